@@ -0,0 +1,550 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// destinationKeyCityCountry is the DESTINATION_KEY that stores same-named
+// cities in different countries separately.
+const destinationKeyCityCountry = "city_country"
+
+// Config holds the server's runtime configuration.
+type Config struct {
+	DatabaseURL            string
+	RedisURL               string
+	BearerToken            string
+	AdminToken             string
+	WeatherAPIKey          string
+	POIAPIKey              string
+	Port                   string
+	MinSuccessfulProviders int
+	TrustedProxies         []*net.IPNet
+	CacheCompress          bool
+	CacheFormat            string
+	CacheTouchOnRead       bool
+	CacheMaxLifetime       time.Duration
+	MaxOutboundConcurrency int
+	POIGeocodeRetries      int
+	POIRadiusRetries       int
+	POILimit               int
+	MaxPOIs                int
+	MaxLanguages           int
+	WeatherMaxBody         int
+	ValidateWeather        bool
+	POIMaxBody             int
+	CountryMaxBody         int
+	TeleportMaxBody        int
+	WeatherFreshFor        time.Duration
+	POIFreshFor            time.Duration
+	CountryFreshFor        time.Duration
+	TeleportFreshFor       time.Duration
+	RefreshReturnsStored   bool
+	ExchangeRates          bool
+	WeatherAlerts          bool
+	BaseCurrency           string
+	LogSchemaDrift         bool
+	LogLevel               string
+	ReloadOnSIGHUP         bool
+	TrackProviderQuota     bool
+	CacheScanCount         int
+	ShutdownTimeout        time.Duration
+	APIDefaultVersion      int
+	RateLimitEnabled       bool
+	RateLimitPerMinute     int
+	RateLimitBurst         int
+	RateLimitStore         string
+	RateLimitFallback      bool
+	MaxPathLength          int
+	RequestTimeout         time.Duration
+	DefaultPageSize        int
+	MaxPageSize            int
+	EnableHistory          bool
+	HistoryDefaultLimit    int
+	HistoryMaxLimit        int
+	ErrorDetail            bool
+	StrictQueryParams      bool
+	FetchOnMiss            bool
+	TimestampFormat        string
+	NotFoundMessage        string
+	NotFoundCode           string
+	CacheConsistencyCheck  bool
+	WeatherPriority        []string
+	InferCountry           bool
+	DisableCountryFallback bool
+	HealthDBSeverity       string
+	HealthRedisSeverity    string
+	ConnectAttempts        int
+	ConnectRetryInterval   time.Duration
+	DBStatementTimeout     time.Duration
+	SkipMigrations         bool
+	DestinationKey         string
+	NegativeCacheTTL       time.Duration
+	CountryCacheTTL        time.Duration
+	StartupProbe           bool
+	ProbeCity              string
+	ProbeCountry           string
+	DatabaseSSLRootCert    string
+	RedisTLS               bool
+	RedisTLSCACert         string
+	DebugLogBodies         bool
+	DebugLogBodiesMax      int
+	AccessLogFormat        string
+}
+
+// LoadConfig builds and validates a Config from the optional file at path merged
+// with the environment. Keys in the file use the environment variable names, and
+// a non-empty environment variable always overrides the file value. The file may
+// be YAML or JSON (JSON is valid YAML).
+//
+// Every setting is validated up front; all problems are reported together in the
+// returned error rather than stopping at the first.
+func LoadConfig(path string, getenv func(string) string) (*Config, error) {
+	cfg, _, err := loadConfig(path, getenv)
+	return cfg, err
+}
+
+// loadConfig is LoadConfig, also returning the raw value every setting was read
+// as, keyed by name and empty when unset, so a reload can tell which changed.
+func loadConfig(path string, getenv func(string) string) (*Config, map[string]string, error) {
+	p, err := newConfigParser(path, getenv)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cfg := &Config{
+		DatabaseURL:            p.url("DATABASE_URL", "postgres", "postgresql"),
+		RedisURL:               p.url("REDIS_URL", "redis", "rediss"),
+		BearerToken:            p.required("BEARER_TOKEN"),
+		AdminToken:             p.lookup("ADMIN_TOKEN"),
+		WeatherAPIKey:          p.required("OPENWEATHER_API_KEY"),
+		POIAPIKey:              p.required("OPENTRIPMAP_API_KEY"),
+		Port:                   strconv.Itoa(p.intRange("PORT", 8080, 1, 65535)),
+		MinSuccessfulProviders: p.intRange("MIN_SUCCESSFUL_PROVIDERS", 0, 0, 4),
+		TrustedProxies:         p.cidrs("TRUSTED_PROXIES"),
+		CacheCompress:          p.boolean("CACHE_COMPRESS", false),
+		CacheFormat:            p.oneOf("CACHE_FORMAT", "json", "json", "gob"),
+		CacheTouchOnRead:       p.boolean("CACHE_TOUCH_ON_READ", false),
+		CacheMaxLifetime:       p.duration("CACHE_MAX_LIFETIME", 0, 0, 7*24*time.Hour),
+		MaxOutboundConcurrency: p.intRange("MAX_OUTBOUND_CONCURRENCY", 0, 0, 10000),
+		POIGeocodeRetries:      p.intRange("POI_GEOCODE_RETRIES", 0, 0, 5),
+		POIRadiusRetries:       p.intRange("POI_RADIUS_RETRIES", 1, 0, 5),
+		POILimit:               p.intRange("POI_LIMIT", 5, 1, 500),
+		MaxPOIs:                p.intRange("MAX_POIS", 20, 1, 500),
+		MaxLanguages:           p.intRange("MAX_LANGUAGES", 0, 0, 1000),
+		WeatherMaxBody:         p.intRange("WEATHER_MAX_BODY_BYTES", 64<<10, 1<<10, 64<<20),
+		ValidateWeather:        p.boolean("VALIDATE_WEATHER", true),
+		POIMaxBody:             p.intRange("POI_MAX_BODY_BYTES", 1<<20, 1<<10, 64<<20),
+		CountryMaxBody:         p.intRange("COUNTRY_MAX_BODY_BYTES", 1<<20, 1<<10, 64<<20),
+		TeleportMaxBody:        p.intRange("TELEPORT_MAX_BODY_BYTES", 256<<10, 1<<10, 64<<20),
+		WeatherFreshFor:        p.duration("WEATHER_FRESH_FOR", 0, 0, 30*24*time.Hour),
+		POIFreshFor:            p.duration("POI_FRESH_FOR", 0, 0, 30*24*time.Hour),
+		CountryFreshFor:        p.duration("COUNTRY_FRESH_FOR", 0, 0, 30*24*time.Hour),
+		TeleportFreshFor:       p.duration("TELEPORT_FRESH_FOR", 0, 0, 30*24*time.Hour),
+		RefreshReturnsStored:   p.boolean("REFRESH_RETURNS_STORED", false),
+		ExchangeRates:          p.boolean("EXCHANGE_RATES", false),
+		WeatherAlerts:          p.boolean("WEATHER_ALERTS", false),
+		BaseCurrency:           p.currency("BASE_CURRENCY", "USD"),
+		LogSchemaDrift:         p.boolean("LOG_SCHEMA_DRIFT", false),
+		LogLevel:               p.oneOf("LOG_LEVEL", "info", "debug", "info", "warn", "error"),
+		ReloadOnSIGHUP:         p.boolean("RELOAD_ON_SIGHUP", true),
+		TrackProviderQuota:     p.boolean("TRACK_PROVIDER_QUOTA", false),
+		CacheScanCount:         p.intRange("CACHE_SCAN_COUNT", 100, 1, 100000),
+		APIDefaultVersion:      p.intRange("API_DEFAULT_VERSION", 1, 1, 2),
+		RateLimitEnabled:       p.boolean("RATE_LIMIT_ENABLED", true),
+		RateLimitPerMinute:     p.intRange("RATE_LIMIT_PER_MINUTE", 60, 1, 100000),
+		RateLimitBurst:         p.intRange("RATE_LIMIT_BURST", 20, 1, 100000),
+		RateLimitStore:         p.oneOf("RATE_LIMIT_STORE", "memory", "memory", "redis"),
+		RateLimitFallback:      p.boolean("RATE_LIMIT_FALLBACK", true),
+		MaxPathLength:          p.intRange("MAX_PATH_LENGTH", 2048, 64, 65536),
+		RequestTimeout:         p.duration("REQUEST_TIMEOUT", 10*time.Second, time.Second, time.Minute),
+		DefaultPageSize:        p.intRange("DEFAULT_PAGE_SIZE", 50, 1, 1000),
+		MaxPageSize:            p.intRange("MAX_PAGE_SIZE", 200, 1, 1000),
+		EnableHistory:          p.boolean("ENABLE_HISTORY", false),
+		HistoryDefaultLimit:    p.intRange("HISTORY_DEFAULT_LIMIT", 10, 1, 1000),
+		HistoryMaxLimit:        p.intRange("HISTORY_MAX_LIMIT", 100, 1, 1000),
+		ErrorDetail:            p.boolean("ERROR_DETAIL", false),
+		StrictQueryParams:      p.boolean("STRICT_QUERY_PARAMS", false),
+		FetchOnMiss:            p.boolean("FETCH_ON_MISS", false),
+		TimestampFormat:        p.oneOf("TIMESTAMP_FORMAT", "rfc3339", "rfc3339", "unix"),
+		NotFoundMessage:        p.lookup("NOT_FOUND_MESSAGE"),
+		NotFoundCode:           p.lookup("NOT_FOUND_CODE"),
+		CacheConsistencyCheck:  p.boolean("CACHE_CONSISTENCY_CHECK", false),
+		WeatherPriority:        p.list("WEATHER_PRIORITY"),
+		InferCountry:           p.boolean("INFER_COUNTRY", false),
+		DisableCountryFallback: p.boolean("DISABLE_COUNTRY_FALLBACK", false),
+		HealthDBSeverity:       p.oneOf("HEALTH_DB_SEVERITY", "critical", "critical", "degraded"),
+		HealthRedisSeverity:    p.oneOf("HEALTH_REDIS_SEVERITY", "degraded", "critical", "degraded"),
+		ShutdownTimeout:        p.duration("SHUTDOWN_TIMEOUT", 30*time.Second, time.Second, 10*time.Minute),
+		ConnectAttempts:        p.intRange("CONNECT_ATTEMPTS", 5, 1, 100),
+		ConnectRetryInterval:   p.duration("CONNECT_RETRY_INTERVAL", 2*time.Second, 10*time.Millisecond, time.Minute),
+		DBStatementTimeout:     p.duration("DB_STATEMENT_TIMEOUT", 5*time.Second, 0, time.Hour),
+		SkipMigrations:         p.boolean("SKIP_MIGRATIONS", false),
+		DestinationKey:         p.oneOf("DESTINATION_KEY", "city", "city", destinationKeyCityCountry),
+		NegativeCacheTTL:       p.duration("NEGATIVE_CACHE_TTL", 0, 0, time.Hour),
+		CountryCacheTTL:        p.duration("COUNTRY_CACHE_TTL", 24*time.Hour, 0, 30*24*time.Hour),
+		StartupProbe:           p.boolean("STARTUP_PROBE", false),
+		ProbeCity:              p.lookup("PROBE_CITY"),
+		ProbeCountry:           p.lookup("PROBE_COUNTRY"),
+		DatabaseSSLRootCert:    p.file("DATABASE_SSL_ROOT_CERT"),
+		RedisTLS:               p.boolean("REDIS_TLS", false),
+		RedisTLSCACert:         p.file("REDIS_TLS_CA_CERT"),
+		DebugLogBodies:         p.boolean("DEBUG_LOG_BODIES", false),
+		DebugLogBodiesMax:      p.intRange("DEBUG_LOG_BODIES_MAX", 4096, 1, 1<<20),
+		AccessLogFormat:        p.oneOf("ACCESS_LOG_FORMAT", "json", "json", "combined"),
+	}
+
+	if cfg.DefaultPageSize > cfg.MaxPageSize {
+		p.errs = append(p.errs, errors.New("DEFAULT_PAGE_SIZE must not exceed MAX_PAGE_SIZE"))
+	}
+	if cfg.HistoryDefaultLimit > cfg.HistoryMaxLimit {
+		p.errs = append(p.errs, errors.New("HISTORY_DEFAULT_LIMIT must not exceed HISTORY_MAX_LIMIT"))
+	}
+	if cfg.RedisTLSCACert != "" && !cfg.RedisTLS {
+		p.errs = append(p.errs, errors.New("REDIS_TLS_CA_CERT requires REDIS_TLS=true"))
+	}
+	if cfg.ProbeCountry != "" && cfg.ProbeCity == "" {
+		p.errs = append(p.errs, errors.New("PROBE_COUNTRY requires PROBE_CITY"))
+	}
+
+	if err := errors.Join(p.errs...); err != nil {
+		return nil, nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, p.values, nil
+}
+
+// LoadMigrationConfig is LoadConfig for -migrate-only: it reads and validates
+// only the settings needed to connect to PostgreSQL and pick the migrations to
+// apply, so a migration job need not carry the tokens, API keys or Redis URL
+// the server requires. Other fields are left zero.
+func LoadMigrationConfig(path string, getenv func(string) string) (*Config, error) {
+	p, err := newConfigParser(path, getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{
+		DatabaseURL:          p.url("DATABASE_URL", "postgres", "postgresql"),
+		ConnectAttempts:      p.intRange("CONNECT_ATTEMPTS", 5, 1, 100),
+		ConnectRetryInterval: p.duration("CONNECT_RETRY_INTERVAL", 2*time.Second, 10*time.Millisecond, time.Minute),
+		DBStatementTimeout:   p.duration("DB_STATEMENT_TIMEOUT", 5*time.Second, 0, time.Hour),
+		DestinationKey:       p.oneOf("DESTINATION_KEY", "city", "city", destinationKeyCityCountry),
+		DatabaseSSLRootCert:  p.file("DATABASE_SSL_ROOT_CERT"),
+	}
+
+	if err := errors.Join(p.errs...); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// newConfigParser returns a parser reading the environment through getenv,
+// falling back to the optional YAML or JSON file at path.
+func newConfigParser(path string, getenv func(string) string) (*configParser, error) {
+	fileValues := map[string]string{}
+	if path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading config file %s: %w", path, err)
+		}
+		if err := yaml.Unmarshal(b, &fileValues); err != nil {
+			return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+		}
+	}
+
+	p := &configParser{values: map[string]string{}}
+	p.lookup = func(key string) string {
+		v := getenv(key)
+		if v == "" {
+			v = fileValues[key]
+		}
+		p.values[key] = v
+		return v
+	}
+	return p, nil
+}
+
+// LogSummary logs the effective configuration as a single line so operators can
+// see how the server is set up without reading its environment. Tokens and API
+// keys are reported only as set/unset, and URLs are reduced to their host.
+func (c *Config) LogSummary(log *slog.Logger) {
+	proxies := make([]string, 0, len(c.TrustedProxies))
+	for _, n := range c.TrustedProxies {
+		proxies = append(proxies, n.String())
+	}
+
+	log.Info("effective configuration",
+		"port", c.Port,
+		"database_host", urlHost(c.DatabaseURL),
+		"redis_host", urlHost(c.RedisURL),
+		"bearer_token", redacted(c.BearerToken),
+		"admin_token", redacted(c.AdminToken),
+		"openweather_api_key", redacted(c.WeatherAPIKey),
+		"opentripmap_api_key", redacted(c.POIAPIKey),
+		"min_successful_providers", c.MinSuccessfulProviders,
+		"trusted_proxies", proxies,
+		"cache_compress", c.CacheCompress,
+		"cache_format", c.CacheFormat,
+		"cache_touch_on_read", c.CacheTouchOnRead,
+		"cache_max_lifetime", c.CacheMaxLifetime.String(),
+		"cache_scan_count", c.CacheScanCount,
+		"max_outbound_concurrency", c.MaxOutboundConcurrency,
+		"poi_geocode_retries", c.POIGeocodeRetries,
+		"poi_radius_retries", c.POIRadiusRetries,
+		"poi_limit", c.POILimit,
+		"max_pois", c.MaxPOIs,
+		"max_languages", c.MaxLanguages,
+		"weather_max_body_bytes", c.WeatherMaxBody,
+		"validate_weather", c.ValidateWeather,
+		"poi_max_body_bytes", c.POIMaxBody,
+		"country_max_body_bytes", c.CountryMaxBody,
+		"teleport_max_body_bytes", c.TeleportMaxBody,
+		"weather_fresh_for", c.WeatherFreshFor.String(),
+		"poi_fresh_for", c.POIFreshFor.String(),
+		"country_fresh_for", c.CountryFreshFor.String(),
+		"teleport_fresh_for", c.TeleportFreshFor.String(),
+		"refresh_returns_stored", c.RefreshReturnsStored,
+		"exchange_rates", c.ExchangeRates,
+		"weather_alerts", c.WeatherAlerts,
+		"base_currency", c.BaseCurrency,
+		"log_schema_drift", c.LogSchemaDrift,
+		"log_level", c.LogLevel,
+		"reload_on_sighup", c.ReloadOnSIGHUP,
+		"track_provider_quota", c.TrackProviderQuota,
+		"api_default_version", c.APIDefaultVersion,
+		"rate_limit_enabled", c.RateLimitEnabled,
+		"rate_limit_per_minute", c.RateLimitPerMinute,
+		"rate_limit_burst", c.RateLimitBurst,
+		"rate_limit_store", c.RateLimitStore,
+		"rate_limit_fallback", c.RateLimitFallback,
+		"max_path_length", c.MaxPathLength,
+		"request_timeout", c.RequestTimeout.String(),
+		"default_page_size", c.DefaultPageSize,
+		"max_page_size", c.MaxPageSize,
+		"enable_history", c.EnableHistory,
+		"history_default_limit", c.HistoryDefaultLimit,
+		"history_max_limit", c.HistoryMaxLimit,
+		"error_detail", c.ErrorDetail,
+		"strict_query_params", c.StrictQueryParams,
+		"fetch_on_miss", c.FetchOnMiss,
+		"timestamp_format", c.TimestampFormat,
+		"not_found_message", c.NotFoundMessage,
+		"not_found_code", c.NotFoundCode,
+		"cache_consistency_check", c.CacheConsistencyCheck,
+		"weather_priority", c.WeatherPriority,
+		"infer_country", c.InferCountry,
+		"disable_country_fallback", c.DisableCountryFallback,
+		"health_db_severity", c.HealthDBSeverity,
+		"health_redis_severity", c.HealthRedisSeverity,
+		"shutdown_timeout", c.ShutdownTimeout.String(),
+		"connect_attempts", c.ConnectAttempts,
+		"connect_retry_interval", c.ConnectRetryInterval.String(),
+		"db_statement_timeout", c.DBStatementTimeout.String(),
+		"skip_migrations", c.SkipMigrations,
+		"destination_key", c.DestinationKey,
+		"negative_cache_ttl", c.NegativeCacheTTL.String(),
+		"country_cache_ttl", c.CountryCacheTTL.String(),
+		"startup_probe", c.StartupProbe,
+		"probe_city", c.ProbeCity,
+		"probe_country", c.ProbeCountry,
+		"database_ssl_root_cert", c.DatabaseSSLRootCert,
+		"redis_tls", c.RedisTLS,
+		"redis_tls_ca_cert", c.RedisTLSCACert,
+		"debug_log_bodies", c.DebugLogBodies,
+		"debug_log_bodies_max", c.DebugLogBodiesMax,
+		"access_log_format", c.AccessLogFormat,
+	)
+}
+
+// redacted stands in for a secret in logs, revealing only whether it is set.
+func redacted(secret string) string {
+	if secret == "" {
+		return "unset"
+	}
+	return "[redacted]"
+}
+
+// urlHost returns the host:port of raw, dropping credentials, path and query.
+func urlHost(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "[unparseable]"
+	}
+	return u.Host
+}
+
+// configParser reads settings through lookup and accumulates validation errors.
+type configParser struct {
+	lookup func(key string) string
+	values map[string]string
+	errs   []error
+}
+
+// required returns the value for key, recording an error if it is empty.
+func (p *configParser) required(key string) string {
+	v := p.lookup(key)
+	if v == "" {
+		p.errs = append(p.errs, errors.New(key+" is required"))
+	}
+	return v
+}
+
+// url returns the required value for key, recording an error unless it parses
+// as a URL with one of the given schemes.
+func (p *configParser) url(key string, schemes ...string) string {
+	v := p.required(key)
+	if v == "" {
+		return v
+	}
+
+	u, err := url.Parse(v)
+	if err != nil {
+		p.errs = append(p.errs, errors.New(key+" is not a valid URL"))
+		return v
+	}
+	for _, s := range schemes {
+		if u.Scheme == s {
+			return v
+		}
+	}
+	p.errs = append(p.errs, fmt.Errorf("%s must use scheme %v, got %q", key, schemes, u.Scheme))
+	return v
+}
+
+// intRange returns the integer value for key, or fallback when unset, recording
+// an error if the value is not an integer within [lo, hi].
+func (p *configParser) intRange(key string, fallback, lo, hi int) int {
+	v := p.lookup(key)
+	if v == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < lo || n > hi {
+		p.errs = append(p.errs, fmt.Errorf("%s must be an integer between %d and %d, got %q", key, lo, hi, v))
+		return fallback
+	}
+	return n
+}
+
+// duration returns the Go duration (e.g. "45s") for key, or fallback when unset,
+// recording an error if the value does not parse or falls outside [lo, hi].
+func (p *configParser) duration(key string, fallback, lo, hi time.Duration) time.Duration {
+	v := p.lookup(key)
+	if v == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil || d < lo || d > hi {
+		p.errs = append(p.errs, fmt.Errorf("%s must be a duration between %s and %s, got %q", key, lo, hi, v))
+		return fallback
+	}
+	return d
+}
+
+// boolean returns the boolean value for key, or fallback when unset, recording
+// an error if the value does not parse.
+func (p *configParser) boolean(key string, fallback bool) bool {
+	v := p.lookup(key)
+	if v == "" {
+		return fallback
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		p.errs = append(p.errs, fmt.Errorf("%s must be a boolean, got %q", key, v))
+		return fallback
+	}
+	return b
+}
+
+// oneOf returns the value for key, or fallback when unset, recording an error
+// unless it is one of allowed.
+func (p *configParser) oneOf(key, fallback string, allowed ...string) string {
+	v := p.lookup(key)
+	if v == "" {
+		return fallback
+	}
+	for _, a := range allowed {
+		if v == a {
+			return v
+		}
+	}
+	p.errs = append(p.errs, fmt.Errorf("%s must be one of %v, got %q", key, allowed, v))
+	return fallback
+}
+
+// currency returns the ISO 4217 code for key in upper case, or fallback when
+// unset, recording an error unless it is three letters.
+func (p *configParser) currency(key, fallback string) string {
+	v := p.lookup(key)
+	if v == "" {
+		return fallback
+	}
+	if len(v) != 3 || strings.IndexFunc(v, func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < 'A' || r > 'Z')
+	}) >= 0 {
+		p.errs = append(p.errs, fmt.Errorf("%s must be a three-letter currency code, got %q", key, v))
+		return fallback
+	}
+	return strings.ToUpper(v)
+}
+
+// file returns the path for key, recording an error if it is set but names no
+// readable regular file. Unset returns "".
+func (p *configParser) file(key string) string {
+	v := p.lookup(key)
+	if v == "" {
+		return v
+	}
+	info, err := os.Stat(v)
+	if err != nil || !info.Mode().IsRegular() {
+		p.errs = append(p.errs, fmt.Errorf("%s must name an existing file, got %q", key, v))
+	}
+	return v
+}
+
+// list returns the non-empty, trimmed entries of the comma-separated value for key.
+func (p *configParser) list(key string) []string {
+	var out []string
+	for _, entry := range strings.Split(p.lookup(key), ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+// cidrs parses a comma-separated list of CIDRs for key, recording an error for
+// each entry that does not parse.
+func (p *configParser) cidrs(key string) []*net.IPNet {
+	v := p.lookup(key)
+	if v == "" {
+		return nil
+	}
+
+	var networks []*net.IPNet
+	for _, entry := range strings.Split(v, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		_, n, err := net.ParseCIDR(entry)
+		if err != nil {
+			p.errs = append(p.errs, fmt.Errorf("%s contains invalid CIDR %q", key, entry))
+			continue
+		}
+		networks = append(networks, n)
+	}
+	return networks
+}
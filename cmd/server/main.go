@@ -2,79 +2,217 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 
 	"github.com/neexbeast/ygo-test/internal/api"
 	"github.com/neexbeast/ygo-test/internal/cache"
 	"github.com/neexbeast/ygo-test/internal/destination"
+	"github.com/neexbeast/ygo-test/internal/metrics"
 	"github.com/neexbeast/ygo-test/internal/storage"
 )
 
 func main() {
-	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	configPath := flag.String("config", "", "path to an optional YAML or JSON config file")
+	migrateOnlyFlag := flag.Bool("migrate-only", false, "run database migrations and exit, without starting the server")
+	flag.Parse()
 
-	if err := run(log); err != nil {
+	level := new(slog.LevelVar)
+	log := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level}))
+
+	if *migrateOnlyFlag {
+		cfg, err := LoadMigrationConfig(*configPath, os.Getenv)
+		if err != nil {
+			log.Error("invalid configuration", "err", err)
+			os.Exit(1)
+		}
+		connect := func(ctx context.Context) (migrationDB, error) { return connectDB(ctx, cfg) }
+		os.Exit(migrateOnly(context.Background(), log, connect, migrationDirs(cfg)))
+	}
+
+	cfg, values, err := loadConfig(*configPath, os.Getenv)
+	if err != nil {
+		log.Error("invalid configuration", "err", err)
+		os.Exit(1)
+	}
+	level.Set(logLevels[cfg.LogLevel])
+	cfg.LogSummary(log)
+
+	rateLimit := api.NewRateLimitVar(cfg.RateLimitPerMinute, cfg.RateLimitBurst)
+	rl := &reloader{path: *configPath, getenv: os.Getenv, level: level, rateLimit: rateLimit, log: log, values: values}
+	if err := run(log, cfg, rl); err != nil {
 		log.Error("server exited with error", "err", err)
 		os.Exit(1)
 	}
 }
 
-func run(log *slog.Logger) error {
-	databaseURL := mustEnv("DATABASE_URL")
-	redisURL := mustEnv("REDIS_URL")
-	bearerToken := mustEnv("BEARER_TOKEN")
-	weatherKey := mustEnv("OPENWEATHER_API_KEY")
-	poiKey := mustEnv("OPENTRIPMAP_API_KEY")
-	port := getEnv("PORT", "8080")
-
+func run(log *slog.Logger, cfg *Config, rl *reloader) error {
 	ctx := context.Background()
 
-	// Connect to PostgreSQL.
-	pool, err := storage.Connect(ctx, databaseURL)
+	pool, err := connectDB(ctx, cfg)
 	if err != nil {
-		return fmt.Errorf("connecting to database: %w", err)
+		return err
 	}
-	defer pool.Close()
+	// Closed explicitly by shutdown once requests drain; the defer
+	// only covers early returns.
+	closeDB := sync.OnceFunc(pool.Close)
+	defer closeDB()
 
-	// Run migrations.
-	migrationsDir := "migrations"
-	if err := storage.RunMigrations(ctx, pool, migrationsDir); err != nil {
-		return fmt.Errorf("running migrations: %w", err)
+	for _, dir := range migrationDirs(cfg) {
+		if err := migrate(ctx, log, pool, dir, cfg.SkipMigrations); err != nil {
+			return err
+		}
 	}
-	log.Info("migrations applied")
 
 	// Connect to Redis.
-	redisClient, err := cache.Connect(ctx, redisURL)
+	redisOpts := []cache.ConnectOption{cache.WithConnectRetries(cfg.ConnectAttempts, cfg.ConnectRetryInterval)}
+	if cfg.RedisTLS {
+		tlsCfg, err := loadTLSConfig(cfg.RedisTLSCACert)
+		if err != nil {
+			return fmt.Errorf("redis TLS: %w", err)
+		}
+		redisOpts = append(redisOpts, cache.WithTLS(tlsCfg))
+	}
+	redisClient, err := cache.Connect(ctx, cfg.RedisURL, redisOpts...)
 	if err != nil {
 		return fmt.Errorf("connecting to redis: %w", err)
 	}
-	defer func() { _ = redisClient.Close() }()
+	closeRedis := sync.OnceFunc(func() { _ = redisClient.Close() })
+	defer closeRedis()
 
 	// Wire dependencies.
-	repo := storage.NewRepository(pool)
-	cacheLayer := cache.NewCache(redisClient)
-	fetcher := destination.NewFetcher(weatherKey, poiKey)
-	handlers := api.NewHandlers(repo, cacheLayer, fetcher, log)
+	repo := storage.NewRepository(pool,
+		storage.WithHistory(cfg.EnableHistory),
+		storage.WithCountryKey(cfg.DestinationKey == destinationKeyCityCountry),
+	)
+	cacheLayer := cache.NewCache(redisClient,
+		cache.WithCompression(cfg.CacheCompress),
+		cache.WithFormat(cfg.CacheFormat),
+		cache.WithTouchOnRead(cfg.CacheTouchOnRead),
+		cache.WithMaxLifetime(cfg.CacheMaxLifetime),
+		cache.WithScanCount(cfg.CacheScanCount),
+		cache.WithNegativeTTL(cfg.NegativeCacheTTL),
+		cache.WithCountryTTL(cfg.CountryCacheTTL),
+	)
+	m := metrics.New()
+	destination.SetMaxOutboundConcurrency(cfg.MaxOutboundConcurrency)
+	instr := destination.Instrumentation{Metrics: m, TrackQuota: cfg.TrackProviderQuota, SchemaDrift: cfg.LogSchemaDrift}
+	fetcherOpts := []destination.FetcherOption{
+		destination.WithPOIOptions(
+			destination.WithGeocodeRetries(cfg.POIGeocodeRetries),
+			destination.WithRadiusRetries(cfg.POIRadiusRetries),
+			destination.WithPOILimit(cfg.POILimit),
+			destination.WithMaxPOIs(cfg.MaxPOIs),
+			destination.WithPOIMaxBody(int64(cfg.POIMaxBody)),
+			destination.WithPOIInstrumentation(instr),
+		),
+		destination.WithCountriesOptions(
+			destination.WithMaxLanguages(cfg.MaxLanguages),
+			destination.WithCountriesMaxBody(int64(cfg.CountryMaxBody)),
+			destination.WithCountriesInstrumentation(instr),
+		),
+		destination.WithWeatherOptions(
+			destination.WithWeatherMaxBody(int64(cfg.WeatherMaxBody)),
+			destination.WithWeatherValidation(cfg.ValidateWeather),
+			destination.WithWeatherInstrumentation(instr),
+		),
+		destination.WithTeleportOptions(
+			destination.WithTeleportMaxBody(int64(cfg.TeleportMaxBody)),
+			destination.WithTeleportInstrumentation(instr),
+		),
+		destination.WithWeatherPriority(cfg.WeatherPriority...),
+		destination.WithCountryInference(cfg.InferCountry),
+		destination.WithCountryFallback(!cfg.DisableCountryFallback),
+		destination.WithProbeCity(cfg.ProbeCity, cfg.ProbeCountry),
+	}
+	if cfg.CountryCacheTTL > 0 {
+		fetcherOpts = append(fetcherOpts, destination.WithCountryCache(cacheLayer))
+	}
+	if cfg.ExchangeRates {
+		rates := destination.NewExchangeRateClient(cfg.BaseCurrency, destination.WithExchangeRateInstrumentation(instr))
+		fetcherOpts = append(fetcherOpts, destination.WithExchangeRates(rates))
+	}
+	if cfg.WeatherAlerts {
+		alerts := destination.NewAlertsClient(cfg.WeatherAPIKey, destination.WithAlertsInstrumentation(instr))
+		fetcherOpts = append(fetcherOpts, destination.WithWeatherAlerts(alerts))
+	}
+	fetcher := destination.NewFetcher(cfg.WeatherAPIKey, cfg.POIAPIKey, fetcherOpts...)
+	if cfg.StartupProbe {
+		probeCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		// Problems are logged per provider by Probe; startup continues regardless.
+		if problems := fetcher.Probe(probeCtx); len(problems) == 0 {
+			log.Info("provider probe passed")
+		}
+		cancel()
+	}
+	handlers := api.NewHandlers(repo, cacheLayer, fetcher, log,
+		api.WithMinSuccessfulProviders(cfg.MinSuccessfulProviders),
+		api.WithHandlerMetrics(m),
+		api.WithPageSizes(api.PageSizes{Default: cfg.DefaultPageSize, Max: cfg.MaxPageSize}),
+		api.WithHistorySizes(api.PageSizes{Default: cfg.HistoryDefaultLimit, Max: cfg.HistoryMaxLimit}),
+		api.WithErrorDetail(cfg.ErrorDetail),
+		api.WithStrictParams(cfg.StrictQueryParams),
+		api.WithFetchOnMiss(cfg.FetchOnMiss),
+		api.WithTimestampFormat(cfg.TimestampFormat),
+		api.WithNotFoundError(api.APIError{Message: cfg.NotFoundMessage, Code: cfg.NotFoundCode}),
+		api.WithCacheConsistencyCheck(cfg.CacheConsistencyCheck),
+		api.WithProviderFreshness(map[string]time.Duration{
+			destination.ProviderWeather:  cfg.WeatherFreshFor,
+			destination.ProviderPOI:      cfg.POIFreshFor,
+			destination.ProviderCountry:  cfg.CountryFreshFor,
+			destination.ProviderTeleport: cfg.TeleportFreshFor,
+		}),
+		api.WithRefreshReturnsStored(cfg.RefreshReturnsStored),
+		api.WithCountryKey(cfg.DestinationKey == destinationKeyCityCountry),
+	)
 
 	// Build router with pingers adapted for health check.
 	dbPinger := &pgxPoolPinger{pool: pool}
 	redisPinger := &redisPingerAdapter{client: redisClient}
 
-	router := api.NewRouter(handlers, bearerToken, dbPinger, redisPinger, log)
+	bodyLogMax := 0
+	if cfg.DebugLogBodies {
+		bodyLogMax = cfg.DebugLogBodiesMax
+	}
 
+	routerOpts := []api.RouterOption{
+		api.WithTrustedProxies(cfg.TrustedProxies),
+		api.WithBodyLogging(bodyLogMax),
+		api.WithAccessLog(cfg.AccessLogFormat),
+		api.WithMetrics(m),
+		api.WithAdminToken(cfg.AdminToken),
+		api.WithDefaultAPIVersion(api.APIVersion(cfg.APIDefaultVersion)),
+		api.WithRateLimitEnabled(cfg.RateLimitEnabled),
+		api.WithRateLimitVar(rl.rateLimit),
+		api.WithMaxPathLength(cfg.MaxPathLength),
+		api.WithRequestTimeout(cfg.RequestTimeout),
+		api.WithHealthSeverities(api.HealthSeverities{
+			DB:    cfg.HealthDBSeverity,
+			Redis: cfg.HealthRedisSeverity,
+		}),
+	}
+	if cfg.RateLimitStore == "redis" {
+		routerOpts = append(routerOpts, api.WithSharedRateLimit(cache.NewRateLimiter(redisClient), cfg.RateLimitFallback))
+	}
+	router := api.NewRouter(handlers, cfg.BearerToken, dbPinger, redisPinger, log, routerOpts...)
+
+	// WriteTimeout outlasts the longest deadline X-Request-Timeout may ask for.
 	srv := &http.Server{
-		Addr:         ":" + port,
+		Addr:         ":" + cfg.Port,
 		Handler:      router,
 		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
+		WriteTimeout: api.MaxRequestTimeout + 5*time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
 
@@ -82,6 +220,12 @@ func run(log *slog.Logger) error {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
+	// Reload the runtime-tunable settings on SIGHUP.
+	hup := make(chan os.Signal, 1)
+	if cfg.ReloadOnSIGHUP {
+		signal.Notify(hup, syscall.SIGHUP)
+	}
+
 	errCh := make(chan error, 1)
 	go func() {
 		defer func() {
@@ -90,23 +234,33 @@ func run(log *slog.Logger) error {
 				errCh <- fmt.Errorf("server panicked: %v", r)
 			}
 		}()
-		log.Info("server starting", "port", port)
+		log.Info("server starting", "port", cfg.Port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			errCh <- fmt.Errorf("listening: %w", err)
 		}
 	}()
 
-	select {
-	case sig := <-quit:
-		log.Info("shutdown signal received", "signal", sig)
-	case err := <-errCh:
-		return err
+wait:
+	for {
+		select {
+		case <-hup:
+			if err := rl.reload(); err != nil {
+				log.Error("configuration reload failed, keeping current settings", "err", err)
+				continue
+			}
+			log.Info("configuration reloaded")
+		case sig := <-quit:
+			log.Info("shutdown signal received", "signal", sig)
+			break wait
+		case err := <-errCh:
+			return err
+		}
 	}
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer cancel()
 
-	if err := srv.Shutdown(shutdownCtx); err != nil {
+	if err := shutdown(shutdownCtx, srv, closeRedis, closeDB); err != nil {
 		return fmt.Errorf("graceful shutdown: %w", err)
 	}
 
@@ -114,20 +268,101 @@ func run(log *slog.Logger) error {
 	return nil
 }
 
-func mustEnv(key string) string {
-	v := os.Getenv(key)
-	if v == "" {
-		slog.Error("required environment variable not set", "key", key)
-		os.Exit(1)
+// connectDB connects to PostgreSQL as configured by cfg.
+func connectDB(ctx context.Context, cfg *Config) (*pgxpool.Pool, error) {
+	dbOpts := []storage.ConnectOption{
+		storage.WithConnectRetries(cfg.ConnectAttempts, cfg.ConnectRetryInterval),
+		storage.WithStatementTimeout(cfg.DBStatementTimeout),
+	}
+	if cfg.DatabaseSSLRootCert != "" {
+		tlsCfg, err := loadTLSConfig(cfg.DatabaseSSLRootCert)
+		if err != nil {
+			return nil, fmt.Errorf("database TLS: %w", err)
+		}
+		dbOpts = append(dbOpts, storage.WithTLS(tlsCfg))
+	}
+	pool, err := storage.Connect(ctx, cfg.DatabaseURL, dbOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to database: %w", err)
 	}
-	return v
+	return pool, nil
+}
+
+// migrationDB is a connection pool migrateOnly can migrate and then close.
+// *pgxpool.Pool satisfies it.
+type migrationDB interface {
+	storage.MigrationPool
+	Close()
 }
 
-func getEnv(key, fallback string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
+// migrateOnly implements -migrate-only: it connects with connect, applies the
+// migrations in dirs and returns the process exit code, 0 on success and 1 on any
+// failure. It never touches Redis or starts the server, so it can run as an init
+// container ahead of them.
+func migrateOnly(ctx context.Context, log *slog.Logger, connect func(context.Context) (migrationDB, error), dirs []string) int {
+	db, err := connect(ctx)
+	if err != nil {
+		log.Error("migrate-only failed", "err", err)
+		return 1
+	}
+	defer db.Close()
+
+	for _, dir := range dirs {
+		if err := migrate(ctx, log, db, dir, false); err != nil {
+			log.Error("migrate-only failed", "err", err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// migrationsDir holds the SQL migrations, relative to the working directory.
+// Optional schema changes live in subdirectories, applied only when configured.
+const migrationsDir = "migrations"
+
+// migrationDirs returns the directories of migrations cfg needs, in the order
+// to apply them.
+func migrationDirs(cfg *Config) []string {
+	dirs := []string{migrationsDir}
+	if cfg.DestinationKey == destinationKeyCityCountry {
+		dirs = append(dirs, filepath.Join(migrationsDir, "city_country"))
+	}
+	return dirs
+}
+
+// migrate applies the migrations in dir to pool, or with skip only logs that it
+// did not, for deployments where a separate job owns the schema.
+func migrate(ctx context.Context, log *slog.Logger, pool storage.MigrationPool, dir string, skip bool) error {
+	if skip {
+		log.Info("migrations skipped", "reason", "SKIP_MIGRATIONS is set")
+		return nil
+	}
+	if err := storage.RunMigrations(ctx, pool, dir); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+	log.Info("migrations applied")
+	return nil
+}
+
+// httpShutdowner is the part of http.Server that shutdown needs.
+type httpShutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// shutdown stops srv accepting requests and waits for in-flight ones, and only
+// then runs closers in order. Dependencies are closed even if ctx expires first,
+// so the process never leaks connections; the returned error reports requests
+// that did not drain in time.
+func shutdown(ctx context.Context, srv httpShutdowner, closers ...func()) error {
+	var err error
+	if shutdownErr := srv.Shutdown(ctx); shutdownErr != nil {
+		err = fmt.Errorf("draining http server: %w", shutdownErr)
+	}
+
+	for _, closeFn := range closers {
+		closeFn()
 	}
-	return fallback
+	return err
 }
 
 // pgxPoolPinger adapts pgxpool.Pool to the api.dbPinger interface.
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer records when Shutdown was called.
+type fakeServer struct {
+	mu     *sync.Mutex
+	events *[]string
+	err    error
+}
+
+func (s fakeServer) Shutdown(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	*s.events = append(*s.events, "http")
+	return s.err
+}
+
+func TestShutdown_ClosesDependenciesAfterRequestsDrain(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	record := func(e string) func() {
+		return func() {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, e)
+		}
+	}
+
+	err := shutdown(context.Background(), fakeServer{mu: &mu, events: &events}, record("redis"), record("db"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http", "redis", "db"}, events)
+}
+
+func TestShutdown_ClosesDependenciesWhenBudgetExpires(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+
+	closed := false
+	err := shutdown(context.Background(), fakeServer{mu: &mu, events: &events, err: context.DeadlineExceeded}, func() { closed = true })
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "draining http server")
+	assert.True(t, closed, "dependencies must still be closed")
+}
+
+// fakeMigrationPool fails every transaction, counting attempts.
+type fakeMigrationPool struct {
+	begins int
+	closed bool
+}
+
+func (p *fakeMigrationPool) Close() { p.closed = true }
+
+func (p *fakeMigrationPool) Begin(_ context.Context) (pgx.Tx, error) {
+	p.begins++
+	return nil, errors.New("no DDL privileges")
+}
+
+func TestMigrate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "001_initial.sql"), []byte("SELECT 1"), 0o644))
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	pool := &fakeMigrationPool{}
+	require.NoError(t, migrate(context.Background(), log, pool, dir, true))
+	assert.Zero(t, pool.begins, "skipped migrations must not touch the DB")
+
+	err := migrate(context.Background(), log, pool, dir, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "running migrations")
+	assert.Equal(t, 1, pool.begins)
+}
+
+func TestMigrateOnly(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	failing := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(failing, "001_initial.sql"), []byte("SELECT 1"), 0o644))
+
+	tests := []struct {
+		name       string
+		dir        string
+		connectErr error
+		wantCode   int
+	}{
+		{name: "nothing to apply", dir: t.TempDir(), wantCode: 0},
+		{name: "migration fails", dir: failing, wantCode: 1},
+		{name: "connect fails", dir: failing, connectErr: errors.New("connection refused"), wantCode: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := &fakeMigrationPool{}
+			connect := func(context.Context) (migrationDB, error) {
+				if tt.connectErr != nil {
+					return nil, tt.connectErr
+				}
+				return pool, nil
+			}
+
+			assert.Equal(t, tt.wantCode, migrateOnly(context.Background(), log, connect, []string{tt.dir}))
+			assert.Equal(t, tt.connectErr == nil, pool.closed, "a connected pool is closed before exiting")
+		})
+	}
+}
+
+func TestMigrationDirs(t *testing.T) {
+	assert.Equal(t, []string{"migrations"}, migrationDirs(&Config{DestinationKey: "city"}))
+	assert.Equal(t, []string{"migrations", filepath.Join("migrations", "city_country")},
+		migrationDirs(&Config{DestinationKey: destinationKeyCityCountry}))
+}
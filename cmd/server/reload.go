@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/neexbeast/ygo-test/internal/api"
+)
+
+// logLevels maps LOG_LEVEL values to slog levels.
+var logLevels = map[string]slog.Level{
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+	"warn":  slog.LevelWarn,
+	"error": slog.LevelError,
+}
+
+// reloadable lists the settings reload applies in place.
+var reloadable = map[string]bool{
+	"LOG_LEVEL":             true,
+	"RATE_LIMIT_PER_MINUTE": true,
+	"RATE_LIMIT_BURST":      true,
+}
+
+// reloader re-reads the configuration while the server runs and applies the
+// settings that are safe to change in place. Everything else, such as the
+// port or connection URLs, keeps the value the server started with.
+type reloader struct {
+	path      string
+	getenv    func(string) string
+	level     *slog.LevelVar
+	rateLimit *api.RateLimitVar
+	log       *slog.Logger
+	// values holds the raw setting values of the last configuration loaded, by name.
+	values map[string]string
+}
+
+// reload loads the configuration again and applies it. An invalid
+// configuration is rejected as a whole, leaving the current settings in place.
+// Settings that changed but only take effect on restart are logged as such,
+// once per change.
+func (r *reloader) reload() error {
+	cfg, values, err := loadConfig(r.path, r.getenv)
+	if err != nil {
+		return fmt.Errorf("reloading configuration: %w", err)
+	}
+
+	if level := logLevels[cfg.LogLevel]; level != r.level.Level() {
+		r.log.Info("log level changed", "from", r.level.Level().String(), "to", level.String())
+		r.level.Set(level)
+	}
+	if perMinute, burst := r.rateLimit.Get(); cfg.RateLimitPerMinute != perMinute || cfg.RateLimitBurst != burst {
+		r.log.Info("rate limit changed", "per_minute", cfg.RateLimitPerMinute, "burst", cfg.RateLimitBurst)
+		r.rateLimit.Set(cfg.RateLimitPerMinute, cfg.RateLimitBurst)
+	}
+	// Values are compared, not logged: some are secrets.
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		if !reloadable[key] && values[key] != r.values[key] {
+			r.log.Warn("setting changed but needs a restart to take effect", "key", key)
+		}
+	}
+	r.values = values
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neexbeast/ygo-test/internal/api"
+)
+
+func TestReloader_LogLevel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig := func(level string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(path, []byte("LOG_LEVEL: "+level+"\n"), 0o600))
+	}
+
+	env := validEnv()
+	level := new(slog.LevelVar)
+	rl := &reloader{path: path, getenv: envMap(env), level: level, rateLimit: api.NewRateLimitVar(60, 20), log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	writeConfig("debug")
+	require.NoError(t, rl.reload())
+	assert.Equal(t, slog.LevelDebug, level.Level())
+
+	writeConfig("error")
+	require.NoError(t, rl.reload())
+	assert.Equal(t, slog.LevelError, level.Level())
+
+	writeConfig("loud")
+	err := rl.reload()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "LOG_LEVEL")
+	assert.Equal(t, slog.LevelError, level.Level(), "an invalid configuration changes nothing")
+
+	env["LOG_LEVEL"] = "warn"
+	writeConfig("debug")
+	require.NoError(t, rl.reload())
+	assert.Equal(t, slog.LevelWarn, level.Level(), "the environment still overrides the file")
+}
+
+func TestReloader_WarnsOfRestartOnlyChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("PORT: \"8080\"\n"), 0o600))
+
+	env := validEnv()
+	_, values, err := loadConfig(path, envMap(env))
+	require.NoError(t, err)
+
+	var logs bytes.Buffer
+	rl := &reloader{path: path, getenv: envMap(env), level: new(slog.LevelVar), rateLimit: api.NewRateLimitVar(60, 20), log: slog.New(slog.NewTextHandler(&logs, nil)), values: values}
+
+	require.NoError(t, rl.reload())
+	assert.NotContains(t, logs.String(), "needs a restart", "nothing changed")
+
+	require.NoError(t, os.WriteFile(path, []byte("PORT: \"9090\"\nLOG_LEVEL: debug\n"), 0o600))
+	env["BEARER_TOKEN"] = "rotated-token"
+	require.NoError(t, rl.reload())
+
+	out := logs.String()
+	assert.Contains(t, out, "key=PORT")
+	assert.Contains(t, out, "key=BEARER_TOKEN")
+	assert.NotContains(t, out, "rotated-token", "changed values are not logged")
+	assert.NotContains(t, out, "key=LOG_LEVEL", "LOG_LEVEL is applied in place")
+	assert.Equal(t, 2, strings.Count(out, "needs a restart"))
+
+	require.NoError(t, rl.reload())
+	assert.Equal(t, 2, strings.Count(logs.String(), "needs a restart"), "each change is reported once")
+}
+
+func TestReloader_RateLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("RATE_LIMIT_PER_MINUTE: \"120\"\nRATE_LIMIT_BURST: \"5\"\n"), 0o600))
+
+	env := validEnv()
+	_, values, err := loadConfig("", envMap(env))
+	require.NoError(t, err)
+
+	var logs bytes.Buffer
+	limit := api.NewRateLimitVar(60, 20)
+	rl := &reloader{path: path, getenv: envMap(env), level: new(slog.LevelVar), rateLimit: limit, log: slog.New(slog.NewTextHandler(&logs, nil)), values: values}
+
+	require.NoError(t, rl.reload())
+	perMinute, burst := limit.Get()
+	assert.Equal(t, 120, perMinute)
+	assert.Equal(t, 5, burst)
+	assert.Contains(t, logs.String(), "rate limit changed")
+	assert.NotContains(t, logs.String(), "needs a restart", "rate limits are applied in place")
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// loadTLSConfig builds a client TLS config that trusts the PEM-encoded CA
+// certificates in caPath. An empty caPath trusts the system roots.
+func loadTLSConfig(caPath string) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caPath == "" {
+		return cfg, nil
+	}
+
+	pem, err := os.ReadFile(caPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading CA certificate %s: %w", caPath, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("no PEM certificates found in " + caPath)
+	}
+	cfg.RootCAs = pool
+	return cfg, nil
+}
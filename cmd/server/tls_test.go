@@ -0,0 +1,62 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// selfSignedCAPEM returns a freshly generated self-signed CA certificate in PEM form.
+func selfSignedCAPEM(t *testing.T) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestLoadTLSConfig(t *testing.T) {
+	path := writeConfigFile(t, "ca.pem", selfSignedCAPEM(t))
+
+	cfg, err := loadTLSConfig(path)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.RootCAs)
+	assert.Equal(t, uint16(tls.VersionTLS12), cfg.MinVersion)
+}
+
+func TestLoadTLSConfig_SystemRoots(t *testing.T) {
+	cfg, err := loadTLSConfig("")
+	require.NoError(t, err)
+	assert.Nil(t, cfg.RootCAs, "nil RootCAs means the system pool")
+}
+
+func TestLoadTLSConfig_Errors(t *testing.T) {
+	_, err := loadTLSConfig("/nonexistent/ca.pem")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reading CA certificate")
+
+	_, err = loadTLSConfig(writeConfigFile(t, "bad.pem", "not a certificate"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no PEM certificates found")
+}
@@ -0,0 +1,298 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/neexbeast/ygo-test/internal/destination"
+)
+
+// incompleteResponse is the body returned by ListIncomplete.
+type incompleteResponse struct {
+	Incomplete []destination.IncompleteDestination `json:"incomplete"`
+}
+
+// ListIncomplete handles GET /api/v1/admin/repair.
+// Lists stored destinations whose data lacks expected sections so they can be re-refreshed.
+// Results are paginated with ?limit and ?offset.
+func (h *Handlers) ListIncomplete(w http.ResponseWriter, r *http.Request) {
+	if !h.checkParams(w, r, "limit", "offset") {
+		return
+	}
+	page, err := parsePagination(r, h.pageSizes)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	incomplete, err := h.repo.FindIncomplete(r.Context(), page)
+	if err != nil {
+		h.log.Error("find incomplete failed", "err", err)
+		h.writeServerError(w, "internal server error", err)
+		return
+	}
+
+	if incomplete == nil {
+		incomplete = []destination.IncompleteDestination{}
+	}
+	writeJSON(w, http.StatusOK, incompleteResponse{Incomplete: incomplete})
+}
+
+// fullRecord is the body returned by GetFullDestination: a stored destination
+// with its timestamps in the configured format.
+type fullRecord struct {
+	ID        int                         `json:"id"`
+	City      string                      `json:"city"`
+	Country   string                      `json:"country"`
+	Data      destination.DestinationData `json:"data"`
+	FetchedAt *timestamp                  `json:"fetched_at"`
+	CreatedAt timestamp                   `json:"created_at"`
+	UpdatedAt timestamp                   `json:"updated_at"`
+}
+
+// GetFullDestination handles GET /api/v1/destinations/{city}/full.
+// Returns the stored record with its metadata (ID, country, timestamps), always
+// from the DB so the timestamps are authoritative. With WithCountryKey,
+// ?country= picks the record for that country, as for GetDestination.
+func (h *Handlers) GetFullDestination(w http.ResponseWriter, r *http.Request) {
+	var params []string
+	if h.countryKey {
+		params = append(params, "country")
+	}
+	if !h.checkParams(w, r, params...) {
+		return
+	}
+	city := chi.URLParam(r, "city")
+
+	dest, err := h.lookup(r.Context(), city, r.URL.Query().Get("country"))
+	if err != nil {
+		h.log.Error("db get failed", "city", city, "err", err)
+		h.writeServerError(w, "internal server error", err)
+		return
+	}
+	if dest == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "destination not found"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, fullRecord{
+		ID:        dest.ID,
+		City:      dest.City,
+		Country:   dest.Country,
+		Data:      dest.Data,
+		FetchedAt: newTimestamp(dest.FetchedAt, h.timestampFormat),
+		CreatedAt: timestamp{t: dest.CreatedAt, format: h.timestampFormat},
+		UpdatedAt: timestamp{t: dest.UpdatedAt, format: h.timestampFormat},
+	})
+}
+
+// bulkDeleteResponse is the body returned by BulkDelete.
+type bulkDeleteResponse struct {
+	Deleted int `json:"deleted"`
+}
+
+// parseAge parses a positive age such as "90d", "12h" or "30m". Days are accepted
+// in addition to the units time.ParseDuration understands.
+func parseAge(s string) (time.Duration, error) {
+	var d time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, errors.New("invalid day count")
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		parsed, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, err
+		}
+		d = parsed
+	}
+	if d <= 0 {
+		return 0, errors.New("age must be positive")
+	}
+	return d, nil
+}
+
+// BulkDelete handles DELETE /api/v1/destinations?region=...&older_than=...
+// Deletes every stored destination matching all given filters and invalidates
+// their cache entries. At least one filter is required so the whole table
+// cannot be wiped by an unqualified request.
+func (h *Handlers) BulkDelete(w http.ResponseWriter, r *http.Request) {
+	if !h.checkParams(w, r, "region", "older_than") {
+		return
+	}
+	filter := destination.BulkDeleteFilter{Region: strings.TrimSpace(r.URL.Query().Get("region"))}
+
+	if v := r.URL.Query().Get("older_than"); v != "" {
+		age, err := parseAge(v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "older_than must be a positive age like 90d or 12h"})
+			return
+		}
+		filter.FetchedBefore = time.Now().UTC().Add(-age)
+	}
+
+	if filter.IsEmpty() {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "at least one filter (region, older_than) is required"})
+		return
+	}
+
+	cities, err := h.repo.DeleteMatching(r.Context(), filter)
+	if err != nil {
+		h.log.Error("bulk delete failed", "region", filter.Region, "fetched_before", filter.FetchedBefore, "err", err)
+		h.writeServerError(w, "internal server error", err)
+		return
+	}
+
+	for _, city := range cities {
+		if err := h.evict(r.Context(), city); err != nil {
+			h.log.Warn("cache delete failed after bulk delete", "city", city, "err", err)
+		}
+	}
+
+	h.log.Info("bulk delete", "region", filter.Region, "fetched_before", filter.FetchedBefore, "deleted", len(cities))
+	writeJSON(w, http.StatusOK, bulkDeleteResponse{Deleted: len(cities)})
+}
+
+// InvalidateCache handles DELETE /api/v1/admin/cache?match=...
+// Drops the cache entry of every city whose name contains match, case-insensitively,
+// so the next read repopulates it from the DB. Stored records are untouched.
+func (h *Handlers) InvalidateCache(w http.ResponseWriter, r *http.Request) {
+	if !h.checkParams(w, r, "match") {
+		return
+	}
+	match := strings.TrimSpace(r.URL.Query().Get("match"))
+	if match == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "query parameter match is required"})
+		return
+	}
+
+	deleted, err := h.cache.DeleteByPattern(r.Context(), match)
+	if err != nil {
+		h.log.Error("cache invalidation failed", "match", match, "deleted", deleted, "err", err)
+		h.writeServerError(w, "internal server error", err)
+		return
+	}
+
+	h.log.Info("cache invalidated", "match", match, "deleted", deleted)
+	writeJSON(w, http.StatusOK, bulkDeleteResponse{Deleted: deleted})
+}
+
+// probeResponse is the body returned by ProbeProvider.
+type probeResponse struct {
+	Provider  string `json:"provider"`
+	Status    string `json:"status"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+	Result    any    `json:"result,omitempty"`
+}
+
+// ProbeProvider handles GET /api/v1/admin/providers/{name}/probe.
+// Makes a live call to one provider for a fixed test city and reports whether it
+// works, how long it took and an excerpt of what it returned; nothing is stored.
+// A failing provider gets 502, with its error (secrets in URLs redacted), and an
+// unknown name 404.
+func (h *Handlers) ProbeProvider(w http.ResponseWriter, r *http.Request) {
+	if !h.checkParams(w, r) {
+		return
+	}
+	name := chi.URLParam(r, "name")
+
+	res, err := h.fetcher.ProbeProvider(r.Context(), name)
+	if errors.Is(err, destination.ErrUnknownProvider) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "unknown provider; expected one of " + strings.Join(destination.Providers(), ", "),
+		})
+		return
+	}
+	if err != nil {
+		h.log.Error("provider probe failed", "provider", name, "err", err)
+		h.writeServerError(w, "provider probe failed", err)
+		return
+	}
+
+	body := probeResponse{Provider: name, Status: "ok", LatencyMS: res.Latency.Milliseconds(), Result: res.Result}
+	status := http.StatusOK
+	if res.Err != nil {
+		h.log.Warn("provider probe: provider is failing", "provider", name, "err", res.Err)
+		body.Status = "error"
+		body.Error = redactURLs(res.Err.Error())
+		status = http.StatusBadGateway
+	}
+	writeJSON(w, status, body)
+}
+
+// diffResponse is the body returned by DiffDestination.
+type diffResponse struct {
+	City    string                    `json:"city"`
+	Status  string                    `json:"status"`
+	Sources map[string]string         `json:"sources"`
+	Changes []destination.FieldChange `json:"changes"`
+}
+
+// DiffDestination handles POST /api/v1/admin/destinations/{city}/diff.
+// Fetches fresh data for a stored city and returns what a refresh would change,
+// field by field, without storing or caching anything. The stored country is used
+// unless ?country= is given. A provider that fails keeps its stored section, so
+// it shows no changes; sources says which ones did.
+func (h *Handlers) DiffDestination(w http.ResponseWriter, r *http.Request) {
+	if !h.checkParams(w, r, "country") {
+		return
+	}
+	city := chi.URLParam(r, "city")
+
+	country := r.URL.Query().Get("country")
+	stored, err := h.lookup(r.Context(), city, country)
+	if err != nil {
+		h.log.Error("db get failed", "city", city, "err", err)
+		h.writeServerError(w, "internal server error", err)
+		return
+	}
+	if stored == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "destination not found"})
+		return
+	}
+
+	if country == "" {
+		country = stored.Country
+	}
+	res, err := h.fetcher.FetchAll(r.Context(), city, country)
+	if err != nil {
+		h.log.Error("fetch all failed", "city", city, "err", err)
+		h.writeServerError(w, "failed to fetch destination data", err)
+		return
+	}
+	if res == nil || res.Data == nil {
+		h.log.Error("fetch all returned no data", "city", city)
+		h.writeServerError(w, "fetcher returned no data", nil)
+		return
+	}
+	if res.Canceled() {
+		writeJSON(w, statusClientClosedRequest, map[string]string{"error": "request canceled"})
+		return
+	}
+
+	fresh := *res.Data
+	for _, p := range destination.Providers() {
+		if res.Errors[p] != nil {
+			fresh.Reuse(&stored.Data, p)
+		}
+	}
+	changes, err := stored.Data.Diff(&fresh)
+	if err != nil {
+		h.log.Error("diff failed", "city", city, "err", err)
+		h.writeServerError(w, "internal server error", err)
+		return
+	}
+	if changes == nil {
+		changes = []destination.FieldChange{}
+	}
+
+	writeJSON(w, http.StatusOK, diffResponse{City: city, Status: res.Status(), Sources: res.Sources(), Changes: changes})
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/neexbeast/ygo-test/internal/destination"
+)
+
+// collectionETag returns the entity tag for a list response over the stored
+// destinations at version. The query and the language the response is
+// localized to also shape the body, so they are part of the tag.
+func collectionETag(r *http.Request, version destination.CollectionVersion) string {
+	h := sha256.New()
+	h.Write([]byte(strconv.FormatInt(version.UpdatedAt.UnixNano(), 10)))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.Itoa(version.Count)))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.Query().Encode()))
+	h.Write([]byte{0})
+	h.Write([]byte(preferredLanguage(r.Header.Get("Accept-Language"))))
+	return `W/"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header value lists etag, using
+// the weak comparison RFC 9110 prescribes for If-None-Match.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified sets the collection ETag on w and reports whether the client's
+// copy is current, in which case it has already written 304 Not Modified.
+// If the collection version can't be read the response simply goes without an
+// ETag.
+func (h *Handlers) notModified(w http.ResponseWriter, r *http.Request) bool {
+	version, err := h.repo.CollectionVersion(r.Context())
+	if err != nil {
+		h.log.Warn("reading collection version failed, serving without ETag", "err", err)
+		return false
+	}
+
+	etag := collectionETag(r, version)
+	w.Header().Set("ETag", etag)
+	w.Header().Add("Vary", "Accept-Language")
+	if match := r.Header.Get("If-None-Match"); match != "" && etagMatches(match, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/neexbeast/ygo-test/internal/destination"
+)
+
+// regionsResponse is the body returned by ListRegions.
+type regionsResponse struct {
+	Regions []destination.NameCount `json:"regions"`
+}
+
+// countriesResponse is the body returned by ListCountries.
+type countriesResponse struct {
+	Countries []destination.NameCount `json:"countries"`
+}
+
+// ListRegions handles GET /api/v1/regions.
+// Returns every distinct region among stored destinations with its count, for filter dropdowns.
+func (h *Handlers) ListRegions(w http.ResponseWriter, r *http.Request) {
+	if !h.checkParams(w, r) {
+		return
+	}
+	regions, ok := h.distinct(w, r, "regions", h.repo.DistinctRegions)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, regionsResponse{Regions: regions})
+}
+
+// ListCountries handles GET /api/v1/countries.
+// Returns every distinct country among stored destinations with its count, for filter dropdowns.
+func (h *Handlers) ListCountries(w http.ResponseWriter, r *http.Request) {
+	if !h.checkParams(w, r) {
+		return
+	}
+	countries, ok := h.distinct(w, r, "countries", h.repo.DistinctCountries)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, countriesResponse{Countries: countries})
+}
+
+// distinct runs query and returns its results, never nil. On error it writes a
+// 500 and returns false.
+func (h *Handlers) distinct(w http.ResponseWriter, r *http.Request, what string, query func(context.Context) ([]destination.NameCount, error)) ([]destination.NameCount, bool) {
+	counts, err := query(r.Context())
+	if err != nil {
+		h.log.Error("listing distinct "+what+" failed", "err", err)
+		h.writeServerError(w, "internal server error", err)
+		return nil, false
+	}
+	if counts == nil {
+		counts = []destination.NameCount{}
+	}
+	return counts, true
+}
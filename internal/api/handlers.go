@@ -3,11 +3,20 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/neexbeast/ygo-test/internal/destination"
+	"github.com/neexbeast/ygo-test/internal/metrics"
 )
 
 // Handlers holds the dependencies for all HTTP handlers.
@@ -16,18 +25,46 @@ type Handlers struct {
 	cache   DestinationCache
 	fetcher DestinationFetcher
 	log     *slog.Logger
+
+	minProviders     int
+	metrics          *metrics.Metrics
+	pageSizes        PageSizes
+	historySizes     PageSizes
+	errorDetail      bool
+	strictParams     bool
+	fetchOnMiss      bool
+	consistencyCheck bool
+	freshFor         map[string]time.Duration
+	returnStored     bool
+	countryKey       bool
+	notFound         APIError
+	timestampFormat  string
 }
 
 // NewHandlers constructs Handlers with all required dependencies.
-func NewHandlers(repo DestinationRepo, cache DestinationCache, fetcher DestinationFetcher, log *slog.Logger) *Handlers {
-	return &Handlers{
+func NewHandlers(repo DestinationRepo, cache DestinationCache, fetcher DestinationFetcher, log *slog.Logger, opts ...HandlerOption) *Handlers {
+	h := &Handlers{
 		repo:    repo,
 		cache:   cache,
 		fetcher: fetcher,
 		log:     log,
+
+		pageSizes:    PageSizes{Default: defaultPageSize, Max: maxPageSize},
+		historySizes: PageSizes{Default: defaultHistorySize, Max: maxHistorySize},
+		notFound:     DefaultNotFoundError,
+
+		timestampFormat: TimestampRFC3339,
+	}
+	for _, opt := range opts {
+		opt(h)
 	}
+	return h
 }
 
+// statusClientClosedRequest is the non-standard status logged when the client went away
+// before the response was ready; nobody is left to read it.
+const statusClientClosedRequest = 499
+
 // writeJSON encodes v as JSON and writes it with the given status code.
 func writeJSON(w http.ResponseWriter, status int, v any) {
 	w.Header().Set("Content-Type", "application/json")
@@ -35,72 +72,481 @@ func writeJSON(w http.ResponseWriter, status int, v any) {
 	_ = json.NewEncoder(w).Encode(v)
 }
 
+// APIError is an error response body: a human-readable message and, optionally,
+// a stable machine-readable code.
+type APIError struct {
+	Message string `json:"error"`
+	Code    string `json:"code,omitempty"`
+}
+
+// DefaultNotFoundError is the body GetDestination returns for a city with no
+// stored data, unless WithNotFoundError overrides it.
+var DefaultNotFoundError = APIError{Message: "destination not found", Code: "not_found"}
+
+// writeServerError writes a 500 with the generic message msg. With WithErrorDetail
+// the body also carries err's text as "detail", with secrets in URLs redacted.
+func (h *Handlers) writeServerError(w http.ResponseWriter, msg string, err error) {
+	body := map[string]string{"error": msg}
+	if h.errorDetail && err != nil {
+		body["detail"] = redactBody([]byte(redactURLs(err.Error())))
+	}
+	writeJSON(w, http.StatusInternalServerError, body)
+}
+
 // GetDestination handles GET /api/v1/destinations/{city}.
 // Cache hit → return. DB hit → cache + return. Neither → 404.
+// With ?envelope=true, meta.cached reports whether the data came from cache.
+// With ?quality_format=map, quality scores are returned as a name → score object.
+// With ?omit_empty=false, empty collections are sent as []/{} instead of being left out.
+// With ?layout=grouped, sections are nested under the source that provided them.
+// With ?fields=weather,country, only the named top-level sections are returned.
+// For debugging stale data, ?no_cache=true skips the cache read (the DB result is
+// still cached) and ?no_store=true skips writing the cache.
+// A city the DB doesn't have is remembered in the cache (if negative caching is
+// enabled), so repeated 404s don't reach the DB; a successful refresh replaces the marker.
+// With ?max_age=24h (or e.g. 7d), data fetched longer ago than that is never served:
+// a stale cache entry falls through to the DB, and a stale DB record returns 410.
+// With WithCacheConsistencyCheck, a cache hit is served only if the DB holds no
+// newer data; otherwise the DB record is served and re-cached.
+// With ?fetch_on_miss=true (or WithFetchOnMiss), a city the DB doesn't have is
+// fetched and stored as by a refresh instead of returning 404; ?fetch_on_miss=false
+// turns that off for one request.
+// With WithCountryKey, ?country= picks which of several same-named cities to return.
 func (h *Handlers) GetDestination(w http.ResponseWriter, r *http.Request) {
+	params := []string{"no_cache", "no_store", "max_age", "envelope", "quality_format", "omit_empty", "layout", "fields", "fetch_on_miss"}
+	if h.countryKey {
+		params = append(params, "country")
+	}
+	if !h.checkParams(w, r, params...) {
+		return
+	}
+	varyLanguage(w)
 	city := chi.URLParam(r, "city")
-
-	cached, err := h.cache.Get(r.Context(), city)
-	if err != nil {
-		h.log.Error("cache get failed", "city", city, "err", err)
+	var country string
+	if h.countryKey {
+		country = r.URL.Query().Get("country")
 	}
-	if cached != nil {
-		writeJSON(w, http.StatusOK, cached)
+	key := h.cacheKey(city, country)
+	fields, err := parseFields(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
 	}
+	noCache, _ := strconv.ParseBool(r.URL.Query().Get("no_cache"))
+	noStore, _ := strconv.ParseBool(r.URL.Query().Get("no_store"))
+	fetchOnMiss := h.fetchOnMiss
+	if v, err := strconv.ParseBool(r.URL.Query().Get("fetch_on_miss")); err == nil {
+		fetchOnMiss = v
+	}
+
+	var maxAge time.Duration
+	if v := r.URL.Query().Get("max_age"); v != "" {
+		d, err := parseAge(v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid max_age: " + err.Error()})
+			return
+		}
+		maxAge = d
+	}
+
+	if !noCache {
+		cached, err := h.cache.Get(r.Context(), key)
+		if err != nil {
+			h.log.Error("cache get failed", "city", city, "err", err)
+		}
+		if cached != nil && cached.NotFound && !fetchOnMiss {
+			writeJSON(w, http.StatusNotFound, h.notFound)
+			return
+		}
+		if cached != nil && !cached.NotFound && !tooOld(cached.FetchedAt, maxAge) && !h.dbNewer(r.Context(), city, cached) {
+			meta := responseMeta{Cached: true}
+			if !cached.FetchedAt.IsZero() {
+				meta.FetchedAt = &cached.FetchedAt
+			}
+			h.respond(w, r, present(r, cached.Data, fields), meta)
+			return
+		}
+	}
 
-	dest, err := h.repo.GetDestination(r.Context(), city)
+	dest, err := h.lookup(r.Context(), city, country)
 	if err != nil {
 		h.log.Error("db get failed", "city", city, "err", err)
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		h.writeServerError(w, "internal server error", err)
+		return
+	}
+	if dest == nil && fetchOnMiss {
+		res, fetchedAt, ok := h.fetchAndStore(w, r, city, country)
+		if ok {
+			h.respond(w, r, present(r, res.Data, fields), responseMeta{FetchedAt: &fetchedAt})
+		}
 		return
 	}
 	if dest == nil {
-		writeJSON(w, http.StatusNotFound, map[string]string{"error": "destination not found — POST /refresh first"})
+		if !noStore {
+			if err := h.cache.SetNotFound(r.Context(), key); err != nil {
+				h.log.Warn("cache set not-found failed", "city", city, "err", err)
+			}
+		}
+		writeJSON(w, http.StatusNotFound, h.notFound)
 		return
 	}
 
-	if err := h.cache.Set(r.Context(), city, &dest.Data); err != nil {
-		h.log.Warn("cache set failed after db hit", "city", city, "err", err)
+	fetchedAt := dataFetchedAt(dest)
+	if tooOld(fetchedAt, maxAge) {
+		writeJSON(w, http.StatusGone, map[string]string{"error": "data too stale, refresh first"})
+		return
+	}
+
+	if !noStore {
+		if err := h.cache.Set(r.Context(), key, &dest.Data, fetchedAt); err != nil {
+			h.log.Warn("cache set failed after db hit", "city", city, "err", err)
+		}
 	}
 
-	writeJSON(w, http.StatusOK, dest.Data)
+	h.respond(w, r, present(r, &dest.Data, fields), responseMeta{FetchedAt: dest.FetchedAt})
+}
+
+// dbNewer reports whether the DB holds data for city fetched after the cached copy,
+// as when a refresh stored it but failed to update the cache. It is always false
+// without WithCacheConsistencyCheck, and when the DB can't be asked, so the cache
+// is still served. A cached copy of unknown age counts as older. With WithCountryKey
+// it compares against the city's newest row in any country, which at worst sends
+// a read to the DB that the cache could have served.
+//
+// A refresh caches the time its fetch started and the DB records when it stored
+// the result, so the first read after a refresh also goes to the DB once, which
+// re-caches the data with the DB's time.
+func (h *Handlers) dbNewer(ctx context.Context, city string, cached *destination.CachedData) bool {
+	if !h.consistencyCheck {
+		return false
+	}
+	fetchedAt, ok, err := h.repo.DataFetchedAt(ctx, city)
+	if err != nil {
+		h.log.Warn("db fetched_at check failed, serving cache", "city", city, "err", err)
+		return false
+	}
+	return ok && (cached.FetchedAt.IsZero() || fetchedAt.After(cached.FetchedAt))
+}
+
+// dataFetchedAt returns when dest's data was fetched from the providers, falling
+// back to its last update for rows stored before fetched_at was recorded.
+func dataFetchedAt(dest *destination.Destination) time.Time {
+	if dest.FetchedAt != nil {
+		return *dest.FetchedAt
+	}
+	return dest.UpdatedAt
+}
+
+// tooOld reports whether data fetched at fetchedAt exceeds maxAge. A zero maxAge
+// means no limit; a zero fetchedAt (unknown age) counts as too old under a limit.
+func tooOld(fetchedAt time.Time, maxAge time.Duration) bool {
+	if maxAge <= 0 {
+		return false
+	}
+	return fetchedAt.IsZero() || time.Since(fetchedAt) > maxAge
+}
+
+// refreshResponse is the refresh body: the stored data, flattened, plus the overall
+// fetch status so clients can tell a complete refresh from a partial one.
+type refreshResponse struct {
+	*destination.DestinationData
+	Status string `json:"status"`
+}
+
+// refreshDebugResponse is the refresh body returned when ?debug=true is set.
+// The embedded data is flattened so the shape matches the normal response plus timings.
+type refreshDebugResponse struct {
+	refreshResponse
+	Timings map[string]int64 `json:"timings"`
+}
+
+// refreshMinimalResponse is the refresh body returned when ?return=minimal is set.
+type refreshMinimalResponse struct {
+	City      string            `json:"city"`
+	Refreshed bool              `json:"refreshed"`
+	Status    string            `json:"status"`
+	Sources   map[string]string `json:"sources"`
 }
 
 // RefreshDestination handles POST /api/v1/destinations/{city}/refresh.
-// Fetches fresh data, upserts DB, invalidates + repopulates cache.
+// Fetches fresh data, upserts DB, invalidates + repopulates cache. The body echoes
+// the fetched data, or the stored record with WithRefreshReturnsStored.
+// If the client cancels mid-fetch nothing is stored, so partial data from the cut-short fetch is discarded.
+// With ?debug=true the response also carries per-provider timings in milliseconds.
+// With ?return=minimal only the city and per-provider status are returned; this takes precedence over debug.
+// Every body carries a top-level status: complete, partial (some providers failed) or
+// failed (all did, stored only when MinSuccessfulProviders allows it).
 func (h *Handlers) RefreshDestination(w http.ResponseWriter, r *http.Request) {
+	if !h.checkParams(w, r, "country", "debug", "return", "envelope") {
+		return
+	}
+	varyLanguage(w)
 	city := chi.URLParam(r, "city")
-	country := r.URL.Query().Get("country")
-	if country == "" {
-		country = city
+	debug, _ := strconv.ParseBool(r.URL.Query().Get("debug"))
+
+	res, fetchedAt, ok := h.fetchAndStore(w, r, city, r.URL.Query().Get("country"))
+	if !ok {
+		return
 	}
+	data := res.Data
 
-	data, err := h.fetcher.FetchAll(r.Context(), city, country)
+	meta := responseMeta{FetchedAt: &fetchedAt}
+	status := res.Status()
+	if status != destination.FetchComplete {
+		h.log.Warn("refresh stored incomplete data", "city", city, "status", status)
+	}
+
+	if r.URL.Query().Get("return") == "minimal" {
+		h.respond(w, r, refreshMinimalResponse{City: city, Refreshed: true, Status: status, Sources: res.Sources()}, meta)
+		return
+	}
+
+	body := refreshResponse{DestinationData: localize(r, data), Status: status}
+
+	if debug {
+		timings := make(map[string]int64, len(res.Timings))
+		for provider, d := range res.Timings {
+			timings[provider] = d.Milliseconds()
+		}
+		h.respond(w, r, refreshDebugResponse{refreshResponse: body, Timings: timings}, meta)
+		return
+	}
+
+	h.respond(w, r, body, meta)
+}
+
+// fetchAndStore fetches city from its providers, upserts the result and replaces
+// its cache entry. It returns the fetch result and when it was fetched, or writes
+// the error response and returns false if nothing was stored.
+//
+// With WithProviderFreshness, providers whose stored section is still fresh are
+// not called: their sections are carried over from the stored record, and they
+// are reported as skipped. If every section is fresh, the stored record is
+// returned as it is, with nothing fetched or written.
+func (h *Handlers) fetchAndStore(w http.ResponseWriter, r *http.Request, city, country string) (*destination.FetchResult, time.Time, bool) {
+	fetchedAt := time.Now().UTC()
+	stored, stale := h.staleProviders(r.Context(), city, country, fetchedAt)
+	if stored != nil && country == "" {
+		country = stored.Country
+	}
+	if stored != nil && len(stale) == 0 {
+		h.log.Info("refresh skipped, all providers fresh", "city", city)
+		return storedResult(stored), dataFetchedAt(stored), true
+	}
+
+	var res *destination.FetchResult
+	var err error
+	if len(stale) == len(destination.Providers()) {
+		res, err = h.fetcher.FetchAll(r.Context(), city, country)
+	} else {
+		res, err = h.fetcher.FetchProviders(r.Context(), city, country, stale)
+	}
 	if err != nil {
 		h.log.Error("fetch all failed", "city", city, "err", err)
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch destination data"})
-		return
+		h.writeServerError(w, "failed to fetch destination data", err)
+		return nil, fetchedAt, false
+	}
+	// FetchAll always returns data on success today; guard the dereference below
+	// so a fetcher that breaks that contract fails the request instead of panicking.
+	if res == nil || res.Data == nil {
+		h.log.Error("fetch all returned no data", "city", city)
+		h.writeServerError(w, "fetcher returned no data", nil)
+		return nil, fetchedAt, false
+	}
+	switch {
+	case res.Country != "":
+		country = res.Country
+	case country == "":
+		country = city
+	}
+
+	if res.Canceled() {
+		h.log.Info("refresh canceled by client", "city", city)
+		writeJSON(w, statusClientClosedRequest, map[string]string{"error": "request canceled"})
+		return nil, fetchedAt, false
 	}
 
-	if err := h.repo.UpsertDestination(r.Context(), city, country, *data); err != nil {
+	if succeeded := res.SuccessCount(); succeeded < h.minProviders {
+		h.log.Error("too few providers succeeded", "city", city, "succeeded", succeeded, "required", h.minProviders)
+		writeJSON(w, http.StatusBadGateway, map[string]string{
+			"error": "only " + strconv.Itoa(succeeded) + " of " + strconv.Itoa(len(destination.Providers())) +
+				" providers succeeded; " + strconv.Itoa(h.minProviders) + " required",
+		})
+		return nil, fetchedAt, false
+	}
+
+	for _, p := range stale {
+		if res.Errors[p] == nil {
+			if res.Data.SourcesFetchedAt == nil {
+				res.Data.SourcesFetchedAt = make(map[string]time.Time, len(stale))
+			}
+			res.Data.SourcesFetchedAt[p] = fetchedAt
+		}
+	}
+	if stored != nil {
+		for _, p := range destination.Providers() {
+			if !slices.Contains(stale, p) {
+				res.Data.Reuse(&stored.Data, p)
+			}
+		}
+	}
+
+	inserted, err := h.repo.UpsertDestination(r.Context(), city, country, *res.Data, fetchedAt)
+	if err != nil {
 		h.log.Error("upsert failed", "city", city, "err", err)
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to store destination data"})
-		return
+		h.writeServerError(w, "failed to store destination data", err)
+		return nil, fetchedAt, false
 	}
+	h.countUpsert(inserted)
 
-	if err := h.cache.Delete(r.Context(), city); err != nil {
+	if h.returnStored {
+		h.useStored(r.Context(), city, country, res)
+	}
+
+	key := h.cacheKey(city, country)
+	if err := h.cache.Delete(r.Context(), key); err != nil {
 		h.log.Warn("cache delete failed", "city", city, "err", err)
 	}
-	if err := h.cache.Set(r.Context(), city, data); err != nil {
+	// A lookup without a country is cached under the bare city and may now be
+	// a different country's record; drop it so it is read again.
+	if key != city {
+		if err := h.cache.Delete(r.Context(), city); err != nil {
+			h.log.Warn("cache delete failed", "city", city, "err", err)
+		}
+	}
+	if err := h.cache.Set(r.Context(), key, res.Data, fetchedAt); err != nil {
 		h.log.Warn("cache set failed after refresh", "city", city, "err", err)
 	}
+	return res, fetchedAt, true
+}
+
+// staleProviders returns the providers a refresh of city (in country, with
+// WithCountryKey) should call, and the stored record the others can be reused
+// from. Without WithProviderFreshness, or when city has no usable stored record,
+// every provider is stale. A section is fresh while it is younger than its
+// provider's freshness period.
+func (h *Handlers) staleProviders(ctx context.Context, city, country string, now time.Time) (*destination.Destination, []string) {
+	if len(h.freshFor) == 0 {
+		return nil, destination.Providers()
+	}
+	stored, err := h.lookup(ctx, city, country)
+	if err != nil {
+		h.log.Warn("db get before refresh failed, fetching every provider", "city", city, "err", err)
+		return nil, destination.Providers()
+	}
+	if stored == nil {
+		return nil, destination.Providers()
+	}
+
+	var stale []string
+	for _, p := range destination.Providers() {
+		at, ok := stored.Data.SourcesFetchedAt[p]
+		if !ok || now.Sub(at) >= h.freshFor[p] {
+			stale = append(stale, p)
+		}
+	}
+	return stored, stale
+}
+
+// useStored replaces res.Data with city's record as stored in country, which the
+// upsert may have changed. If it cannot be read, res keeps the fetched data.
+func (h *Handlers) useStored(ctx context.Context, city, country string, res *destination.FetchResult) {
+	stored, err := h.lookup(ctx, city, country)
+	if err != nil || stored == nil {
+		h.log.Warn("re-reading stored destination failed, returning fetched data", "city", city, "err", err)
+		return
+	}
+	res.Data = &stored.Data
+}
+
+// lookup returns city's stored record. With WithCountryKey and a country, it is
+// the record for that country; otherwise whichever record GetDestination picks.
+func (h *Handlers) lookup(ctx context.Context, city, country string) (*destination.Destination, error) {
+	if h.countryKey && country != "" {
+		return h.repo.GetDestinationInCountry(ctx, city, country)
+	}
+	return h.repo.GetDestination(ctx, city)
+}
+
+// cacheKey returns the key city's data is cached under: with WithCountryKey and
+// a country, one qualified by the country, so same-named cities don't share it.
+func (h *Handlers) cacheKey(city, country string) string {
+	if h.countryKey && country != "" {
+		return city + countryKeySep + country
+	}
+	return city
+}
+
+// countryKeySep separates city and country in a country-qualified cache key. It
+// cannot appear in a city name.
+const countryKeySep = "@"
+
+// DeleteDestination handles DELETE /api/v1/destinations/{city}.
+// Permanently deletes the stored record and evicts its cache entries, e.g. to
+// undo refreshing the wrong city or for erasure requests: 204 if it existed,
+// 404 if not. Every delete is audit-logged with the request ID, noting whether
+// the admin token was used (see BearerOrAdminAuth).
+func (h *Handlers) DeleteDestination(w http.ResponseWriter, r *http.Request) {
+	if !h.checkParams(w, r) {
+		return
+	}
+	city := chi.URLParam(r, "city")
+
+	deleted, err := h.repo.DeleteDestination(r.Context(), city)
+	if err != nil {
+		h.log.Error("delete destination failed", "city", city, "err", err)
+		h.writeServerError(w, "internal server error", err)
+		return
+	}
+
+	if err := h.evict(r.Context(), city); err != nil {
+		h.log.Warn("cache delete failed after delete", "city", city, "err", err)
+	}
+
+	if !deleted {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "destination not found"})
+		return
+	}
+
+	h.log.Info("destination deleted", "city", city, "admin", isAdminCaller(r), "request_id", middleware.GetReqID(r.Context()))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// evict drops city's cache entry and, with WithCountryKey, its country-qualified
+// ones. Those are matched by pattern, which may also catch other cities whose
+// name ends the same way; evicting them only costs a cache miss.
+func (h *Handlers) evict(ctx context.Context, city string) error {
+	err := h.cache.Delete(ctx, city)
+	if h.countryKey {
+		if _, perr := h.cache.DeleteByPattern(ctx, city+countryKeySep); perr != nil {
+			err = errors.Join(err, perr)
+		}
+	}
+	return err
+}
 
-	writeJSON(w, http.StatusOK, data)
+// storedResult reports stored as the result of a refresh that called no provider.
+func storedResult(stored *destination.Destination) *destination.FetchResult {
+	statuses := make(map[string]string, len(destination.Providers()))
+	for _, p := range destination.Providers() {
+		statuses[p] = destination.SourceSkipped
+	}
+	return &destination.FetchResult{Data: &stored.Data, Statuses: statuses, Country: stored.Country}
 }
 
-// HealthCheck handles GET /api/v1/health.
-// Pings DB and Redis; returns 200 if both ok, 503 otherwise.
+// countUpsert records a stored refresh in the upsert counter, if metrics are enabled.
+func (h *Handlers) countUpsert(inserted bool) {
+	if h.metrics == nil {
+		return
+	}
+	result := metrics.UpsertUpdated
+	if inserted {
+		result = metrics.UpsertInserted
+	}
+	h.metrics.DestinationUpserts.WithLabelValues(result).Inc()
+}
+
+// dbPinger and redisPinger are the dependencies pinged by DefaultHealthChecks.
 type dbPinger interface {
 	Ping(ctx context.Context) error
 }
@@ -109,37 +555,93 @@ type redisPinger interface {
 	Ping(ctx context.Context) error
 }
 
-// HealthHandlerFunc returns an http.HandlerFunc that checks db and redis connectivity.
-func HealthHandlerFunc(db dbPinger, redis redisPinger, log *slog.Logger) http.HandlerFunc {
+// Health severities: how much a failing dependency affects overall health.
+const (
+	// SeverityCritical means the service cannot serve without the dependency: 503.
+	SeverityCritical = "critical"
+	// SeverityDegraded means the service still serves without it: 200 with status "degraded".
+	SeverityDegraded = "degraded"
+)
+
+// HealthSeverities sets the severity of each dependency checked by the health endpoint.
+type HealthSeverities struct {
+	DB    string
+	Redis string
+}
+
+// DefaultHealthSeverities treats the DB as critical and Redis as degraded, since
+// reads fall through to the DB when the cache is unavailable.
+var DefaultHealthSeverities = HealthSeverities{DB: SeverityCritical, Redis: SeverityDegraded}
+
+// HealthCheck is one dependency checked by the health endpoint.
+type HealthCheck struct {
+	// Name is the dependency's key in the response body; it must not be "status".
+	Name string
+	// Check reports whether the dependency is reachable.
+	Check func(ctx context.Context) error
+	// Severity decides what a failing Check means; empty is SeverityCritical.
+	Severity string
+}
+
+// DefaultHealthChecks returns the checks the health endpoint runs by default:
+// pings of db and redis, as "db" and "redis", with the given severities.
+func DefaultHealthChecks(db dbPinger, redis redisPinger, severities HealthSeverities) []HealthCheck {
+	return []HealthCheck{
+		{Name: "db", Check: func(ctx context.Context) error { return db.Ping(ctx) }, Severity: severities.DB},
+		{Name: "redis", Check: func(ctx context.Context) error { return redis.Ping(ctx) }, Severity: severities.Redis},
+	}
+}
+
+// HealthHandlerFunc returns an http.HandlerFunc for GET /api/v1/health that runs
+// every check concurrently, within a shared 3-second timeout. A failing check's
+// severity decides the outcome: a critical one takes the service down (503), a
+// degraded one is reported but still returns 200. The body has each check's result,
+// "ok" or "error", under its name, and the overall status: "ok", "degraded" (only
+// non-critical checks failed) or "down".
+func HealthHandlerFunc(checks []HealthCheck, log *slog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 		defer cancel()
 
-		status := http.StatusOK
-		dbStatus := "ok"
-		redisStatus := "ok"
+		errs := make([]error, len(checks))
+		var wg sync.WaitGroup
+		for i, c := range checks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() {
+					if r := recover(); r != nil {
+						errs[i] = fmt.Errorf("health check %s panicked: %v", c.Name, r)
+					}
+				}()
+				errs[i] = c.Check(ctx)
+			}()
+		}
+		wg.Wait()
 
-		if err := db.Ping(ctx); err != nil {
-			log.Error("health check: db ping failed", "err", err)
-			dbStatus = "error"
-			status = http.StatusServiceUnavailable
+		overall := "ok"
+		body := make(map[string]string, len(checks)+1)
+		for i, c := range checks {
+			if errs[i] == nil {
+				body[c.Name] = "ok"
+				continue
+			}
+			log.Error("health check: "+c.Name+" failed", "err", errs[i])
+			body[c.Name] = "error"
+			if c.Severity == SeverityDegraded {
+				if overall == "ok" {
+					overall = "degraded"
+				}
+			} else {
+				overall = "down"
+			}
 		}
+		body["status"] = overall
 
-		if err := redis.Ping(ctx); err != nil {
-			log.Error("health check: redis ping failed", "err", err)
-			redisStatus = "error"
+		status := http.StatusOK
+		if overall == "down" {
 			status = http.StatusServiceUnavailable
 		}
-
-		writeJSON(w, status, map[string]string{
-			"status": func() string {
-				if status == http.StatusOK {
-					return "ok"
-				}
-				return "degraded"
-			}(),
-			"db":    dbStatus,
-			"redis": redisStatus,
-		})
+		writeJSON(w, status, body)
 	}
 }
@@ -1,60 +1,179 @@
 package api_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/neexbeast/ygo-test/internal/api"
 	"github.com/neexbeast/ygo-test/internal/destination"
+	"github.com/neexbeast/ygo-test/internal/metrics"
 )
 
 // ---- mock implementations ----
 
 type mockRepo struct {
 	getDestinationFn func(ctx context.Context, city string) (*destination.Destination, error)
-	upsertFn         func(ctx context.Context, city, country string, data destination.DestinationData) error
+	getInCountryFn   func(ctx context.Context, city, country string) (*destination.Destination, error)
+	fetchedAtFn      func(ctx context.Context, city string) (time.Time, bool, error)
+	upsertFn         func(ctx context.Context, city, country string, data destination.DestinationData, fetchedAt time.Time) (bool, error)
+	historyFn        func(ctx context.Context, city string, limit int) ([]destination.Snapshot, error)
+	findIncompleteFn func(ctx context.Context, page destination.Page) ([]destination.IncompleteDestination, error)
+	searchFn         func(ctx context.Context, query string, page destination.Page) ([]*destination.Destination, error)
+	minQualityFn     func(ctx context.Context, filter destination.QualityFilter, page destination.Page) ([]*destination.Destination, error)
+	deleteMatchingFn func(ctx context.Context, filter destination.BulkDeleteFilter) ([]string, error)
+	deleteDestFn     func(ctx context.Context, city string) (bool, error)
+	regionsFn        func(ctx context.Context) ([]destination.NameCount, error)
+	countriesFn      func(ctx context.Context) ([]destination.NameCount, error)
+	versionFn        func(ctx context.Context) (destination.CollectionVersion, error)
 }
 
 func (m *mockRepo) GetDestination(ctx context.Context, city string) (*destination.Destination, error) {
 	return m.getDestinationFn(ctx, city)
 }
-func (m *mockRepo) UpsertDestination(ctx context.Context, city, country string, data destination.DestinationData) error {
-	return m.upsertFn(ctx, city, country, data)
+func (m *mockRepo) GetDestinationInCountry(ctx context.Context, city, country string) (*destination.Destination, error) {
+	if m.getInCountryFn == nil {
+		return nil, nil
+	}
+	return m.getInCountryFn(ctx, city, country)
+}
+func (m *mockRepo) DataFetchedAt(ctx context.Context, city string) (time.Time, bool, error) {
+	if m.fetchedAtFn == nil {
+		return time.Time{}, false, nil
+	}
+	return m.fetchedAtFn(ctx, city)
+}
+func (m *mockRepo) UpsertDestination(ctx context.Context, city, country string, data destination.DestinationData, fetchedAt time.Time) (bool, error) {
+	return m.upsertFn(ctx, city, country, data, fetchedAt)
+}
+
+func (m *mockRepo) GetHistory(ctx context.Context, city string, limit int) ([]destination.Snapshot, error) {
+	if m.historyFn == nil {
+		return nil, nil
+	}
+	return m.historyFn(ctx, city, limit)
+}
+
+func (m *mockRepo) FindIncomplete(ctx context.Context, page destination.Page) ([]destination.IncompleteDestination, error) {
+	if m.findIncompleteFn == nil {
+		return nil, nil
+	}
+	return m.findIncompleteFn(ctx, page)
+}
+
+func (m *mockRepo) FullTextSearch(ctx context.Context, query string, page destination.Page) ([]*destination.Destination, error) {
+	if m.searchFn == nil {
+		return nil, nil
+	}
+	return m.searchFn(ctx, query, page)
+}
+
+func (m *mockRepo) FindByMinQuality(ctx context.Context, filter destination.QualityFilter, page destination.Page) ([]*destination.Destination, error) {
+	if m.minQualityFn == nil {
+		return nil, nil
+	}
+	return m.minQualityFn(ctx, filter, page)
+}
+
+func (m *mockRepo) DeleteMatching(ctx context.Context, filter destination.BulkDeleteFilter) ([]string, error) {
+	if m.deleteMatchingFn == nil {
+		return nil, nil
+	}
+	return m.deleteMatchingFn(ctx, filter)
+}
+
+func (m *mockRepo) DeleteDestination(ctx context.Context, city string) (bool, error) {
+	if m.deleteDestFn == nil {
+		return false, nil
+	}
+	return m.deleteDestFn(ctx, city)
+}
+
+func (m *mockRepo) DistinctRegions(ctx context.Context) ([]destination.NameCount, error) {
+	if m.regionsFn == nil {
+		return nil, nil
+	}
+	return m.regionsFn(ctx)
+}
+
+func (m *mockRepo) DistinctCountries(ctx context.Context) ([]destination.NameCount, error) {
+	if m.countriesFn == nil {
+		return nil, nil
+	}
+	return m.countriesFn(ctx)
+}
+
+func (m *mockRepo) CollectionVersion(ctx context.Context) (destination.CollectionVersion, error) {
+	if m.versionFn == nil {
+		return destination.CollectionVersion{}, nil
+	}
+	return m.versionFn(ctx)
 }
 
 type mockCache struct {
-	getFn    func(ctx context.Context, city string) (*destination.DestinationData, error)
-	setFn    func(ctx context.Context, city string, data *destination.DestinationData) error
+	getFn    func(ctx context.Context, city string) (*destination.CachedData, error)
+	setFn    func(ctx context.Context, city string, data *destination.DestinationData, fetchedAt time.Time) error
 	deleteFn func(ctx context.Context, city string) error
+
+	setNotFoundFn     func(ctx context.Context, city string) error
+	deleteByPatternFn func(ctx context.Context, pattern string) (int, error)
 }
 
-func (m *mockCache) Get(ctx context.Context, city string) (*destination.DestinationData, error) {
+func (m *mockCache) Get(ctx context.Context, city string) (*destination.CachedData, error) {
 	return m.getFn(ctx, city)
 }
-func (m *mockCache) Set(ctx context.Context, city string, data *destination.DestinationData) error {
-	return m.setFn(ctx, city, data)
+func (m *mockCache) Set(ctx context.Context, city string, data *destination.DestinationData, fetchedAt time.Time) error {
+	return m.setFn(ctx, city, data, fetchedAt)
+}
+func (m *mockCache) SetNotFound(ctx context.Context, city string) error {
+	if m.setNotFoundFn == nil {
+		return nil
+	}
+	return m.setNotFoundFn(ctx, city)
 }
 func (m *mockCache) Delete(ctx context.Context, city string) error {
 	return m.deleteFn(ctx, city)
 }
+func (m *mockCache) DeleteByPattern(ctx context.Context, pattern string) (int, error) {
+	if m.deleteByPatternFn == nil {
+		return 0, nil
+	}
+	return m.deleteByPatternFn(ctx, pattern)
+}
 
 type mockFetcher struct {
-	fetchAllFn func(ctx context.Context, city, country string) (*destination.DestinationData, error)
+	fetchAllFn       func(ctx context.Context, city, country string) (*destination.FetchResult, error)
+	fetchProvidersFn func(ctx context.Context, city, country string, providers []string) (*destination.FetchResult, error)
+	probeProviderFn  func(ctx context.Context, provider string) (*destination.ProbeResult, error)
 }
 
-func (m *mockFetcher) FetchAll(ctx context.Context, city, country string) (*destination.DestinationData, error) {
+func (m *mockFetcher) FetchAll(ctx context.Context, city, country string) (*destination.FetchResult, error) {
 	return m.fetchAllFn(ctx, city, country)
 }
 
+func (m *mockFetcher) FetchProviders(ctx context.Context, city, country string, providers []string) (*destination.FetchResult, error) {
+	return m.fetchProvidersFn(ctx, city, country, providers)
+}
+
+func (m *mockFetcher) ProbeProvider(ctx context.Context, provider string) (*destination.ProbeResult, error) {
+	return m.probeProviderFn(ctx, provider)
+}
+
 type mockPinger struct{ err error }
 
 func (m *mockPinger) Ping(_ context.Context) error { return m.err }
@@ -67,6 +186,10 @@ func sampleData() *destination.DestinationData {
 	}
 }
 
+func sampleResult() *destination.FetchResult {
+	return &destination.FetchResult{Data: sampleData()}
+}
+
 func sampleDest() *destination.Destination {
 	return &destination.Destination{
 		ID:      1,
@@ -76,9 +199,38 @@ func sampleDest() *destination.Destination {
 	}
 }
 
-const testToken = "secret-token"
+// noopRepo returns a mockRepo whose methods succeed with no data.
+func noopRepo() *mockRepo {
+	return &mockRepo{
+		getDestinationFn: func(_ context.Context, _ string) (*destination.Destination, error) { return nil, nil },
+		upsertFn: func(_ context.Context, _, _ string, _ destination.DestinationData, _ time.Time) (bool, error) {
+			return true, nil
+		},
+	}
+}
+
+// noopCache returns a mockCache that always misses and accepts writes.
+func noopCache() *mockCache {
+	return &mockCache{
+		getFn:    func(_ context.Context, _ string) (*destination.CachedData, error) { return nil, nil },
+		setFn:    func(_ context.Context, _ string, _ *destination.DestinationData, _ time.Time) error { return nil },
+		deleteFn: func(_ context.Context, _ string) error { return nil },
+	}
+}
+
+const (
+	testToken      = "secret-token"
+	testAdminToken = "admin-token"
+)
+
+// buildAdminRouter builds a router with the admin routes mounted under testAdminToken.
+func buildAdminRouter(repo api.DestinationRepo, cache api.DestinationCache, fetcher api.DestinationFetcher, opts ...api.HandlerOption) http.Handler {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handlers := api.NewHandlers(repo, cache, fetcher, log, opts...)
+	return api.NewRouter(handlers, testToken, &mockPinger{}, &mockPinger{}, log, api.WithAdminToken(testAdminToken))
+}
 
-func buildRouter(repo api.DestinationRepo, cache api.DestinationCache, fetcher api.DestinationFetcher, db, redis *mockPinger) http.Handler {
+func buildRouter(repo api.DestinationRepo, cache api.DestinationCache, fetcher api.DestinationFetcher, db, redis *mockPinger, opts ...api.HandlerOption) http.Handler {
 	if db == nil {
 		db = &mockPinger{}
 	}
@@ -86,7 +238,7 @@ func buildRouter(repo api.DestinationRepo, cache api.DestinationCache, fetcher a
 		redis = &mockPinger{}
 	}
 	log := slog.New(slog.NewTextHandler(io.Discard, nil))
-	handlers := api.NewHandlers(repo, cache, fetcher, log)
+	handlers := api.NewHandlers(repo, cache, fetcher, log, opts...)
 	return api.NewRouter(handlers, testToken, db, redis, log)
 }
 
@@ -99,15 +251,19 @@ func TestGetDestination_CacheHit(t *testing.T) {
 			t.Fatal("repo should not be called on cache hit")
 			return nil, nil
 		},
-		upsertFn: func(_ context.Context, _, _ string, _ destination.DestinationData) error { return nil },
+		upsertFn: func(_ context.Context, _, _ string, _ destination.DestinationData, _ time.Time) (bool, error) {
+			return true, nil
+		},
 	}
 	cache := &mockCache{
-		getFn:    func(_ context.Context, _ string) (*destination.DestinationData, error) { return data, nil },
-		setFn:    func(_ context.Context, _ string, _ *destination.DestinationData) error { return nil },
+		getFn: func(_ context.Context, _ string) (*destination.CachedData, error) {
+			return &destination.CachedData{Data: data}, nil
+		},
+		setFn:    func(_ context.Context, _ string, _ *destination.DestinationData, _ time.Time) error { return nil },
 		deleteFn: func(_ context.Context, _ string) error { return nil },
 	}
 	fetcher := &mockFetcher{
-		fetchAllFn: func(_ context.Context, _, _ string) (*destination.DestinationData, error) { return data, nil },
+		fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) { return sampleResult(), nil },
 	}
 
 	router := buildRouter(repo, cache, fetcher, nil, nil)
@@ -128,18 +284,20 @@ func TestGetDestination_DBHit_CacheMiss(t *testing.T) {
 		getDestinationFn: func(_ context.Context, _ string) (*destination.Destination, error) {
 			return sampleDest(), nil
 		},
-		upsertFn: func(_ context.Context, _, _ string, _ destination.DestinationData) error { return nil },
+		upsertFn: func(_ context.Context, _, _ string, _ destination.DestinationData, _ time.Time) (bool, error) {
+			return true, nil
+		},
 	}
 	cache := &mockCache{
-		getFn: func(_ context.Context, _ string) (*destination.DestinationData, error) { return nil, nil },
-		setFn: func(_ context.Context, _ string, _ *destination.DestinationData) error {
+		getFn: func(_ context.Context, _ string) (*destination.CachedData, error) { return nil, nil },
+		setFn: func(_ context.Context, _ string, _ *destination.DestinationData, _ time.Time) error {
 			setCalled = true
 			return nil
 		},
 		deleteFn: func(_ context.Context, _ string) error { return nil },
 	}
 	fetcher := &mockFetcher{
-		fetchAllFn: func(_ context.Context, _, _ string) (*destination.DestinationData, error) { return sampleData(), nil },
+		fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) { return sampleResult(), nil },
 	}
 
 	router := buildRouter(repo, cache, fetcher, nil, nil)
@@ -155,15 +313,17 @@ func TestGetDestination_DBHit_CacheMiss(t *testing.T) {
 func TestGetDestination_NotFound(t *testing.T) {
 	repo := &mockRepo{
 		getDestinationFn: func(_ context.Context, _ string) (*destination.Destination, error) { return nil, nil },
-		upsertFn:         func(_ context.Context, _, _ string, _ destination.DestinationData) error { return nil },
+		upsertFn: func(_ context.Context, _, _ string, _ destination.DestinationData, _ time.Time) (bool, error) {
+			return true, nil
+		},
 	}
 	cache := &mockCache{
-		getFn:    func(_ context.Context, _ string) (*destination.DestinationData, error) { return nil, nil },
-		setFn:    func(_ context.Context, _ string, _ *destination.DestinationData) error { return nil },
+		getFn:    func(_ context.Context, _ string) (*destination.CachedData, error) { return nil, nil },
+		setFn:    func(_ context.Context, _ string, _ *destination.DestinationData, _ time.Time) error { return nil },
 		deleteFn: func(_ context.Context, _ string) error { return nil },
 	}
 	fetcher := &mockFetcher{
-		fetchAllFn: func(_ context.Context, _, _ string) (*destination.DestinationData, error) { return sampleData(), nil },
+		fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) { return sampleResult(), nil },
 	}
 
 	router := buildRouter(repo, cache, fetcher, nil, nil)
@@ -175,20 +335,61 @@ func TestGetDestination_NotFound(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Code)
 }
 
+func TestGetDestination_NotFoundBody(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []api.HandlerOption
+		want string
+	}{
+		{name: "default", want: `{"error":"destination not found","code":"not_found"}`},
+		{
+			name: "configured",
+			opts: []api.HandlerOption{api.WithNotFoundError(api.APIError{Message: "unknown city", Code: "city_unknown"})},
+			want: `{"error":"unknown city","code":"city_unknown"}`,
+		},
+		{
+			name: "message only",
+			opts: []api.HandlerOption{api.WithNotFoundError(api.APIError{Message: "unknown city"})},
+			want: `{"error":"unknown city","code":"not_found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newMemCache()
+			router := buildRouter(noopRepo(), cache, nil, nil, nil, tt.opts...)
+
+			// The first request misses the DB; the second hits the not-found cache marker.
+			for range 2 {
+				req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Atlantis", nil)
+				req.Header.Set("Authorization", "Bearer "+testToken)
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				require.Equal(t, http.StatusNotFound, w.Code)
+				assert.JSONEq(t, tt.want, w.Body.String())
+			}
+			require.True(t, cache.entries["Atlantis"].NotFound)
+		})
+	}
+}
+
 func TestGetDestination_DBError(t *testing.T) {
 	repo := &mockRepo{
 		getDestinationFn: func(_ context.Context, _ string) (*destination.Destination, error) {
 			return nil, fmt.Errorf("db down")
 		},
-		upsertFn: func(_ context.Context, _, _ string, _ destination.DestinationData) error { return nil },
+		upsertFn: func(_ context.Context, _, _ string, _ destination.DestinationData, _ time.Time) (bool, error) {
+			return true, nil
+		},
 	}
 	cache := &mockCache{
-		getFn:    func(_ context.Context, _ string) (*destination.DestinationData, error) { return nil, nil },
-		setFn:    func(_ context.Context, _ string, _ *destination.DestinationData) error { return nil },
+		getFn:    func(_ context.Context, _ string) (*destination.CachedData, error) { return nil, nil },
+		setFn:    func(_ context.Context, _ string, _ *destination.DestinationData, _ time.Time) error { return nil },
 		deleteFn: func(_ context.Context, _ string) error { return nil },
 	}
 	fetcher := &mockFetcher{
-		fetchAllFn: func(_ context.Context, _, _ string) (*destination.DestinationData, error) { return sampleData(), nil },
+		fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) { return sampleResult(), nil },
 	}
 
 	router := buildRouter(repo, cache, fetcher, nil, nil)
@@ -200,162 +401,3672 @@ func TestGetDestination_DBError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
 
-// ---- POST /api/v1/destinations/{city}/refresh ----
+func TestGetDestination_LocalizedRegion(t *testing.T) {
+	data := sampleData()
+	data.Country = &destination.CountryData{Region: "Europe", Capital: "Paris"}
 
-func TestRefreshDestination_Success(t *testing.T) {
-	upsertCalled := false
-	repo := &mockRepo{
-		getDestinationFn: func(_ context.Context, _ string) (*destination.Destination, error) { return sampleDest(), nil },
-		upsertFn: func(_ context.Context, _, _ string, _ destination.DestinationData) error {
-			upsertCalled = true
-			return nil
-		},
+	cache := noopCache()
+	cache.getFn = func(_ context.Context, _ string) (*destination.CachedData, error) {
+		return &destination.CachedData{Data: data}, nil
 	}
-	cache := &mockCache{
-		getFn:    func(_ context.Context, _ string) (*destination.DestinationData, error) { return nil, nil },
-		setFn:    func(_ context.Context, _ string, _ *destination.DestinationData) error { return nil },
-		deleteFn: func(_ context.Context, _ string) error { return nil },
+
+	router := buildRouter(noopRepo(), cache, nil, nil, nil)
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "", want: "Europe"},
+		{header: "de-DE,de;q=0.9", want: "Europa"},
+		{header: "ja, fr;q=0.5", want: "Europe"},
+		{header: "ja, es;q=0.5", want: "Europa"},
+		{header: "en;q=0.8, fr", want: "Europe"},
+		{header: "en, de;q=0.5", want: "Europe"},
+		{header: "en-GB;q=0.4, de", want: "Europa"},
+		{header: "en;q=0, de;q=0.1", want: "Europa"},
 	}
-	fetcher := &mockFetcher{
-		fetchAllFn: func(_ context.Context, _, _ string) (*destination.DestinationData, error) { return sampleData(), nil },
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris", nil)
+		req.Header.Set("Authorization", "Bearer "+testToken)
+		if tt.header != "" {
+			req.Header.Set("Accept-Language", tt.header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var got destination.DestinationData
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+		require.NotNil(t, got.Country)
+		assert.Equal(t, tt.want, got.Country.Region, "Accept-Language %q", tt.header)
+		assert.Contains(t, w.Header().Values("Vary"), "Accept-Language")
 	}
 
-	router := buildRouter(repo, cache, fetcher, nil, nil)
-	req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh?country=France", nil)
+	assert.Equal(t, "Europe", data.Country.Region, "cached value must stay in English")
+}
+
+func TestGetDestination_CacheOverrides(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantRead   bool
+		wantStored bool
+		wantTemp   float64
+	}{
+		{name: "default reads cache", query: "", wantRead: true, wantStored: false, wantTemp: 22.5},
+		{name: "no_cache goes to db and still stores", query: "?no_cache=true", wantRead: false, wantStored: true, wantTemp: 10},
+		{name: "no_cache and no_store", query: "?no_cache=true&no_store=true", wantRead: false, wantStored: false, wantTemp: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var read, stored bool
+			cache := noopCache()
+			cache.getFn = func(_ context.Context, _ string) (*destination.CachedData, error) {
+				read = true
+				return &destination.CachedData{Data: sampleData()}, nil
+			}
+			cache.setFn = func(_ context.Context, _ string, _ *destination.DestinationData, _ time.Time) error {
+				stored = true
+				return nil
+			}
+			repo := noopRepo()
+			repo.getDestinationFn = func(_ context.Context, _ string) (*destination.Destination, error) {
+				dest := sampleDest()
+				dest.Data.Weather = &destination.WeatherData{Temperature: 10}
+				return dest, nil
+			}
+
+			router := buildRouter(repo, cache, nil, nil, nil)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.wantRead, read, "cache read")
+			assert.Equal(t, tt.wantStored, stored, "cache write")
+
+			var body destination.DestinationData
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			require.NotNil(t, body.Weather)
+			assert.Equal(t, tt.wantTemp, body.Weather.Temperature)
+		})
+	}
+}
+
+func TestGetDestination_NoStoreOnCacheMiss(t *testing.T) {
+	stored := false
+	cache := noopCache()
+	cache.setFn = func(_ context.Context, _ string, _ *destination.DestinationData, _ time.Time) error {
+		stored = true
+		return nil
+	}
+	repo := noopRepo()
+	repo.getDestinationFn = func(_ context.Context, _ string) (*destination.Destination, error) { return sampleDest(), nil }
+
+	router := buildRouter(repo, cache, nil, nil, nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris?no_store=true", nil)
 	req.Header.Set("Authorization", "Bearer "+testToken)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusOK, w.Code)
-	assert.True(t, upsertCalled)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, stored)
 }
 
-func TestRefreshDestination_FetchError(t *testing.T) {
-	repo := &mockRepo{
-		getDestinationFn: func(_ context.Context, _ string) (*destination.Destination, error) { return nil, nil },
-		upsertFn:         func(_ context.Context, _, _ string, _ destination.DestinationData) error { return nil },
+func TestGetDestination_MaxAge(t *testing.T) {
+	now := time.Now()
+	at := func(ago time.Duration) time.Time { return now.Add(-ago) }
+
+	tests := []struct {
+		name       string
+		query      string
+		cached     *destination.CachedData
+		dbFetched  time.Time
+		wantStatus int
+		wantTemp   float64
+	}{
+		{
+			name:       "fresh cache served",
+			query:      "?max_age=24h",
+			cached:     &destination.CachedData{Data: sampleData(), FetchedAt: at(time.Hour)},
+			wantStatus: http.StatusOK,
+			wantTemp:   22.5,
+		},
+		{
+			name:       "stale cache falls through to fresh db",
+			query:      "?max_age=24h",
+			cached:     &destination.CachedData{Data: sampleData(), FetchedAt: at(48 * time.Hour)},
+			dbFetched:  at(time.Hour),
+			wantStatus: http.StatusOK,
+			wantTemp:   10,
+		},
+		{
+			name:       "stale db rejected",
+			query:      "?max_age=1d",
+			dbFetched:  at(48 * time.Hour),
+			wantStatus: http.StatusGone,
+		},
+		{
+			name:       "no limit serves stale data",
+			cached:     &destination.CachedData{Data: sampleData(), FetchedAt: at(48 * time.Hour)},
+			wantStatus: http.StatusOK,
+			wantTemp:   22.5,
+		},
+		{
+			name:       "unknown cache age treated as stale",
+			query:      "?max_age=24h",
+			cached:     &destination.CachedData{Data: sampleData()},
+			dbFetched:  at(time.Hour),
+			wantStatus: http.StatusOK,
+			wantTemp:   10,
+		},
+		{
+			name:       "invalid",
+			query:      "?max_age=soon",
+			wantStatus: http.StatusBadRequest,
+		},
 	}
-	cache := &mockCache{
-		getFn:    func(_ context.Context, _ string) (*destination.DestinationData, error) { return nil, nil },
-		setFn:    func(_ context.Context, _ string, _ *destination.DestinationData) error { return nil },
-		deleteFn: func(_ context.Context, _ string) error { return nil },
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := noopCache()
+			cache.getFn = func(_ context.Context, _ string) (*destination.CachedData, error) { return tt.cached, nil }
+			repo := noopRepo()
+			repo.getDestinationFn = func(_ context.Context, _ string) (*destination.Destination, error) {
+				dest := sampleDest()
+				dest.Data.Weather = &destination.WeatherData{Temperature: 10}
+				dest.FetchedAt = &tt.dbFetched
+				return dest, nil
+			}
+
+			router := buildRouter(repo, cache, nil, nil, nil)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusGone {
+				assert.Contains(t, w.Body.String(), "data too stale, refresh first")
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var body destination.DestinationData
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			require.NotNil(t, body.Weather)
+			assert.Equal(t, tt.wantTemp, body.Weather.Temperature)
+		})
 	}
-	fetcher := &mockFetcher{
-		fetchAllFn: func(_ context.Context, _, _ string) (*destination.DestinationData, error) {
-			return nil, fmt.Errorf("all APIs down")
-		},
+}
+
+func TestGetDestination_CachesDBFetchTime(t *testing.T) {
+	fetchedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var stored time.Time
+	cache := noopCache()
+	cache.setFn = func(_ context.Context, _ string, _ *destination.DestinationData, at time.Time) error {
+		stored = at
+		return nil
+	}
+	repo := noopRepo()
+	repo.getDestinationFn = func(_ context.Context, _ string) (*destination.Destination, error) {
+		dest := sampleDest()
+		dest.FetchedAt = &fetchedAt
+		return dest, nil
 	}
 
-	router := buildRouter(repo, cache, fetcher, nil, nil)
-	req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh", nil)
+	router := buildRouter(repo, cache, nil, nil, nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris", nil)
 	req.Header.Set("Authorization", "Bearer "+testToken)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, fetchedAt, stored, "the cache entry keeps the original fetch time, not the time it was cached")
 }
 
-func TestRefreshDestination_UpsertError(t *testing.T) {
-	repo := &mockRepo{
-		getDestinationFn: func(_ context.Context, _ string) (*destination.Destination, error) { return nil, nil },
-		upsertFn: func(_ context.Context, _, _ string, _ destination.DestinationData) error {
-			return fmt.Errorf("db error")
+func TestGetDestination_QualityFormat(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  any
+	}{
+		{
+			name:  "default array",
+			query: "",
+			want:  []any{map[string]any{"name": "Safety", "score_out_of_10": 7.5}},
+		},
+		{
+			name:  "unknown format keeps array",
+			query: "?quality_format=list",
+			want:  []any{map[string]any{"name": "Safety", "score_out_of_10": 7.5}},
+		},
+		{
+			name:  "map",
+			query: "?quality_format=map",
+			want:  map[string]any{"Safety": 7.5},
 		},
 	}
-	cache := &mockCache{
-		getFn:    func(_ context.Context, _ string) (*destination.DestinationData, error) { return nil, nil },
-		setFn:    func(_ context.Context, _ string, _ *destination.DestinationData) error { return nil },
-		deleteFn: func(_ context.Context, _ string) error { return nil },
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := noopCache()
+			cache.getFn = func(_ context.Context, _ string) (*destination.CachedData, error) {
+				data := sampleData()
+				data.QualityScores = []destination.QualityScore{{Name: "Safety", ScoreOutOf: 7.5}}
+				return &destination.CachedData{Data: data}, nil
+			}
+			router := buildRouter(noopRepo(), cache, nil, nil, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			assert.Equal(t, tt.want, body["quality_scores"])
+			assert.Contains(t, body, "weather")
+		})
 	}
-	fetcher := &mockFetcher{
-		fetchAllFn: func(_ context.Context, _, _ string) (*destination.DestinationData, error) { return sampleData(), nil },
+}
+
+func TestGetDestination_Layout(t *testing.T) {
+	data := &destination.DestinationData{
+		Weather:       &destination.WeatherData{Temperature: 22.5, Description: "clear sky"},
+		PointsOfInt:   []destination.POI{{Name: "Louvre", Kinds: "museums", Rate: 7}},
+		Country:       &destination.CountryData{Region: "Europe", Capital: "Paris"},
+		QualityScores: []destination.QualityScore{{Name: "Safety", ScoreOutOf: 6.5}},
+		ExchangeRates: map[string]float64{"USD": 1, "EUR": 0.9},
 	}
+	weather := `{"temperature":22.5,"feels_like":0,"humidity":0,"description":"clear sky","wind_speed":0}`
+	pois := `[{"name":"Louvre","kinds":"museums","rate":7}]`
+	country := `{"currencies":null,"languages":null,"region":"Europe","capital":"Paris"}`
+	rates := `{"USD":1,"EUR":0.9}`
 
-	router := buildRouter(repo, cache, fetcher, nil, nil)
-	req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh", nil)
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name: "flat by default",
+			want: `{"weather":` + weather + `,"points_of_interest":` + pois + `,"country":` + country +
+				`,"quality_scores":[{"name":"Safety","score_out_of_10":6.5}],"exchange_rates":` + rates + `}`,
+		},
+		{
+			name:  "unknown layout stays flat",
+			query: "?layout=nested",
+			want: `{"weather":` + weather + `,"points_of_interest":` + pois + `,"country":` + country +
+				`,"quality_scores":[{"name":"Safety","score_out_of_10":6.5}],"exchange_rates":` + rates + `}`,
+		},
+		{
+			name:  "grouped",
+			query: "?layout=grouped",
+			want: `{"providers":{"weather":` + weather + `,"poi":` + pois + `,"country":` + country +
+				`,"teleport":[{"name":"Safety","score_out_of_10":6.5}],"exchange":` + rates + `}}`,
+		},
+		{
+			name:  "grouped with quality map",
+			query: "?layout=grouped&quality_format=map",
+			want: `{"providers":{"weather":` + weather + `,"poi":` + pois + `,"country":` + country +
+				`,"teleport":{"Safety":6.5},"exchange":` + rates + `}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := noopCache()
+			cache.getFn = func(_ context.Context, _ string) (*destination.CachedData, error) {
+				return &destination.CachedData{Data: data}, nil
+			}
+			router := buildRouter(noopRepo(), cache, nil, nil, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.JSONEq(t, tt.want, w.Body.String())
+		})
+	}
+}
+
+func TestGetDestination_Fields(t *testing.T) {
+	data := &destination.DestinationData{
+		Weather:       &destination.WeatherData{Temperature: 22.5, Description: "clear sky"},
+		PointsOfInt:   []destination.POI{{Name: "Louvre", Kinds: "museums", Rate: 7}},
+		Country:       &destination.CountryData{Region: "Europe", Capital: "Paris"},
+		QualityScores: []destination.QualityScore{{Name: "Safety", ScoreOutOf: 6.5}},
+	}
+	weather := `{"temperature":22.5,"feels_like":0,"humidity":0,"description":"clear sky","wind_speed":0}`
+	country := `{"currencies":null,"languages":null,"region":"Europe","capital":"Paris"}`
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode int
+		want     string
+	}{
+		{
+			name:     "single field",
+			query:    "?fields=weather",
+			wantCode: http.StatusOK,
+			want:     `{"weather":` + weather + `}`,
+		},
+		{
+			name:     "multiple fields",
+			query:    "?fields=weather,%20country",
+			wantCode: http.StatusOK,
+			want:     `{"weather":` + weather + `,"country":` + country + `}`,
+		},
+		{
+			name:     "empty section stays omitted",
+			query:    "?fields=weather,exchange_rates",
+			wantCode: http.StatusOK,
+			want:     `{"weather":` + weather + `}`,
+		},
+		{
+			name:     "with grouped layout",
+			query:    "?fields=country,quality_scores&layout=grouped",
+			wantCode: http.StatusOK,
+			want:     `{"providers":{"country":` + country + `,"teleport":[{"name":"Safety","score_out_of_10":6.5}]}}`,
+		},
+		{
+			name:     "unknown field",
+			query:    "?fields=weather,hotels,flights",
+			wantCode: http.StatusBadRequest,
+			want:     `{"error":"unknown fields: hotels, flights"}`,
+		},
+		{
+			name:     "no field names",
+			query:    "?fields=,",
+			wantCode: http.StatusBadRequest,
+			want:     `{"error":"fields must name at least one section"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := noopCache()
+			cache.getFn = func(_ context.Context, _ string) (*destination.CachedData, error) {
+				return &destination.CachedData{Data: data}, nil
+			}
+			router := buildRouter(noopRepo(), cache, nil, nil, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantCode, w.Code)
+			assert.JSONEq(t, tt.want, w.Body.String())
+		})
+	}
+}
+
+func TestGetDestination_GroupedLayoutKeepsEmptySections(t *testing.T) {
+	cache := noopCache()
+	cache.getFn = func(_ context.Context, _ string) (*destination.CachedData, error) {
+		return &destination.CachedData{Data: &destination.DestinationData{Country: &destination.CountryData{Region: "Europe"}}}, nil
+	}
+	router := buildRouter(noopRepo(), cache, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris?layout=grouped&omit_empty=false", nil)
 	req.Header.Set("Authorization", "Bearer "+testToken)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"providers":{
+		"weather":null,
+		"poi":[],
+		"country":{"currencies":{},"languages":[],"region":"Europe","capital":""},
+		"teleport":[],
+		"exchange":{},
+		"alerts":[]
+	}}`, w.Body.String())
 }
 
-// ---- GET /api/v1/health ----
+func TestGetDestination_OmitEmpty(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  map[string]any
+	}{
+		{
+			name:  "default omits empty fields",
+			query: "",
+			want: map[string]any{
+				"weather": map[string]any{"temperature": 22.5, "feels_like": 0.0, "humidity": 0.0, "description": "clear sky", "wind_speed": 0.0},
+				"country": map[string]any{"currencies": nil, "languages": nil, "region": "Europe", "capital": ""},
+			},
+		},
+		{
+			name:  "omit_empty=false keeps every field",
+			query: "?omit_empty=false",
+			want: map[string]any{
+				"weather":            nil,
+				"points_of_interest": []any{},
+				"country":            map[string]any{"currencies": map[string]any{}, "languages": []any{}, "region": "Europe", "capital": ""},
+				"quality_scores":     []any{},
+				"exchange_rates":     map[string]any{},
+				"alerts":             []any{},
+			},
+		},
+		{
+			name:  "omit_empty=false with quality map",
+			query: "?omit_empty=false&quality_format=map",
+			want: map[string]any{
+				"weather":            nil,
+				"points_of_interest": []any{},
+				"country":            map[string]any{"currencies": map[string]any{}, "languages": []any{}, "region": "Europe", "capital": ""},
+				"quality_scores":     map[string]any{},
+				"exchange_rates":     map[string]any{},
+				"alerts":             []any{},
+			},
+		},
+	}
 
-func TestHealth_OK(t *testing.T) {
-	router := buildRouter(nil, nil, nil, &mockPinger{}, &mockPinger{})
-	req := httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := noopCache()
+			cache.getFn = func(_ context.Context, _ string) (*destination.CachedData, error) {
+				data := &destination.DestinationData{Country: &destination.CountryData{Region: "Europe"}}
+				if tt.query == "" {
+					data.Weather = sampleData().Weather
+				}
+				return &destination.CachedData{Data: data}, nil
+			}
+			router := buildRouter(noopRepo(), cache, nil, nil, nil)
 
-	assert.Equal(t, http.StatusOK, w.Code)
-	var body map[string]string
-	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
-	assert.Equal(t, "ok", body["status"])
-	assert.Equal(t, "ok", body["db"])
-	assert.Equal(t, "ok", body["redis"])
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			assert.Equal(t, tt.want, body)
+		})
+	}
 }
 
-func TestHealth_DBDown(t *testing.T) {
-	router := buildRouter(nil, nil, nil,
-		&mockPinger{err: fmt.Errorf("db unreachable")},
-		&mockPinger{},
-	)
-	req := httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
+func TestGetDestination_HidesSourcesFetchedAt(t *testing.T) {
+	cache := noopCache()
+	cache.getFn = func(_ context.Context, _ string) (*destination.CachedData, error) {
+		data := sampleData()
+		data.SourcesFetchedAt = map[string]time.Time{destination.ProviderWeather: time.Now()}
+		return &destination.CachedData{Data: data}, nil
+	}
+	router := buildRouter(noopRepo(), cache, nil, nil, nil)
 
-	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
-	var body map[string]string
-	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
-	assert.Equal(t, "error", body["db"])
+	for _, query := range []string{"", "?omit_empty=false", "?layout=grouped"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+testToken)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code, query)
+		assert.NotContains(t, w.Body.String(), "sources_fetched_at", query)
+	}
 }
 
-func TestHealth_RedisDown(t *testing.T) {
-	router := buildRouter(nil, nil, nil,
-		&mockPinger{},
-		&mockPinger{err: fmt.Errorf("redis unreachable")},
-	)
-	req := httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
+func TestGetDestination_OmitEmptyKeepsAlerts(t *testing.T) {
+	alert := destination.WeatherAlert{
+		Event:       "Heat warning",
+		Description: "Temperatures above 35°C.",
+		Start:       time.Date(2026, 7, 1, 10, 0, 0, 0, time.UTC),
+		End:         time.Date(2026, 7, 2, 10, 0, 0, 0, time.UTC),
+	}
+	cache := noopCache()
+	cache.getFn = func(_ context.Context, _ string) (*destination.CachedData, error) {
+		data := sampleData()
+		data.Alerts = []destination.WeatherAlert{alert}
+		return &destination.CachedData{Data: data}, nil
+	}
+	router := buildRouter(noopRepo(), cache, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris?omit_empty=false", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Alerts []destination.WeatherAlert `json:"alerts"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, []destination.WeatherAlert{alert}, body.Alerts)
 }
 
-// ---- Auth middleware ----
+func TestGetDestination_Envelope(t *testing.T) {
+	fetchedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
 
-func TestBearerAuth_NoHeader(t *testing.T) {
-	router := buildRouter(nil, nil, nil, nil, nil)
-	req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris", nil)
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	tests := []struct {
+		name       string
+		cacheHit   bool
+		wantCached bool
+		wantFetch  *time.Time
+	}{
+		{name: "cache hit", cacheHit: true, wantCached: true},
+		{name: "db hit", cacheHit: false, wantCached: false, wantFetch: &fetchedAt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := noopCache()
+			if tt.cacheHit {
+				cache.getFn = func(_ context.Context, _ string) (*destination.CachedData, error) {
+					return &destination.CachedData{Data: sampleData()}, nil
+				}
+			}
+			repo := noopRepo()
+			repo.getDestinationFn = func(_ context.Context, _ string) (*destination.Destination, error) {
+				dest := sampleDest()
+				dest.FetchedAt = &fetchedAt
+				return dest, nil
+			}
+
+			router := buildRouter(repo, cache, nil, nil, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris?envelope=true", nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var body struct {
+				Data destination.DestinationData `json:"data"`
+				Meta struct {
+					RequestID string     `json:"request_id"`
+					Cached    bool       `json:"cached"`
+					FetchedAt *time.Time `json:"fetched_at"`
+				} `json:"meta"`
+			}
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			require.NotNil(t, body.Data.Weather)
+			assert.Equal(t, 22.5, body.Data.Weather.Temperature)
+			assert.NotEmpty(t, body.Meta.RequestID)
+			assert.Equal(t, tt.wantCached, body.Meta.Cached)
+			assert.Equal(t, tt.wantFetch, body.Meta.FetchedAt)
+		})
+	}
 }
 
-func TestBearerAuth_WrongToken(t *testing.T) {
-	router := buildRouter(nil, nil, nil, nil, nil)
-	req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris", nil)
-	req.Header.Set("Authorization", "Bearer wrong-token")
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusUnauthorized, w.Code)
+func TestGetDestination_AcceptVersion(t *testing.T) {
+	repo := noopRepo()
+	repo.getDestinationFn = func(_ context.Context, _ string) (*destination.Destination, error) {
+		return sampleDest(), nil
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handlers := api.NewHandlers(repo, noopCache(), nil, log)
+
+	tests := []struct {
+		name         string
+		header       string
+		opts         []api.RouterOption
+		wantEnvelope bool
+	}{
+		{name: "no header is v1", wantEnvelope: false},
+		{name: "v1", header: "1", wantEnvelope: false},
+		{name: "v2", header: "2", wantEnvelope: true},
+		{name: "v2 with prefix", header: "v2", wantEnvelope: true},
+		{name: "default v2", opts: []api.RouterOption{api.WithDefaultAPIVersion(api.APIVersion2)}, wantEnvelope: true},
+		{name: "v1 over default v2", header: "1", opts: []api.RouterOption{api.WithDefaultAPIVersion(api.APIVersion2)}, wantEnvelope: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := api.NewRouter(handlers, testToken, &mockPinger{}, &mockPinger{}, log, tt.opts...)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris", nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			if tt.header != "" {
+				req.Header.Set("Accept-Version", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Header().Values("Vary"), "Accept-Version")
+			var body map[string]json.RawMessage
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			if tt.wantEnvelope {
+				assert.Contains(t, body, "meta")
+				var data destination.DestinationData
+				require.NoError(t, json.Unmarshal(body["data"], &data))
+				require.NotNil(t, data.Weather)
+				assert.Equal(t, 22.5, data.Weather.Temperature)
+			} else {
+				assert.NotContains(t, body, "meta")
+				assert.Contains(t, body, "weather")
+			}
+		})
+	}
 }
 
-func TestBearerAuth_HealthNoAuth(t *testing.T) {
-	// Health endpoint must not require auth.
-	router := buildRouter(nil, nil, nil, &mockPinger{}, &mockPinger{})
-	req := httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusOK, w.Code)
+func TestAcceptVersion_Unsupported(t *testing.T) {
+	router := buildRouter(noopRepo(), noopCache(), nil, nil, nil)
+
+	for _, v := range []string{"0", "3", "latest"} {
+		t.Run(v, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris", nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			req.Header.Set("Accept-Version", v)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), "Accept-Version")
+		})
+	}
 }
 
-func TestBearerAuth_MissingBearerPrefix(t *testing.T) {
-	router := buildRouter(nil, nil, nil, nil, nil)
+func TestTimestampFormat(t *testing.T) {
+	fetchedAt := time.Date(2026, 3, 1, 12, 0, 0, 123456789, time.UTC)
+	created := time.Date(2026, 1, 1, 0, 0, 0, 500, time.UTC)
+	repo := noopRepo()
+	repo.getDestinationFn = func(_ context.Context, _ string) (*destination.Destination, error) {
+		dest := sampleDest()
+		dest.FetchedAt = &fetchedAt
+		dest.CreatedAt = created
+		dest.UpdatedAt = fetchedAt
+		return dest, nil
+	}
+
+	tests := []struct {
+		name       string
+		opts       []api.HandlerOption
+		wantMeta   any
+		wantRecord map[string]any
+	}{
+		{
+			name:     "rfc3339 by default",
+			wantMeta: "2026-03-01T12:00:00Z",
+			wantRecord: map[string]any{
+				"fetched_at": "2026-03-01T12:00:00Z", "created_at": "2026-01-01T00:00:00Z", "updated_at": "2026-03-01T12:00:00Z",
+			},
+		},
+		{
+			name:     "unix",
+			opts:     []api.HandlerOption{api.WithTimestampFormat(api.TimestampUnix)},
+			wantMeta: float64(1772366400),
+			wantRecord: map[string]any{
+				"fetched_at": float64(1772366400), "created_at": float64(1767225600), "updated_at": float64(1772366400),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := buildRouter(repo, noopCache(), nil, nil, nil, tt.opts...)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris?envelope=true", nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			var env map[string]map[string]any
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&env))
+			assert.Equal(t, tt.wantMeta, env["meta"]["fetched_at"])
+
+			admin := buildAdminRouter(repo, noopCache(), nil, tt.opts...)
+			req = httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris/full", nil)
+			req.Header.Set("Authorization", "Bearer "+testAdminToken)
+			w = httptest.NewRecorder()
+			admin.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			var record map[string]any
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&record))
+			for key, want := range tt.wantRecord {
+				assert.Equal(t, want, record[key], key)
+			}
+		})
+	}
+}
+
+func TestGetDestination_NoEnvelopeByDefault(t *testing.T) {
+	cache := noopCache()
+	cache.getFn = func(_ context.Context, _ string) (*destination.CachedData, error) {
+		return &destination.CachedData{Data: sampleData()}, nil
+	}
+
+	router := buildRouter(noopRepo(), cache, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var body map[string]any
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Contains(t, body, "weather")
+	assert.NotContains(t, body, "meta")
+}
+
+func TestRefreshDestination_Envelope(t *testing.T) {
+	router := buildRouter(noopRepo(), noopCache(), &mockFetcher{
+		fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) { return sampleResult(), nil },
+	}, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh?envelope=true", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body map[string]map[string]any
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Contains(t, body["data"], "weather")
+	assert.Equal(t, false, body["meta"]["cached"])
+	assert.NotEmpty(t, body["meta"]["fetched_at"])
+}
+
+// ---- POST /api/v1/destinations/{city}/refresh ----
+
+func TestRefreshDestination_Success(t *testing.T) {
+	upsertCalled := false
+	var storedAt, cachedAt time.Time
+	repo := &mockRepo{
+		getDestinationFn: func(_ context.Context, _ string) (*destination.Destination, error) { return sampleDest(), nil },
+		upsertFn: func(_ context.Context, _, _ string, _ destination.DestinationData, fetchedAt time.Time) (bool, error) {
+			upsertCalled = true
+			storedAt = fetchedAt
+			return true, nil
+		},
+	}
+	cache := &mockCache{
+		getFn: func(_ context.Context, _ string) (*destination.CachedData, error) { return nil, nil },
+		setFn: func(_ context.Context, _ string, _ *destination.DestinationData, fetchedAt time.Time) error {
+			cachedAt = fetchedAt
+			return nil
+		},
+		deleteFn: func(_ context.Context, _ string) error { return nil },
+	}
+	fetcher := &mockFetcher{
+		fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) { return sampleResult(), nil },
+	}
+
+	router := buildRouter(repo, cache, fetcher, nil, nil)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh?country=France", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, upsertCalled)
+	assert.False(t, storedAt.IsZero())
+	assert.Equal(t, storedAt, cachedAt, "the stored and cached fetch times must match")
+}
+
+func TestRefreshDestination_ReturnsStored(t *testing.T) {
+	// The stored row differs from what was fetched, as after an upsert that merges
+	// into the existing data: it kept a POI the fetch did not return.
+	merged := &destination.Destination{
+		City:    "Paris",
+		Country: "France",
+		Data: destination.DestinationData{
+			Weather:     &destination.WeatherData{Temperature: 22.5, Description: "clear sky"},
+			PointsOfInt: []destination.POI{{Name: "Louvre", Rate: 7}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		opts     []api.HandlerOption
+		getFn    func(context.Context, string) (*destination.Destination, error)
+		wantPOIs []destination.POI
+	}{
+		{name: "fetched by default", wantPOIs: nil},
+		{
+			name:     "stored",
+			opts:     []api.HandlerOption{api.WithRefreshReturnsStored(true)},
+			getFn:    func(_ context.Context, _ string) (*destination.Destination, error) { return merged, nil },
+			wantPOIs: merged.Data.PointsOfInt,
+		},
+		{
+			name:     "stored re-read fails",
+			opts:     []api.HandlerOption{api.WithRefreshReturnsStored(true)},
+			getFn:    func(_ context.Context, _ string) (*destination.Destination, error) { return nil, fmt.Errorf("db down") },
+			wantPOIs: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reads := 0
+			repo := noopRepo()
+			repo.getDestinationFn = func(ctx context.Context, city string) (*destination.Destination, error) {
+				reads++
+				return tt.getFn(ctx, city)
+			}
+			var cached *destination.DestinationData
+			cache := noopCache()
+			cache.setFn = func(_ context.Context, _ string, data *destination.DestinationData, _ time.Time) error {
+				cached = data
+				return nil
+			}
+			fetcher := &mockFetcher{
+				fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) { return sampleResult(), nil },
+			}
+
+			router := buildRouter(repo, cache, fetcher, nil, nil, tt.opts...)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh", nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			var body destination.DestinationData
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			assert.Equal(t, "clear sky", body.Weather.Description)
+			assert.Equal(t, tt.wantPOIs, body.PointsOfInt)
+			require.NotNil(t, cached)
+			assert.Equal(t, tt.wantPOIs, cached.PointsOfInt, "the cache holds what the response returned")
+			if tt.getFn == nil {
+				assert.Zero(t, reads, "the stored record is not re-read by default")
+			}
+		})
+	}
+}
+
+func TestRefreshDestination_DebugTimings(t *testing.T) {
+	fetcher := &mockFetcher{
+		fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) {
+			return &destination.FetchResult{
+				Data: sampleData(),
+				Timings: map[string]time.Duration{
+					destination.ProviderWeather: 120 * time.Millisecond,
+					destination.ProviderPOI:     45 * time.Millisecond,
+				},
+			}, nil
+		},
+	}
+
+	router := buildRouter(noopRepo(), noopCache(), fetcher, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh?debug=true", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Weather *destination.WeatherData `json:"weather"`
+		Timings map[string]int64         `json:"timings"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	require.NotNil(t, body.Weather)
+	assert.Equal(t, 22.5, body.Weather.Temperature)
+	assert.Equal(t, int64(120), body.Timings[destination.ProviderWeather])
+	assert.Equal(t, int64(45), body.Timings[destination.ProviderPOI])
+}
+
+func TestRefreshDestination_NoTimingsWithoutDebug(t *testing.T) {
+	fetcher := &mockFetcher{
+		fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) {
+			return &destination.FetchResult{
+				Data:    sampleData(),
+				Timings: map[string]time.Duration{destination.ProviderWeather: time.Millisecond},
+			}, nil
+		},
+	}
+
+	router := buildRouter(noopRepo(), noopCache(), fetcher, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var body map[string]any
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.NotContains(t, body, "timings")
+}
+
+func TestRefreshDestination_NilFetchResult(t *testing.T) {
+	tests := []struct {
+		name string
+		res  *destination.FetchResult
+	}{
+		{name: "nil result", res: nil},
+		{name: "nil data", res: &destination.FetchResult{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := noopRepo()
+			repo.upsertFn = func(context.Context, string, string, destination.DestinationData, time.Time) (bool, error) {
+				t.Fatal("nothing must be stored")
+				return false, nil
+			}
+			fetcher := &mockFetcher{
+				fetchAllFn: func(context.Context, string, string) (*destination.FetchResult, error) {
+					return tt.res, nil
+				},
+			}
+
+			router := buildRouter(repo, noopCache(), fetcher, nil, nil)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh", nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusInternalServerError, w.Code)
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			assert.Equal(t, "fetcher returned no data", body["error"])
+		})
+	}
+}
+
+func TestRefreshDestination_Status(t *testing.T) {
+	tests := []struct {
+		name   string
+		errs   map[string]error
+		status string
+	}{
+		{name: "all providers succeed", status: "complete"},
+		{name: "one provider fails", errs: map[string]error{destination.ProviderTeleport: fmt.Errorf("teleport down")}, status: "partial"},
+		{name: "all providers fail", errs: map[string]error{
+			destination.ProviderWeather:  fmt.Errorf("down"),
+			destination.ProviderPOI:      fmt.Errorf("down"),
+			destination.ProviderCountry:  fmt.Errorf("down"),
+			destination.ProviderTeleport: fmt.Errorf("down"),
+		}, status: "failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := &mockFetcher{
+				fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) {
+					res := sampleResult()
+					res.Errors = tt.errs
+					return res, nil
+				},
+			}
+
+			router := buildRouter(noopRepo(), noopCache(), fetcher, nil, nil)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh", nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			assert.Equal(t, tt.status, body["status"])
+			assert.Contains(t, body, "weather", "data stays at the top level")
+		})
+	}
+}
+
+func TestRefreshDestination_ReturnMinimal(t *testing.T) {
+	var stored *destination.DestinationData
+	repo := noopRepo()
+	repo.upsertFn = func(_ context.Context, _, _ string, data destination.DestinationData, _ time.Time) (bool, error) {
+		stored = &data
+		return true, nil
+	}
+	fetcher := &mockFetcher{
+		fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) {
+			res := sampleResult()
+			res.Errors = map[string]error{destination.ProviderTeleport: fmt.Errorf("teleport down")}
+			res.Statuses = map[string]string{destination.ProviderPOI: destination.SourceEmpty}
+			return res, nil
+		},
+	}
+
+	router := buildRouter(repo, noopCache(), fetcher, nil, nil)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh?return=minimal", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var body map[string]any
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, map[string]any{
+		"city":      "Paris",
+		"refreshed": true,
+		"status":    "partial",
+		"sources": map[string]any{
+			"weather":  "ok",
+			"poi":      "empty",
+			"country":  "ok",
+			"teleport": "error",
+		},
+	}, body)
+
+	require.NotNil(t, stored, "data must still be persisted")
+	require.NotNil(t, stored.Weather)
+	assert.Equal(t, 22.5, stored.Weather.Temperature)
+}
+
+func TestRefreshDestination_MinSuccessfulProviders(t *testing.T) {
+	// Two of the four providers fail in every case below.
+	failed := map[string]error{
+		destination.ProviderCountry:  fmt.Errorf("restcountries down"),
+		destination.ProviderTeleport: fmt.Errorf("teleport down"),
+	}
+
+	tests := []struct {
+		name       string
+		threshold  int
+		wantStatus int
+		wantUpsert bool
+	}{
+		{name: "below threshold", threshold: 3, wantStatus: http.StatusBadGateway, wantUpsert: false},
+		{name: "at threshold", threshold: 2, wantStatus: http.StatusOK, wantUpsert: true},
+		{name: "above threshold", threshold: 1, wantStatus: http.StatusOK, wantUpsert: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upsertCalled := false
+			repo := noopRepo()
+			repo.upsertFn = func(_ context.Context, _, _ string, _ destination.DestinationData, _ time.Time) (bool, error) {
+				upsertCalled = true
+				return true, nil
+			}
+			fetcher := &mockFetcher{
+				fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) {
+					return &destination.FetchResult{Data: sampleData(), Errors: failed}, nil
+				},
+			}
+
+			router := buildRouter(repo, noopCache(), fetcher, nil, nil, api.WithMinSuccessfulProviders(tt.threshold))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh", nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantUpsert, upsertCalled)
+		})
+	}
+}
+
+func TestRefreshDestination_FetchError(t *testing.T) {
+	repo := &mockRepo{
+		getDestinationFn: func(_ context.Context, _ string) (*destination.Destination, error) { return nil, nil },
+		upsertFn: func(_ context.Context, _, _ string, _ destination.DestinationData, _ time.Time) (bool, error) {
+			return true, nil
+		},
+	}
+	cache := &mockCache{
+		getFn:    func(_ context.Context, _ string) (*destination.CachedData, error) { return nil, nil },
+		setFn:    func(_ context.Context, _ string, _ *destination.DestinationData, _ time.Time) error { return nil },
+		deleteFn: func(_ context.Context, _ string) error { return nil },
+	}
+	fetcher := &mockFetcher{
+		fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) {
+			return nil, fmt.Errorf("all APIs down")
+		},
+	}
+
+	router := buildRouter(repo, cache, fetcher, nil, nil)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestRefreshDestination_ClientCanceled(t *testing.T) {
+	upsertCalled := false
+	repo := &mockRepo{
+		upsertFn: func(_ context.Context, _, _ string, _ destination.DestinationData, _ time.Time) (bool, error) {
+			upsertCalled = true
+			return true, nil
+		},
+	}
+	fetcher := &mockFetcher{
+		fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) {
+			res := sampleResult()
+			res.Errors = map[string]error{
+				destination.ProviderWeather: fmt.Errorf("openweathermap fetch: %w", destination.ErrFetchCanceled),
+			}
+			return res, nil
+		},
+	}
+
+	router := buildRouter(repo, noopCache(), fetcher, nil, nil)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 499, w.Code)
+	assert.False(t, upsertCalled, "canceled refresh must not store partial data")
+}
+
+func TestRefreshDestination_ProviderTimeoutIsNotCancellation(t *testing.T) {
+	fetcher := &mockFetcher{
+		fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) {
+			res := sampleResult()
+			res.Errors = map[string]error{
+				destination.ProviderWeather: fmt.Errorf("openweathermap fetch: %w", destination.ErrFetchTimeout),
+			}
+			return res, nil
+		},
+	}
+
+	router := buildRouter(noopRepo(), noopCache(), fetcher, nil, nil)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRefreshDestination_UpsertError(t *testing.T) {
+	repo := &mockRepo{
+		getDestinationFn: func(_ context.Context, _ string) (*destination.Destination, error) { return nil, nil },
+		upsertFn: func(_ context.Context, _, _ string, _ destination.DestinationData, _ time.Time) (bool, error) {
+			return false, fmt.Errorf("db error")
+		},
+	}
+	cache := &mockCache{
+		getFn:    func(_ context.Context, _ string) (*destination.CachedData, error) { return nil, nil },
+		setFn:    func(_ context.Context, _ string, _ *destination.DestinationData, _ time.Time) error { return nil },
+		deleteFn: func(_ context.Context, _ string) error { return nil },
+	}
+	fetcher := &mockFetcher{
+		fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) { return sampleResult(), nil },
+	}
+
+	router := buildRouter(repo, cache, fetcher, nil, nil)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestRefreshDestination_ReusesFreshProviders(t *testing.T) {
+	now := time.Now().UTC()
+	stored := &destination.Destination{
+		City:    "Paris",
+		Country: "France",
+		Data: destination.DestinationData{
+			Weather:       &destination.WeatherData{Temperature: 5, Description: "old"},
+			PointsOfInt:   []destination.POI{{Name: "Louvre"}},
+			Country:       &destination.CountryData{Region: "Europe", Capital: "Paris"},
+			QualityScores: []destination.QualityScore{{Name: "Safety", ScoreOutOf: 6}},
+			ExchangeRates: map[string]float64{"EUR": 0.9},
+			SourcesFetchedAt: map[string]time.Time{
+				destination.ProviderWeather:  now.Add(-2 * time.Hour),
+				destination.ProviderPOI:      now.Add(-time.Hour),
+				destination.ProviderCountry:  now.Add(-time.Hour),
+				destination.ProviderTeleport: now.Add(-time.Hour),
+			},
+		},
+	}
+	freshness := api.WithProviderFreshness(map[string]time.Duration{
+		destination.ProviderWeather:  time.Hour,
+		destination.ProviderPOI:      24 * time.Hour,
+		destination.ProviderCountry:  24 * time.Hour,
+		destination.ProviderTeleport: 24 * time.Hour,
+	})
+
+	var gotProviders []string
+	var gotCountry string
+	var upserted destination.DestinationData
+	repo := noopRepo()
+	repo.getDestinationFn = func(_ context.Context, _ string) (*destination.Destination, error) { return stored, nil }
+	repo.upsertFn = func(_ context.Context, _, _ string, data destination.DestinationData, _ time.Time) (bool, error) {
+		upserted = data
+		return false, nil
+	}
+	fetcher := &mockFetcher{
+		fetchProvidersFn: func(_ context.Context, _, country string, providers []string) (*destination.FetchResult, error) {
+			gotProviders = providers
+			gotCountry = country
+			return &destination.FetchResult{
+				Data: &destination.DestinationData{Weather: &destination.WeatherData{Temperature: 22.5, Description: "clear sky"}},
+				Statuses: map[string]string{
+					destination.ProviderWeather:  destination.SourceOK,
+					destination.ProviderPOI:      destination.SourceSkipped,
+					destination.ProviderCountry:  destination.SourceSkipped,
+					destination.ProviderTeleport: destination.SourceSkipped,
+				},
+			}, nil
+		},
+	}
+
+	router := buildRouter(repo, noopCache(), fetcher, nil, nil, freshness)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh?return=minimal", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{destination.ProviderWeather}, gotProviders, "only the stale provider is fetched")
+	assert.Equal(t, "France", gotCountry, "the stored country is used when none is given")
+
+	assert.Equal(t, 22.5, upserted.Weather.Temperature)
+	assert.Equal(t, stored.Data.PointsOfInt, upserted.PointsOfInt)
+	assert.Equal(t, stored.Data.Country, upserted.Country)
+	assert.Equal(t, stored.Data.QualityScores, upserted.QualityScores)
+	assert.Equal(t, stored.Data.ExchangeRates, upserted.ExchangeRates)
+	assert.True(t, upserted.SourcesFetchedAt[destination.ProviderWeather].After(now.Add(-time.Minute)))
+	assert.Equal(t, stored.Data.SourcesFetchedAt[destination.ProviderPOI], upserted.SourcesFetchedAt[destination.ProviderPOI])
+
+	var body struct {
+		Sources map[string]string `json:"sources"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, destination.SourceOK, body.Sources[destination.ProviderWeather])
+	assert.Equal(t, destination.SourceSkipped, body.Sources[destination.ProviderPOI])
+}
+
+func TestRefreshDestination_AllProvidersFresh(t *testing.T) {
+	now := time.Now().UTC()
+	fresh := map[string]time.Time{}
+	for _, p := range destination.Providers() {
+		fresh[p] = now.Add(-time.Minute)
+	}
+	stored := sampleDest()
+	stored.Data.SourcesFetchedAt = fresh
+
+	repo := noopRepo()
+	repo.getDestinationFn = func(_ context.Context, _ string) (*destination.Destination, error) { return stored, nil }
+	repo.upsertFn = func(_ context.Context, _, _ string, _ destination.DestinationData, _ time.Time) (bool, error) {
+		t.Error("nothing should be stored when every section is fresh")
+		return false, nil
+	}
+	fetcher := &mockFetcher{} // any call panics
+	router := buildRouter(repo, noopCache(), fetcher, nil, nil, api.WithProviderFreshness(map[string]time.Duration{
+		destination.ProviderWeather:  time.Hour,
+		destination.ProviderPOI:      time.Hour,
+		destination.ProviderCountry:  time.Hour,
+		destination.ProviderTeleport: time.Hour,
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var got destination.DestinationData
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+	assert.Equal(t, 22.5, got.Weather.Temperature)
+}
+
+func TestRefreshDestination_FreshnessFetchesAllForNewCity(t *testing.T) {
+	var fetchedAll bool
+	repo := noopRepo()
+	repo.getDestinationFn = func(_ context.Context, _ string) (*destination.Destination, error) { return nil, nil }
+	fetcher := &mockFetcher{
+		fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) {
+			fetchedAll = true
+			return sampleResult(), nil
+		},
+	}
+	router := buildRouter(repo, noopCache(), fetcher, nil, nil, api.WithProviderFreshness(map[string]time.Duration{
+		destination.ProviderCountry: time.Hour,
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, fetchedAll)
+}
+
+// ---- GET /api/v1/destinations/fts ----
+
+func TestSearchDestinations(t *testing.T) {
+	var gotQuery string
+	repo := noopRepo()
+	repo.searchFn = func(_ context.Context, q string, _ destination.Page) ([]*destination.Destination, error) {
+		gotQuery = q
+		return []*destination.Destination{sampleDest()}, nil
+	}
+
+	router := buildRouter(repo, noopCache(), nil, nil, nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/fts?q=paris+france", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "paris france", gotQuery)
+	assert.Contains(t, w.Header().Values("Vary"), "Accept-Language")
+
+	var body struct {
+		Results []struct {
+			City    string                      `json:"city"`
+			Country string                      `json:"country"`
+			Data    destination.DestinationData `json:"data"`
+		} `json:"results"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	require.Len(t, body.Results, 1)
+	assert.Equal(t, "Paris", body.Results[0].City)
+	assert.Equal(t, "France", body.Results[0].Country)
+	require.NotNil(t, body.Results[0].Data.Weather)
+}
+
+// ---- GET /api/v1/destinations?min_quality=... ----
+
+func TestFilterByQuality(t *testing.T) {
+	tests := []struct {
+		query string
+		want  destination.QualityFilter
+	}{
+		{query: "Safety:6", want: destination.QualityFilter{Category: "Safety", Min: 6}},
+		{query: "Cost+of+Living:+7.5", want: destination.QualityFilter{Category: "Cost of Living", Min: 7.5}},
+		{query: "safety:0", want: destination.QualityFilter{Category: "safety", Min: 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var got destination.QualityFilter
+			repo := noopRepo()
+			repo.minQualityFn = func(_ context.Context, f destination.QualityFilter, page destination.Page) ([]*destination.Destination, error) {
+				got = f
+				assert.Equal(t, destination.Page{Limit: 50}, page)
+				return []*destination.Destination{sampleDest()}, nil
+			}
+			router := buildRouter(repo, noopCache(), nil, &mockPinger{}, &mockPinger{})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations?min_quality="+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.want, got)
+			var body struct {
+				Results []struct {
+					City string `json:"city"`
+				} `json:"results"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			require.Len(t, body.Results, 1)
+			assert.Equal(t, sampleDest().City, body.Results[0].City)
+		})
+	}
+}
+
+func TestFilterByQuality_RejectsBadFormat(t *testing.T) {
+	repo := noopRepo()
+	repo.minQualityFn = func(_ context.Context, _ destination.QualityFilter, _ destination.Page) ([]*destination.Destination, error) {
+		t.Fatal("repository must not be called")
+		return nil, nil
+	}
+	router := buildRouter(repo, noopCache(), nil, &mockPinger{}, &mockPinger{})
+
+	for _, query := range []string{"", "?min_quality=Safety", "?min_quality=:6", "?min_quality=Safety:high", "?min_quality=Safety:11", "?min_quality=Safety:-1", "?min_quality=Safety:NaN", "?min_quality=Safety:6&limit=-1"} {
+		t.Run(query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations"+query, nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestFilterByQuality_DBError(t *testing.T) {
+	repo := noopRepo()
+	repo.minQualityFn = func(_ context.Context, _ destination.QualityFilter, _ destination.Page) ([]*destination.Destination, error) {
+		return nil, fmt.Errorf("db down")
+	}
+	router := buildRouter(repo, noopCache(), nil, &mockPinger{}, &mockPinger{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations?min_quality=Safety:6", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestFilterByQuality_ETag(t *testing.T) {
+	version := destination.CollectionVersion{UpdatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), Count: 3}
+	queries := 0
+	repo := noopRepo()
+	repo.versionFn = func(_ context.Context) (destination.CollectionVersion, error) { return version, nil }
+	repo.minQualityFn = func(_ context.Context, _ destination.QualityFilter, _ destination.Page) ([]*destination.Destination, error) {
+		queries++
+		return []*destination.Destination{sampleDest()}, nil
+	}
+	router := buildRouter(repo, noopCache(), nil, &mockPinger{}, &mockPinger{})
+
+	get := func(query, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations?"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+testToken)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := get("min_quality=Safety:6", "")
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Equal(t, 1, queries)
+
+	second := get("min_quality=Safety:6", etag)
+	assert.Equal(t, http.StatusNotModified, second.Code)
+	assert.Equal(t, etag, second.Header().Get("ETag"))
+	assert.Empty(t, second.Body.String())
+	assert.Equal(t, 1, queries, "a 304 must not run the list query")
+
+	assert.Equal(t, http.StatusNotModified, get("min_quality=Safety:6", `"other", `+etag).Code)
+	assert.Equal(t, http.StatusOK, get("min_quality=Safety:7", etag).Code, "a different query has a different tag")
+
+	version.Count = 2
+	third := get("min_quality=Safety:6", etag)
+	require.Equal(t, http.StatusOK, third.Code)
+	assert.NotEqual(t, etag, third.Header().Get("ETag"))
+}
+
+func TestFilterByQuality_ETagVersionError(t *testing.T) {
+	repo := noopRepo()
+	repo.versionFn = func(_ context.Context) (destination.CollectionVersion, error) {
+		return destination.CollectionVersion{}, fmt.Errorf("db down")
+	}
+	router := buildRouter(repo, noopCache(), nil, &mockPinger{}, &mockPinger{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations?min_quality=Safety:6", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	req.Header.Set("If-None-Match", "*")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("ETag"))
+}
+
+func TestListEndpoints_Pagination(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		want   destination.Page
+	}{
+		{name: "defaults", query: "", status: http.StatusOK, want: destination.Page{Limit: 10}},
+		{name: "zero limit defaults", query: "limit=0", status: http.StatusOK, want: destination.Page{Limit: 10}},
+		{name: "explicit", query: "limit=5&offset=15", status: http.StatusOK, want: destination.Page{Limit: 5, Offset: 15}},
+		{name: "clamped to max", query: "limit=1000", status: http.StatusOK, want: destination.Page{Limit: 25}},
+		{name: "negative limit", query: "limit=-1", status: http.StatusBadRequest},
+		{name: "negative offset", query: "offset=-5", status: http.StatusBadRequest},
+		{name: "non-integer limit", query: "limit=ten", status: http.StatusBadRequest},
+	}
+
+	endpoints := []struct {
+		path  string
+		token string
+	}{
+		{path: "/api/v1/destinations/fts?q=paris&", token: testToken},
+		{path: "/api/v1/admin/repair?", token: testAdminToken},
+	}
+
+	for _, ep := range endpoints {
+		for _, tt := range tests {
+			t.Run(ep.path+tt.name, func(t *testing.T) {
+				var got *destination.Page
+				repo := noopRepo()
+				repo.searchFn = func(_ context.Context, _ string, page destination.Page) ([]*destination.Destination, error) {
+					got = &page
+					return nil, nil
+				}
+				repo.findIncompleteFn = func(_ context.Context, page destination.Page) ([]destination.IncompleteDestination, error) {
+					got = &page
+					return nil, nil
+				}
+				handlers := api.NewHandlers(repo, noopCache(), nil, slog.Default(),
+					api.WithPageSizes(api.PageSizes{Default: 10, Max: 25}))
+				router := api.NewRouter(handlers, testToken, &mockPinger{}, &mockPinger{}, slog.Default(), api.WithAdminToken(testAdminToken))
+
+				req := httptest.NewRequest(http.MethodGet, ep.path+tt.query, nil)
+				req.Header.Set("Authorization", "Bearer "+ep.token)
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				require.Equal(t, tt.status, w.Code)
+				if tt.status != http.StatusOK {
+					assert.Nil(t, got, "repo must not be queried")
+					return
+				}
+				require.NotNil(t, got)
+				assert.Equal(t, tt.want, *got)
+			})
+		}
+	}
+}
+
+func TestWithPageSizes_DefaultNeverExceedsMax(t *testing.T) {
+	var got destination.Page
+	repo := noopRepo()
+	repo.searchFn = func(_ context.Context, _ string, page destination.Page) ([]*destination.Destination, error) {
+		got = page
+		return nil, nil
+	}
+	handlers := api.NewHandlers(repo, noopCache(), nil, slog.Default(), api.WithPageSizes(api.PageSizes{Max: 20}))
+	router := api.NewRouter(handlers, testToken, &mockPinger{}, &mockPinger{}, slog.Default())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/fts?q=paris", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, destination.Page{Limit: 20}, got)
+}
+
+func TestSearchDestinations_NoMatchesIsEmptyArray(t *testing.T) {
+	router := buildRouter(noopRepo(), noopCache(), nil, nil, nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/fts?q=atlantis", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"results":[]}`, w.Body.String())
+}
+
+func TestSearchDestinations_Errors(t *testing.T) {
+	repo := noopRepo()
+	repo.searchFn = func(_ context.Context, _ string, _ destination.Page) ([]*destination.Destination, error) {
+		return nil, fmt.Errorf("db down")
+	}
+	router := buildRouter(repo, noopCache(), nil, nil, nil)
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{name: "missing q", query: "", status: http.StatusBadRequest},
+		{name: "blank q", query: "?q=+++", status: http.StatusBadRequest},
+		{name: "db error", query: "?q=paris", status: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/fts"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+// ---- rate limiting ----
+
+func rateLimited(perMinute, burst, maxClients int) http.Handler {
+	return api.RateLimitByIP(perMinute, burst, maxClients)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func hit(h http.Handler, ip string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = ip + ":1234"
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitByIP_ToleratesBurst(t *testing.T) {
+	h := rateLimited(60, 5, 100)
+
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusOK, hit(h, "10.0.0.1").Code, "request %d within burst", i+1)
+	}
+
+	w := hit(h, "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, hit(h, "10.0.0.2").Code, "other IPs have their own bucket")
+}
+
+func TestRateLimitByIP_RejectsSustainedRate(t *testing.T) {
+	// 1200/min refills one token every 50ms.
+	h := rateLimited(1200, 1, 100)
+
+	assert.Equal(t, http.StatusOK, hit(h, "10.0.0.1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, hit(h, "10.0.0.1").Code, "no tokens left before refill")
+
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, http.StatusOK, hit(h, "10.0.0.1").Code, "token refilled at the sustained rate")
+	assert.Equal(t, http.StatusTooManyRequests, hit(h, "10.0.0.1").Code)
+}
+
+func TestRateLimitByIP_EvictsLeastRecentlyUsed(t *testing.T) {
+	h := rateLimited(60, 1, 2)
+
+	assert.Equal(t, http.StatusOK, hit(h, "10.0.0.1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, hit(h, "10.0.0.1").Code)
+
+	// Two newer clients push 10.0.0.1 out of the bounded set.
+	assert.Equal(t, http.StatusOK, hit(h, "10.0.0.2").Code)
+	assert.Equal(t, http.StatusOK, hit(h, "10.0.0.3").Code)
+
+	assert.Equal(t, http.StatusOK, hit(h, "10.0.0.1").Code, "evicted client starts with a fresh bucket")
+}
+
+// failingStore is a RateLimitStore whose backend is unreachable.
+type failingStore struct{}
+
+func (failingStore) Reserve(context.Context, string, int, int) (time.Duration, error) {
+	return 0, fmt.Errorf("connection refused")
+}
+
+func (failingStore) Peek(context.Context, string, int, int) (int, time.Duration, error) {
+	return 0, 0, fmt.Errorf("connection refused")
+}
+
+// countingStore allows the first n requests per key, like a shared bucket would.
+type countingStore struct {
+	n     int
+	calls map[string]int
+}
+
+func (s *countingStore) Reserve(_ context.Context, key string, _, _ int) (time.Duration, error) {
+	s.calls[key]++
+	if s.calls[key] > s.n {
+		return 2 * time.Second, nil
+	}
+	return 0, nil
+}
+
+func (s *countingStore) Peek(_ context.Context, key string, _, _ int) (int, time.Duration, error) {
+	return max(0, s.n-s.calls[key]), time.Duration(s.calls[key]) * time.Second, nil
+}
+
+func sharedRateLimited(store api.RateLimitStore, burst int, fallback bool) http.Handler {
+	return api.SharedRateLimitByIP(store, 60, burst, 100, fallback, slog.Default())(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestSharedRateLimitByIP_UsesStore(t *testing.T) {
+	store := &countingStore{n: 2, calls: map[string]int{}}
+	// Two instances sharing one store share the budget.
+	a, b := sharedRateLimited(store, 2, true), sharedRateLimited(store, 2, true)
+
+	assert.Equal(t, http.StatusOK, hit(a, "10.0.0.1").Code)
+	assert.Equal(t, http.StatusOK, hit(b, "10.0.0.1").Code)
+	w := hit(a, "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+}
+
+func TestSharedRateLimitByIP_FallsBackToMemory(t *testing.T) {
+	h := sharedRateLimited(failingStore{}, 1, true)
+
+	assert.Equal(t, http.StatusOK, hit(h, "10.0.0.1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, hit(h, "10.0.0.1").Code, "in-memory buckets enforce the limit")
+}
+
+func TestSharedRateLimitByIP_FailsOpenWithoutFallback(t *testing.T) {
+	h := sharedRateLimited(failingStore{}, 1, false)
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, hit(h, "10.0.0.1").Code)
+	}
+}
+
+func TestRouter_RateLimitOption(t *testing.T) {
+	handlers := api.NewHandlers(noopRepo(), noopCache(), nil, slog.Default())
+	router := api.NewRouter(handlers, testToken, &mockPinger{}, &mockPinger{}, slog.Default(), api.WithRateLimit(60, 2))
+
+	codes := make([]int, 0, 3)
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		codes = append(codes, w.Code)
+	}
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, codes)
+}
+
+func TestRouter_RateLimitDisabled(t *testing.T) {
+	handlers := api.NewHandlers(noopRepo(), noopCache(), nil, slog.Default())
+	router := api.NewRouter(handlers, testToken, &mockPinger{}, &mockPinger{}, slog.Default(),
+		api.WithRateLimit(60, 20), api.WithRateLimitEnabled(false), api.WithAdminToken(testAdminToken))
+
+	for i := 0; i < 200; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
+		req.RemoteAddr = "203.0.113.7:1234"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, "request %d", i+1)
+		assert.Empty(t, w.Header().Get("Retry-After"))
+	}
+
+	code, _ := inspectRateLimit(t, router, "203.0.113.7")
+	assert.Equal(t, http.StatusNotFound, code, "status endpoint is not mounted without a limiter")
+}
+
+func TestWithRateLimitVar_AppliesChanges(t *testing.T) {
+	limit := api.NewRateLimitVar(60, 1)
+	handlers := api.NewHandlers(noopRepo(), noopCache(), nil, slog.Default())
+	router := api.NewRouter(handlers, testToken, &mockPinger{}, &mockPinger{}, slog.Default(),
+		api.WithRateLimit(600, 100), api.WithRateLimitVar(limit), api.WithAdminToken(testAdminToken))
+
+	assert.NotEqual(t, http.StatusTooManyRequests, hit(router, "10.0.0.1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, hit(router, "10.0.0.1").Code, "the var overrides WithRateLimit")
+
+	limit.Set(60, 3)
+	for i := 0; i < 3; i++ {
+		assert.NotEqual(t, http.StatusTooManyRequests, hit(router, "10.0.0.2").Code, "request %d within the new burst", i+1)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, hit(router, "10.0.0.2").Code)
+	assert.Equal(t, http.StatusTooManyRequests, hit(router, "10.0.0.1").Code, "an existing bucket keeps its tokens")
+
+	code, st := inspectRateLimit(t, router, "203.0.113.7")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 3, st.Burst)
+	assert.Equal(t, 3, st.Remaining)
+}
+
+// ---- GET /api/v1/admin/ratelimit/{ip} ----
+
+type rateLimitStatus struct {
+	IP        string    `json:"ip"`
+	PerMinute int       `json:"per_minute"`
+	Burst     int       `json:"burst"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+func inspectRateLimit(t *testing.T, router http.Handler, ip string) (int, rateLimitStatus) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/ratelimit/"+ip, nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	req.RemoteAddr = "192.0.2.99:1234"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var st rateLimitStatus
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &st))
+	}
+	return w.Code, st
+}
+
+func TestRateLimitStatus_RemainingDecreases(t *testing.T) {
+	handlers := api.NewHandlers(noopRepo(), noopCache(), nil, slog.Default())
+	router := api.NewRouter(handlers, testToken, &mockPinger{}, &mockPinger{}, slog.Default(),
+		api.WithRateLimit(60, 5), api.WithAdminToken(testAdminToken))
+
+	code, st := inspectRateLimit(t, router, "203.0.113.7")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, rateLimitStatus{IP: "203.0.113.7", PerMinute: 60, Burst: 5, Remaining: 5, ResetAt: st.ResetAt}, st, "unseen IP has a full bucket")
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
+		req.RemoteAddr = "203.0.113.7:1234"
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	before := time.Now()
+	code, st = inspectRateLimit(t, router, "203.0.113.7")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 2, st.Remaining)
+	// Three tokens refill at one per second.
+	assert.WithinDuration(t, before.Add(3*time.Second), st.ResetAt, 2*time.Second)
+
+	code, st = inspectRateLimit(t, router, "203.0.113.7")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 2, st.Remaining, "inspecting does not take from the bucket")
+}
+
+func TestRateLimitStatus_SharedStore(t *testing.T) {
+	store := &countingStore{n: 5, calls: map[string]int{}}
+	handlers := api.NewHandlers(noopRepo(), noopCache(), nil, slog.Default())
+	router := api.NewRouter(handlers, testToken, &mockPinger{}, &mockPinger{}, slog.Default(),
+		api.WithRateLimit(60, 5), api.WithSharedRateLimit(store, false), api.WithAdminToken(testAdminToken))
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
+		req.RemoteAddr = "203.0.113.7:1234"
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	code, st := inspectRateLimit(t, router, "203.0.113.7")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 3, st.Remaining)
+}
+
+func TestRateLimitStatus_Errors(t *testing.T) {
+	handlers := api.NewHandlers(noopRepo(), noopCache(), nil, slog.Default())
+
+	down := api.NewRouter(handlers, testToken, &mockPinger{}, &mockPinger{}, slog.Default(),
+		api.WithSharedRateLimit(failingStore{}, false), api.WithAdminToken(testAdminToken))
+	code, _ := inspectRateLimit(t, down, "203.0.113.7")
+	assert.Equal(t, http.StatusServiceUnavailable, code, "no state to report without a fallback")
+
+	fallback := api.NewRouter(handlers, testToken, &mockPinger{}, &mockPinger{}, slog.Default(),
+		api.WithSharedRateLimit(failingStore{}, true), api.WithAdminToken(testAdminToken))
+	code, st := inspectRateLimit(t, fallback, "203.0.113.7")
+	require.Equal(t, http.StatusOK, code, "the in-memory fallback is reported")
+	assert.Equal(t, 20, st.Remaining)
+
+	code, _ = inspectRateLimit(t, fallback, "not-an-ip")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+// ---- GET /api/v1/health ----
+
+func TestHealth_OK(t *testing.T) {
+	router := buildRouter(nil, nil, nil, &mockPinger{}, &mockPinger{})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body map[string]string
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, "ok", body["status"])
+	assert.Equal(t, "ok", body["db"])
+	assert.Equal(t, "ok", body["redis"])
+}
+
+func TestHealth_DBDown(t *testing.T) {
+	router := buildRouter(nil, nil, nil,
+		&mockPinger{err: fmt.Errorf("db unreachable")},
+		&mockPinger{},
+	)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var body map[string]string
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, "error", body["db"])
+}
+
+func TestHealth_RedisDown(t *testing.T) {
+	router := buildRouter(nil, nil, nil,
+		&mockPinger{},
+		&mockPinger{err: fmt.Errorf("redis unreachable")},
+	)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "reads fall back to the DB, so Redis alone is not fatal")
+	var body map[string]string
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, "degraded", body["status"])
+	assert.Equal(t, "ok", body["db"])
+	assert.Equal(t, "error", body["redis"])
+}
+
+func TestHealth_Severities(t *testing.T) {
+	down := &mockPinger{err: fmt.Errorf("unreachable")}
+
+	tests := []struct {
+		name       string
+		severities api.HealthSeverities
+		db, redis  *mockPinger
+		wantCode   int
+		wantStatus string
+	}{
+		{"db down", api.HealthSeverities{}, down, &mockPinger{}, http.StatusServiceUnavailable, "down"},
+		{"both down", api.HealthSeverities{}, down, down, http.StatusServiceUnavailable, "down"},
+		{"redis critical", api.HealthSeverities{Redis: api.SeverityCritical}, &mockPinger{}, down, http.StatusServiceUnavailable, "down"},
+		{"db degraded", api.HealthSeverities{DB: api.SeverityDegraded}, down, &mockPinger{}, http.StatusOK, "degraded"},
+		{"both ok", api.HealthSeverities{}, &mockPinger{}, &mockPinger{}, http.StatusOK, "ok"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlers := api.NewHandlers(noopRepo(), noopCache(), nil, slog.Default())
+			router := api.NewRouter(handlers, testToken, tt.db, tt.redis, slog.Default(), api.WithHealthSeverities(tt.severities))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			assert.Equal(t, tt.wantStatus, body["status"])
+		})
+	}
+}
+
+func TestHealthHandlerFunc_CustomChecks(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	fail := func(context.Context) error { return fmt.Errorf("unreachable") }
+
+	tests := []struct {
+		name       string
+		checks     []api.HealthCheck
+		wantCode   int
+		wantStatus string
+		wantBody   map[string]string
+	}{
+		{
+			name: "all passing",
+			checks: []api.HealthCheck{
+				{Name: "db", Check: ok},
+				{Name: "ratelimit", Check: ok, Severity: api.SeverityDegraded},
+				{Name: "search", Check: ok},
+			},
+			wantCode: http.StatusOK,
+			wantBody: map[string]string{"status": "ok", "db": "ok", "ratelimit": "ok", "search": "ok"},
+		},
+		{
+			name: "degraded check failing",
+			checks: []api.HealthCheck{
+				{Name: "db", Check: ok},
+				{Name: "ratelimit", Check: fail, Severity: api.SeverityDegraded},
+			},
+			wantCode: http.StatusOK,
+			wantBody: map[string]string{"status": "degraded", "db": "ok", "ratelimit": "error"},
+		},
+		{
+			name: "critical by default",
+			checks: []api.HealthCheck{
+				{Name: "ratelimit", Check: fail, Severity: api.SeverityDegraded},
+				{Name: "search", Check: fail},
+			},
+			wantCode: http.StatusServiceUnavailable,
+			wantBody: map[string]string{"status": "down", "ratelimit": "error", "search": "error"},
+		},
+		{
+			name:     "no checks",
+			wantCode: http.StatusOK,
+			wantBody: map[string]string{"status": "ok"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := api.HealthHandlerFunc(tt.checks, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/health", nil))
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			assert.Equal(t, tt.wantBody, body)
+		})
+	}
+}
+
+func TestHealth_WithHealthChecks(t *testing.T) {
+	handlers := api.NewHandlers(noopRepo(), noopCache(), nil, slog.Default())
+	router := api.NewRouter(handlers, testToken, &mockPinger{}, &mockPinger{}, slog.Default(),
+		api.WithHealthChecks(api.HealthCheck{Name: "search", Check: func(context.Context) error { return fmt.Errorf("index missing") }}))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/health", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.JSONEq(t, `{"status":"down","db":"ok","redis":"ok","search":"error"}`, w.Body.String(),
+		"added checks run after the default DB and Redis pings")
+}
+
+// ---- Auth middleware ----
+
+func TestBearerAuth_NoHeader(t *testing.T) {
+	router := buildRouter(nil, nil, nil, nil, nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestBearerAuth_WrongToken(t *testing.T) {
+	router := buildRouter(nil, nil, nil, nil, nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris", nil)
+	req.Header.Set("Authorization", "Bearer wrong-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestBearerAuth_HealthNoAuth(t *testing.T) {
+	// Health endpoint must not require auth.
+	router := buildRouter(nil, nil, nil, &mockPinger{}, &mockPinger{})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestBearerAuth_MissingBearerPrefix(t *testing.T) {
+	router := buildRouter(nil, nil, nil, nil, nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris", nil)
+	req.Header.Set("Authorization", testToken) // no "Bearer " prefix
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+// ---- Trusted proxy real IP ----
+
+func TestTrustedRealIP(t *testing.T) {
+	_, trusted, err := net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+
+	var seen string
+	handler := api.TrustedRealIP([]*net.IPNet{trusted})(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		seen = r.RemoteAddr
+	}))
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		header     string
+		value      string
+		want       string
+	}{
+		{name: "trusted peer uses X-Forwarded-For", remoteAddr: "10.1.2.3:4567", header: "X-Forwarded-For", value: "203.0.113.7, 10.1.2.3", want: "203.0.113.7"},
+		{name: "trusted peer uses X-Real-IP", remoteAddr: "10.1.2.3:4567", header: "X-Real-IP", value: "203.0.113.8", want: "203.0.113.8"},
+		{name: "untrusted peer ignores headers", remoteAddr: "198.51.100.9:5555", header: "X-Forwarded-For", value: "203.0.113.7", want: "198.51.100.9:5555"},
+		{name: "trusted peer without headers", remoteAddr: "10.1.2.3:4567", want: "10.1.2.3:4567"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			assert.Equal(t, tt.want, seen)
+		})
+	}
+}
+
+// ---- Metrics ----
+
+func TestInFlight_GaugeTracksActiveRequest(t *testing.T) {
+	m := metrics.New()
+	entered := make(chan struct{})
+	release := make(chan struct{})
+
+	repo := noopRepo()
+	repo.getDestinationFn = func(_ context.Context, _ string) (*destination.Destination, error) {
+		close(entered)
+		<-release
+		return sampleDest(), nil
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handlers := api.NewHandlers(repo, noopCache(), nil, log)
+	router := api.NewRouter(handlers, testToken, &mockPinger{}, &mockPinger{}, log, api.WithMetrics(m))
+	gauge := m.InFlightRequests.WithLabelValues("/api/v1/destinations/{city}")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		router.ServeHTTP(w, req)
+	}()
+
+	<-entered
+	assert.Equal(t, 1.0, promtestutil.ToFloat64(gauge), "gauge should count the in-flight request")
+
+	close(release)
+	<-done
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 0.0, promtestutil.ToFloat64(gauge), "gauge should drop after the request completes")
+}
+
+func TestInFlight_DecrementsOnPanic(t *testing.T) {
+	m := metrics.New()
+
+	repo := noopRepo()
+	repo.getDestinationFn = func(_ context.Context, _ string) (*destination.Destination, error) {
+		panic("boom")
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handlers := api.NewHandlers(repo, noopCache(), nil, log)
+	router := api.NewRouter(handlers, testToken, &mockPinger{}, &mockPinger{}, log, api.WithMetrics(m))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	gauge := m.InFlightRequests.WithLabelValues("/api/v1/destinations/{city}")
+	assert.Equal(t, 0.0, promtestutil.ToFloat64(gauge))
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	m := metrics.New()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	router := api.NewRouter(api.NewHandlers(nil, nil, nil, log), testToken, &mockPinger{}, &mockPinger{}, log, api.WithMetrics(m))
+
+	// Serve one request so the gauge has a labelled series to export.
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/health", nil))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `http_in_flight_requests{route="/api/v1/health"} 0`)
+}
+
+func TestMetricsEndpoint_ProviderQuota(t *testing.T) {
+	m := metrics.New()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "7")
+		w.Header().Set("X-RateLimit-Limit", "60")
+		_, _ = w.Write([]byte(`{"alerts":[]}`))
+	}))
+	defer srv.Close()
+	alerts := destination.NewAlertsClientWithURL(srv.URL, "key",
+		destination.WithAlertsInstrumentation(destination.Instrumentation{Metrics: m, TrackQuota: true}))
+	_, err := alerts.Fetch(context.Background(), destination.Coordinates{Lat: 48.85, Lon: 2.35})
+	require.NoError(t, err)
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	router := api.NewRouter(api.NewHandlers(nil, nil, nil, log), testToken, &mockPinger{}, &mockPinger{}, log, api.WithMetrics(m))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `provider_quota_remaining{provider="alerts"} 7`)
+	assert.Contains(t, w.Body.String(), `provider_quota_limit{provider="alerts"} 60`)
+}
+
+// ---- Admin ----
+
+func TestListIncomplete(t *testing.T) {
+	repo := noopRepo()
+	repo.findIncompleteFn = func(_ context.Context, _ destination.Page) ([]destination.IncompleteDestination, error) {
+		return []destination.IncompleteDestination{
+			{City: "Paris", Country: "France", Missing: []string{"weather"}},
+		}, nil
+	}
+	router := buildAdminRouter(repo, noopCache(), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/repair", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Incomplete []destination.IncompleteDestination `json:"incomplete"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	require.Len(t, body.Incomplete, 1)
+	assert.Equal(t, "Paris", body.Incomplete[0].City)
+	assert.Equal(t, []string{"weather"}, body.Incomplete[0].Missing)
+}
+
+func TestListIncomplete_EmptyIsArray(t *testing.T) {
+	router := buildAdminRouter(noopRepo(), noopCache(), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/repair", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"incomplete":[]}`, w.Body.String())
+}
+
+func TestListIncomplete_DBError(t *testing.T) {
+	repo := noopRepo()
+	repo.findIncompleteFn = func(_ context.Context, _ destination.Page) ([]destination.IncompleteDestination, error) {
+		return nil, fmt.Errorf("db down")
+	}
+	router := buildAdminRouter(repo, noopCache(), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/repair", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestAdminRoutes_RequireAdminToken(t *testing.T) {
+	router := buildAdminRouter(noopRepo(), noopCache(), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/repair", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "the regular API token must not grant admin access")
+}
+
+func TestAdminRoutes_NotMountedWithoutToken(t *testing.T) {
+	router := buildRouter(noopRepo(), noopCache(), nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/repair", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// ---- GET /api/v1/destinations/{city}/history ----
+
+func TestGetHistory(t *testing.T) {
+	var gotCity string
+	var gotLimit int
+	repo := noopRepo()
+	repo.historyFn = func(_ context.Context, city string, limit int) ([]destination.Snapshot, error) {
+		gotCity, gotLimit = city, limit
+		return []destination.Snapshot{
+			{Country: "France", Data: *sampleData(), FetchedAt: time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC)},
+			{Country: "France", Data: destination.DestinationData{}, FetchedAt: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)},
+		}, nil
+	}
+	router := buildRouter(repo, noopCache(), nil, &mockPinger{}, &mockPinger{}, api.WithHistorySizes(api.PageSizes{Max: 20}))
+
+	tests := []struct {
+		query     string
+		wantLimit int
+	}{
+		{query: "", wantLimit: 10},
+		{query: "?limit=3", wantLimit: 3},
+		{query: "?limit=500", wantLimit: 20},
+	}
+	for _, tt := range tests {
+		t.Run("limit"+tt.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris/history"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "Paris", gotCity)
+			assert.Equal(t, tt.wantLimit, gotLimit)
+
+			var body struct {
+				City    string `json:"city"`
+				History []struct {
+					Country   string                      `json:"country"`
+					Data      destination.DestinationData `json:"data"`
+					FetchedAt string                      `json:"fetched_at"`
+				} `json:"history"`
+			}
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			require.Len(t, body.History, 2)
+			assert.Equal(t, "2024-03-02T09:00:00Z", body.History[0].FetchedAt)
+			assert.Equal(t, "clear sky", body.History[0].Data.Weather.Description)
+			assert.Equal(t, "2024-03-01T09:00:00Z", body.History[1].FetchedAt)
+		})
+	}
+}
+
+func TestGetHistory_EmptyAndErrors(t *testing.T) {
+	router := buildRouter(noopRepo(), noopCache(), nil, &mockPinger{}, &mockPinger{})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris/history", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"city":"Paris","history":[]}`, w.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris/history?limit=-1", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	failing := noopRepo()
+	failing.historyFn = func(_ context.Context, _ string, _ int) ([]destination.Snapshot, error) {
+		return nil, fmt.Errorf("db down")
+	}
+	router = buildRouter(failing, noopCache(), nil, &mockPinger{}, &mockPinger{})
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris/history", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+// ---- GET /api/v1/regions, /api/v1/countries ----
+
+func TestListDistinct(t *testing.T) {
+	repo := noopRepo()
+	repo.regionsFn = func(_ context.Context) ([]destination.NameCount, error) {
+		return []destination.NameCount{{Name: "Europe", Count: 3}, {Name: "Asia", Count: 1}}, nil
+	}
+	repo.countriesFn = func(_ context.Context) ([]destination.NameCount, error) {
+		return []destination.NameCount{{Name: "France", Count: 2}}, nil
+	}
+	router := buildRouter(repo, noopCache(), nil, &mockPinger{}, &mockPinger{})
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/api/v1/regions", want: `{"regions":[{"name":"Europe","count":3},{"name":"Asia","count":1}]}`},
+		{path: "/api/v1/countries", want: `{"countries":[{"name":"France","count":2}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.JSONEq(t, tt.want, w.Body.String())
+		})
+	}
+}
+
+func TestListDistinct_EmptyAndErrors(t *testing.T) {
+	empty := buildRouter(noopRepo(), noopCache(), nil, &mockPinger{}, &mockPinger{})
+
+	failing := noopRepo()
+	failing.regionsFn = func(_ context.Context) ([]destination.NameCount, error) { return nil, fmt.Errorf("db down") }
+	failing.countriesFn = failing.regionsFn
+	broken := buildRouter(failing, noopCache(), nil, &mockPinger{}, &mockPinger{})
+
+	for _, path := range []string{"/api/v1/regions", "/api/v1/countries"} {
+		t.Run(path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			w := httptest.NewRecorder()
+			empty.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Body.String(), `:[]`)
+
+			w = httptest.NewRecorder()
+			broken.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusInternalServerError, w.Code)
+		})
+	}
+}
+
+// ---- DELETE /api/v1/destinations (bulk) ----
+
+func TestBulkDelete_Filters(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantRegion string
+		wantAge    time.Duration
+	}{
+		{name: "region", query: "?region=Europe", wantRegion: "Europe"},
+		{name: "older_than days", query: "?older_than=90d", wantAge: 90 * 24 * time.Hour},
+		{name: "older_than duration", query: "?older_than=12h", wantAge: 12 * time.Hour},
+		{name: "both", query: "?region=Asia&older_than=30d", wantRegion: "Asia", wantAge: 30 * 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got destination.BulkDeleteFilter
+			repo := noopRepo()
+			repo.deleteMatchingFn = func(_ context.Context, f destination.BulkDeleteFilter) ([]string, error) {
+				got = f
+				return []string{"Paris", "Berlin"}, nil
+			}
+			var invalidated []string
+			cache := noopCache()
+			cache.deleteFn = func(_ context.Context, city string) error {
+				invalidated = append(invalidated, city)
+				return nil
+			}
+
+			router := buildAdminRouter(repo, cache, nil)
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/destinations"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+testAdminToken)
+			w := httptest.NewRecorder()
+			before := time.Now().UTC()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.JSONEq(t, `{"deleted":2}`, w.Body.String())
+			assert.Equal(t, []string{"Paris", "Berlin"}, invalidated)
+			assert.Equal(t, tt.wantRegion, got.Region)
+			if tt.wantAge == 0 {
+				assert.True(t, got.FetchedBefore.IsZero())
+			} else {
+				assert.WithinDuration(t, before.Add(-tt.wantAge), got.FetchedBefore, time.Second)
+			}
+		})
+	}
+}
+
+func TestBulkDelete_RejectsBadRequests(t *testing.T) {
+	repo := noopRepo()
+	repo.deleteMatchingFn = func(_ context.Context, _ destination.BulkDeleteFilter) ([]string, error) {
+		t.Fatal("repository must not be called")
+		return nil, nil
+	}
+	router := buildAdminRouter(repo, noopCache(), nil)
+
+	for _, query := range []string{"", "?region=+", "?older_than=soon", "?older_than=-5d", "?older_than=0h"} {
+		t.Run(query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/destinations"+query, nil)
+			req.Header.Set("Authorization", "Bearer "+testAdminToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestBulkDelete_RequiresAdminToken(t *testing.T) {
+	router := buildAdminRouter(noopRepo(), noopCache(), nil)
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/destinations?region=Europe", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestBulkDelete_DBError(t *testing.T) {
+	repo := noopRepo()
+	repo.deleteMatchingFn = func(_ context.Context, _ destination.BulkDeleteFilter) ([]string, error) {
+		return nil, fmt.Errorf("db down")
+	}
+	router := buildAdminRouter(repo, noopCache(), nil)
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/destinations?region=Europe", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+// ---- request timeout ----
+
+// deadlineOf serves one request through RequestTimeout(def) and returns the
+// response and how far away the handler's context deadline was.
+func deadlineOf(t *testing.T, def time.Duration, header string) (*httptest.ResponseRecorder, time.Duration) {
+	t.Helper()
+	var remaining time.Duration
+	h := api.RequestTimeout(def)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, ok := r.Context().Deadline()
+		require.True(t, ok, "handler context has a deadline")
+		remaining = time.Until(deadline)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if header != "" {
+		req.Header.Set("X-Request-Timeout", header)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w, remaining
+}
+
+func TestRequestTimeout_Override(t *testing.T) {
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{header: "", want: 10 * time.Second},
+		{header: "45s", want: 45 * time.Second},
+		{header: "2", want: 2 * time.Second},
+		{header: "1500ms", want: 1500 * time.Millisecond},
+		{header: "1m", want: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			w, remaining := deadlineOf(t, 10*time.Second, tt.header)
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.InDelta(t, tt.want, remaining, float64(100*time.Millisecond))
+		})
+	}
+}
+
+func TestRequestTimeout_RejectsOutOfBounds(t *testing.T) {
+	for _, header := range []string{"500ms", "0", "-5s", "61s", "2h", "soon"} {
+		t.Run(header, func(t *testing.T) {
+			h := api.RequestTimeout(10 * time.Second)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+				t.Fatal("handler must not run")
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-Request-Timeout", header)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), "X-Request-Timeout")
+		})
+	}
+}
+
+func TestRequestTimeout_CutsOffSlowFetch(t *testing.T) {
+	fetcher := &mockFetcher{fetchAllFn: func(ctx context.Context, _, _ string) (*destination.FetchResult, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+			return &destination.FetchResult{Data: sampleData()}, nil
+		}
+	}}
+	handlers := api.NewHandlers(noopRepo(), noopCache(), fetcher, slog.Default())
+	router := api.NewRouter(handlers, testToken, &mockPinger{}, &mockPinger{}, slog.Default(), api.WithRequestTimeout(time.Second))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	start := time.Now()
+	router.ServeHTTP(w, req)
+
+	assert.Less(t, time.Since(start), 3*time.Second, "the router's deadline applies")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+// ---- strict query parameters ----
+
+func TestStrictParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []api.HandlerOption
+		path    string
+		status  int
+		unknown []string
+	}{
+		{name: "lenient by default", path: "/api/v1/destinations/Paris?contry=France", status: http.StatusOK},
+		{name: "per request", path: "/api/v1/destinations/Paris?contry=France&strict=true", status: http.StatusBadRequest, unknown: []string{"contry"}},
+		{name: "per request off", path: "/api/v1/destinations/Paris?contry=France&strict=false", status: http.StatusOK},
+		{
+			name:    "configured",
+			opts:    []api.HandlerOption{api.WithStrictParams(true)},
+			path:    "/api/v1/destinations/Paris?zz=1&contry=France",
+			status:  http.StatusBadRequest,
+			unknown: []string{"contry", "zz"},
+		},
+		{name: "known params pass", opts: []api.HandlerOption{api.WithStrictParams(true)}, path: "/api/v1/destinations/Paris?no_cache=true&layout=grouped", status: http.StatusOK},
+		{name: "strict itself is known", path: "/api/v1/destinations/Paris?no_cache=true&strict=true", status: http.StatusOK},
+		{name: "per handler allowlist", opts: []api.HandlerOption{api.WithStrictParams(true)}, path: "/api/v1/destinations/fts?q=paris&no_cache=true", status: http.StatusBadRequest, unknown: []string{"no_cache"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := noopRepo()
+			repo.getDestinationFn = func(_ context.Context, _ string) (*destination.Destination, error) {
+				return sampleDest(), nil
+			}
+			router := buildRouter(repo, noopCache(), nil, &mockPinger{}, &mockPinger{}, tt.opts...)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.status, w.Code)
+			if tt.unknown == nil {
+				return
+			}
+			var body struct {
+				Error   string   `json:"error"`
+				Unknown []string `json:"unknown"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.unknown, body.Unknown)
+			assert.Contains(t, body.Error, strings.Join(tt.unknown, ", "))
+		})
+	}
+}
+
+func TestStrictParams_RejectsBeforeSideEffects(t *testing.T) {
+	fetcher := &mockFetcher{fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) {
+		t.Fatal("fetcher must not be called")
+		return nil, nil
+	}}
+	router := buildRouter(noopRepo(), noopCache(), fetcher, &mockPinger{}, &mockPinger{}, api.WithStrictParams(true))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh?contry=France", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestStrictParams_RefreshEnvelope(t *testing.T) {
+	router := buildRouter(noopRepo(), noopCache(), &mockFetcher{
+		fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) { return sampleResult(), nil },
+	}, &mockPinger{}, &mockPinger{}, api.WithStrictParams(true))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh?envelope=true", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var body map[string]map[string]any
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Contains(t, body["data"], "weather")
+	assert.Equal(t, false, body["meta"]["cached"])
+}
+
+// ---- 500 error detail ----
+
+func TestServerError_Detail(t *testing.T) {
+	fetchErr := fmt.Errorf("fetching weather: %w", &url.Error{
+		Op:  "Get",
+		URL: "https://api.openweathermap.org/data/2.5/weather?q=Paris&appid=s3cr3t&units=metric",
+		Err: errors.New("connection refused"),
+	})
+
+	tests := []struct {
+		name       string
+		opts       []api.HandlerOption
+		wantDetail bool
+	}{
+		{name: "terse by default", wantDetail: false},
+		{name: "disabled", opts: []api.HandlerOption{api.WithErrorDetail(false)}, wantDetail: false},
+		{name: "verbose", opts: []api.HandlerOption{api.WithErrorDetail(true)}, wantDetail: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := &mockFetcher{fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) {
+				return nil, fetchErr
+			}}
+			router := buildRouter(noopRepo(), noopCache(), fetcher, &mockPinger{}, &mockPinger{}, tt.opts...)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh", nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusInternalServerError, w.Code)
+			var body map[string]string
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, "failed to fetch destination data", body["error"])
+			assert.NotContains(t, w.Body.String(), "s3cr3t")
+			if !tt.wantDetail {
+				assert.NotContains(t, body, "detail")
+				return
+			}
+			assert.Contains(t, body["detail"], "connection refused")
+			assert.Contains(t, body["detail"], "appid=[redacted]&units=metric")
+		})
+	}
+}
+
+func TestServerError_DetailRedactsJSONSecrets(t *testing.T) {
+	repo := noopRepo()
+	repo.getDestinationFn = func(_ context.Context, _ string) (*destination.Destination, error) {
+		return nil, errors.New(`bad config {"api_key":"s3cr3t"}`)
+	}
+	router := buildRouter(repo, noopCache(), nil, &mockPinger{}, &mockPinger{}, api.WithErrorDetail(true))
+
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris", nil)
-	req.Header.Set("Authorization", testToken) // no "Bearer " prefix
+	req.Header.Set("Authorization", "Bearer "+testToken)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "bad config")
+	assert.NotContains(t, w.Body.String(), "s3cr3t")
+}
+
+// ---- DELETE /api/v1/destinations/{city} ----
+
+func TestDeleteDestination_AdminAudit(t *testing.T) {
+	var deleted, evicted string
+	repo := noopRepo()
+	repo.deleteDestFn = func(_ context.Context, city string) (bool, error) {
+		deleted = city
+		return true, nil
+	}
+	cache := noopCache()
+	cache.deleteFn = func(_ context.Context, city string) error {
+		evicted = city
+		return nil
+	}
+
+	var logs bytes.Buffer
+	log := slog.New(slog.NewJSONHandler(&logs, nil))
+	router := api.NewRouter(api.NewHandlers(repo, cache, nil, log), testToken, &mockPinger{}, &mockPinger{}, log, api.WithAdminToken(testAdminToken))
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/destinations/Paris", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	req.Header.Set("X-Request-Id", "req-123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "Paris", deleted)
+	assert.Equal(t, "Paris", evicted)
+	assert.Contains(t, logs.String(), `"msg":"destination deleted"`)
+	assert.Contains(t, logs.String(), `"admin":true`)
+	assert.Contains(t, logs.String(), `"request_id":"req-123"`)
+}
+
+// ---- WithCountryKey ----
+
+// sameNamedCities returns a repo storing Paris in France and in the United States,
+// the US one updated most recently.
+func sameNamedCities() *mockRepo {
+	paris := func(country string, temp float64) *destination.Destination {
+		return &destination.Destination{City: "Paris", Country: country, Data: destination.DestinationData{
+			Weather: &destination.WeatherData{Temperature: temp},
+		}}
+	}
+	repo := noopRepo()
+	repo.getDestinationFn = func(_ context.Context, _ string) (*destination.Destination, error) {
+		return paris("United States", 30), nil
+	}
+	repo.getInCountryFn = func(_ context.Context, _, country string) (*destination.Destination, error) {
+		switch country {
+		case "France":
+			return paris("France", 15), nil
+		case "United States":
+			return paris("United States", 30), nil
+		}
+		return nil, nil
+	}
+	return repo
+}
+
+func TestGetDestination_CountryKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		countryKey bool
+		query      string
+		wantStatus int
+		wantTemp   float64
+		wantKey    string
+	}{
+		{name: "france", countryKey: true, query: "?country=France", wantStatus: http.StatusOK, wantTemp: 15, wantKey: "Paris@France"},
+		{name: "united states", countryKey: true, query: "?country=United+States", wantStatus: http.StatusOK, wantTemp: 30, wantKey: "Paris@United States"},
+		{name: "no country gets the latest", countryKey: true, wantStatus: http.StatusOK, wantTemp: 30, wantKey: "Paris"},
+		{name: "unknown country", countryKey: true, query: "?country=Canada", wantStatus: http.StatusNotFound},
+		{name: "country ignored without the option", query: "?country=France", wantStatus: http.StatusOK, wantTemp: 30, wantKey: "Paris"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotKeys []string
+			cache := noopCache()
+			cache.getFn = func(_ context.Context, key string) (*destination.CachedData, error) {
+				gotKeys = append(gotKeys, key)
+				return nil, nil
+			}
+			var setKey string
+			cache.setFn = func(_ context.Context, key string, _ *destination.DestinationData, _ time.Time) error {
+				setKey = key
+				return nil
+			}
+
+			router := buildRouter(sameNamedCities(), cache, nil, nil, nil, api.WithCountryKey(tt.countryKey))
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var body destination.DestinationData
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			assert.Equal(t, tt.wantTemp, body.Weather.Temperature)
+			assert.Equal(t, []string{tt.wantKey}, gotKeys, "each country is cached separately")
+			assert.Equal(t, tt.wantKey, setKey)
+		})
+	}
+}
+
+func TestRefreshDestination_CountryKey(t *testing.T) {
+	var upsertedCountry string
+	repo := sameNamedCities()
+	repo.upsertFn = func(_ context.Context, _, country string, _ destination.DestinationData, _ time.Time) (bool, error) {
+		upsertedCountry = country
+		return true, nil
+	}
+	var deleted []string
+	var setKey string
+	cache := noopCache()
+	cache.deleteFn = func(_ context.Context, key string) error {
+		deleted = append(deleted, key)
+		return nil
+	}
+	cache.setFn = func(_ context.Context, key string, _ *destination.DestinationData, _ time.Time) error {
+		setKey = key
+		return nil
+	}
+	fetcher := &mockFetcher{
+		fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) { return sampleResult(), nil },
+	}
+
+	router := buildRouter(repo, cache, fetcher, nil, nil, api.WithCountryKey(true))
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh?country=France", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "France", upsertedCountry)
+	assert.Equal(t, []string{"Paris@France", "Paris"}, deleted, "the unqualified entry may now be stale")
+	assert.Equal(t, "Paris@France", setKey)
+}
+
+func TestGetFullDestination_CountryKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		countryKey  bool
+		query       string
+		wantStatus  int
+		wantCountry string
+	}{
+		{name: "france", countryKey: true, query: "?country=France", wantStatus: http.StatusOK, wantCountry: "France"},
+		{name: "no country gets the latest", countryKey: true, wantStatus: http.StatusOK, wantCountry: "United States"},
+		{name: "unknown country", countryKey: true, query: "?country=Canada", wantStatus: http.StatusNotFound},
+		{name: "country ignored without the option", query: "?country=France", wantStatus: http.StatusOK, wantCountry: "United States"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := buildAdminRouter(sameNamedCities(), noopCache(), nil, api.WithCountryKey(tt.countryKey))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris/full"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+testAdminToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			assert.Equal(t, tt.wantCountry, body["country"])
+		})
+	}
+}
+
+func TestDeleteDestination_CountryKey(t *testing.T) {
+	repo := noopRepo()
+	repo.deleteDestFn = func(_ context.Context, _ string) (bool, error) { return true, nil }
+	var evicted, pattern string
+	cache := noopCache()
+	cache.deleteFn = func(_ context.Context, city string) error {
+		evicted = city
+		return nil
+	}
+	cache.deleteByPatternFn = func(_ context.Context, p string) (int, error) {
+		pattern = p
+		return 2, nil
+	}
+
+	router := buildAdminRouter(repo, cache, nil, api.WithCountryKey(true))
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/destinations/Paris", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "Paris", evicted)
+	assert.Equal(t, "Paris@", pattern, "every country's entry is evicted")
+}
+
+func TestDeleteDestination_Admin(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		found  bool
+		err    error
+		status int
+		called bool
+	}{
+		{name: "deleted", token: testAdminToken, found: true, status: http.StatusNoContent, called: true},
+		{name: "not found", token: testAdminToken, status: http.StatusNotFound, called: true},
+		{name: "db error", token: testAdminToken, err: fmt.Errorf("db down"), status: http.StatusInternalServerError, called: true},
+		{name: "wrong token", token: "nope", found: true, status: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			repo := noopRepo()
+			repo.deleteDestFn = func(_ context.Context, _ string) (bool, error) {
+				called = true
+				return tt.found, tt.err
+			}
+			router := buildAdminRouter(repo, noopCache(), nil)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/destinations/Paris", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.called, called)
+		})
+	}
+}
+
+func TestDeleteDestination(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		found  bool
+		err    error
+		status int
+		called bool
+	}{
+		{name: "deleted", token: testToken, found: true, status: http.StatusNoContent, called: true},
+		{name: "not found", token: testToken, status: http.StatusNotFound, called: true},
+		{name: "db error", token: testToken, err: fmt.Errorf("db down"), status: http.StatusInternalServerError, called: true},
+		{name: "admin token without ADMIN_TOKEN", token: testAdminToken, found: true, status: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			repo := noopRepo()
+			repo.deleteDestFn = func(_ context.Context, city string) (bool, error) {
+				called = true
+				assert.Equal(t, "Paris", city)
+				return tt.found, tt.err
+			}
+			var evicted string
+			cache := noopCache()
+			cache.deleteFn = func(_ context.Context, city string) error {
+				evicted = city
+				return nil
+			}
+			router := buildRouter(repo, cache, nil, &mockPinger{}, &mockPinger{})
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/destinations/Paris", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.called, called)
+			if tt.status == http.StatusNoContent {
+				assert.Equal(t, "Paris", evicted)
+			}
+		})
+	}
+}
+
+// ---- DELETE /api/v1/admin/cache ----
+
+func TestInvalidateCache(t *testing.T) {
+	var got string
+	cache := noopCache()
+	cache.deleteByPatternFn = func(_ context.Context, pattern string) (int, error) {
+		got = pattern
+		return 3, nil
+	}
+	router := buildAdminRouter(noopRepo(), cache, nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/admin/cache?match=+san+", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"deleted":3}`, w.Body.String())
+	assert.Equal(t, "san", got)
+}
+
+func TestInvalidateCache_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		token  string
+		err    error
+		status int
+	}{
+		{name: "missing match", query: "", token: testAdminToken, status: http.StatusBadRequest},
+		{name: "blank match", query: "?match=++", token: testAdminToken, status: http.StatusBadRequest},
+		{name: "redis error", query: "?match=san", token: testAdminToken, err: fmt.Errorf("redis down"), status: http.StatusInternalServerError},
+		{name: "user token", query: "?match=san", token: testToken, status: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := noopCache()
+			cache.deleteByPatternFn = func(_ context.Context, _ string) (int, error) { return 0, tt.err }
+			router := buildAdminRouter(noopRepo(), cache, nil)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/admin/cache"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+// ---- GET /api/v1/destinations/{city}/full ----
+
+func TestGetFullDestination(t *testing.T) {
+	fetchedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	updated := time.Date(2026, 3, 1, 12, 0, 1, 0, time.UTC)
+
+	repo := noopRepo()
+	repo.getDestinationFn = func(_ context.Context, _ string) (*destination.Destination, error) {
+		dest := sampleDest()
+		dest.FetchedAt = &fetchedAt
+		dest.CreatedAt = created
+		dest.UpdatedAt = updated
+		return dest, nil
+	}
+	router := buildAdminRouter(repo, noopCache(), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris/full", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var body map[string]any
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, float64(1), body["id"])
+	assert.Equal(t, "Paris", body["city"])
+	assert.Equal(t, "France", body["country"])
+	assert.Equal(t, "2026-03-01T12:00:00Z", body["fetched_at"])
+	assert.Equal(t, "2026-01-01T00:00:00Z", body["created_at"])
+	assert.Equal(t, "2026-03-01T12:00:01Z", body["updated_at"])
+	require.IsType(t, map[string]any{}, body["data"])
+	assert.Contains(t, body["data"], "weather")
+}
+
+func TestGetFullDestination_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		getFn  func(context.Context, string) (*destination.Destination, error)
+		token  string
+		status int
+	}{
+		{
+			name:   "not found",
+			getFn:  func(_ context.Context, _ string) (*destination.Destination, error) { return nil, nil },
+			token:  testAdminToken,
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "db error",
+			getFn:  func(_ context.Context, _ string) (*destination.Destination, error) { return nil, fmt.Errorf("db down") },
+			token:  testAdminToken,
+			status: http.StatusInternalServerError,
+		},
+		{
+			name:   "regular token rejected",
+			getFn:  func(_ context.Context, _ string) (*destination.Destination, error) { return sampleDest(), nil },
+			token:  testToken,
+			status: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := noopRepo()
+			repo.getDestinationFn = tt.getFn
+			router := buildAdminRouter(repo, noopCache(), nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris/full", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestRefreshDestination_StoresFetchedCountry(t *testing.T) {
+	var passed, stored string
+	repo := noopRepo()
+	repo.upsertFn = func(_ context.Context, _, country string, _ destination.DestinationData, _ time.Time) (bool, error) {
+		stored = country
+		return true, nil
+	}
+	fetcher := &mockFetcher{
+		fetchAllFn: func(_ context.Context, _, country string) (*destination.FetchResult, error) {
+			passed = country
+			res := sampleResult()
+			res.Country = "France"
+			return res, nil
+		},
+	}
+	router := buildRouter(repo, noopCache(), fetcher, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Lyon/refresh", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, passed, "no country is passed so the fetcher can derive one")
+	assert.Equal(t, "France", stored)
+}
+
+func TestRefreshDestination_CountsUpserts(t *testing.T) {
+	m := metrics.New()
+	inserted := true
+	repo := noopRepo()
+	repo.upsertFn = func(_ context.Context, _, _ string, _ destination.DestinationData, _ time.Time) (bool, error) {
+		return inserted, nil
+	}
+	fetcher := &mockFetcher{
+		fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) { return sampleResult(), nil },
+	}
+	handlers := api.NewHandlers(repo, noopCache(), fetcher, slog.Default(), api.WithHandlerMetrics(m))
+	router := api.NewRouter(handlers, testToken, nil, nil, slog.Default())
+
+	refresh := func() {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh", nil)
+		req.Header.Set("Authorization", "Bearer "+testToken)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	refresh()
+	inserted = false
+	refresh()
+	refresh()
+
+	assert.Equal(t, 1.0, promtestutil.ToFloat64(m.DestinationUpserts.WithLabelValues(metrics.UpsertInserted)))
+	assert.Equal(t, 2.0, promtestutil.ToFloat64(m.DestinationUpserts.WithLabelValues(metrics.UpsertUpdated)))
+}
+
+// memCache is a stateful DestinationCache for tests that follow an entry across requests.
+type memCache struct {
+	entries map[string]*destination.CachedData
+}
+
+func newMemCache() *memCache { return &memCache{entries: map[string]*destination.CachedData{}} }
+
+func (m *memCache) Get(_ context.Context, city string) (*destination.CachedData, error) {
+	return m.entries[city], nil
+}
+func (m *memCache) Set(_ context.Context, city string, data *destination.DestinationData, fetchedAt time.Time) error {
+	m.entries[city] = &destination.CachedData{Data: data, FetchedAt: fetchedAt}
+	return nil
+}
+func (m *memCache) SetNotFound(_ context.Context, city string) error {
+	m.entries[city] = &destination.CachedData{NotFound: true}
+	return nil
+}
+func (m *memCache) Delete(_ context.Context, city string) error {
+	delete(m.entries, city)
+	return nil
+}
+func (m *memCache) DeleteByPattern(_ context.Context, pattern string) (int, error) {
+	n := 0
+	for city := range m.entries {
+		if strings.Contains(strings.ToLower(city), strings.ToLower(pattern)) {
+			delete(m.entries, city)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func TestGetDestination_CacheConsistencyCheck(t *testing.T) {
+	cachedAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	cachedData := &destination.DestinationData{Weather: &destination.WeatherData{Temperature: 10}}
+
+	tests := []struct {
+		name       string
+		disabled   bool
+		dbTime     time.Time
+		dbMissing  bool
+		dbErr      error
+		wantTemp   float64
+		wantCached bool
+	}{
+		{name: "cache newer", dbTime: cachedAt.Add(-time.Hour), wantTemp: 10, wantCached: true},
+		{name: "same age", dbTime: cachedAt, wantTemp: 10, wantCached: true},
+		{name: "db newer", dbTime: cachedAt.Add(time.Hour), wantTemp: 22.5},
+		{name: "db newer but check disabled", disabled: true, dbTime: cachedAt.Add(time.Hour), wantTemp: 10, wantCached: true},
+		{name: "no db row", dbMissing: true, wantTemp: 10, wantCached: true},
+		{name: "check fails", dbErr: errors.New("db down"), wantTemp: 10, wantCached: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var checks int
+			dbFetchedAt := tt.dbTime
+			repo := noopRepo()
+			repo.fetchedAtFn = func(_ context.Context, _ string) (time.Time, bool, error) {
+				checks++
+				return tt.dbTime, !tt.dbMissing, tt.dbErr
+			}
+			repo.getDestinationFn = func(_ context.Context, _ string) (*destination.Destination, error) {
+				dest := sampleDest()
+				dest.FetchedAt = &dbFetchedAt
+				return dest, nil
+			}
+			cache := newMemCache()
+			require.NoError(t, cache.Set(context.Background(), "Paris", cachedData, cachedAt))
+			router := buildRouter(repo, cache, nil, nil, nil, api.WithCacheConsistencyCheck(!tt.disabled))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris?envelope=true", nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			var got struct {
+				Data destination.DestinationData `json:"data"`
+				Meta struct {
+					Cached bool `json:"cached"`
+				} `json:"meta"`
+			}
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+			assert.Equal(t, tt.wantTemp, got.Data.Weather.Temperature)
+			assert.Equal(t, tt.wantCached, got.Meta.Cached)
+			if tt.disabled {
+				assert.Zero(t, checks, "the DB must not be asked when the check is off")
+			}
+			if !tt.wantCached {
+				assert.Equal(t, dbFetchedAt, cache.entries["Paris"].FetchedAt, "the newer DB copy is re-cached")
+			}
+		})
+	}
+}
+
+func TestGetDestination_FetchOnMiss(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []api.HandlerOption
+		query     string
+		wantCode  int
+		wantFetch bool
+	}{
+		{name: "default returns 404", wantCode: http.StatusNotFound},
+		{name: "query opts in", query: "?fetch_on_miss=true", wantCode: http.StatusOK, wantFetch: true},
+		{name: "option enables", opts: []api.HandlerOption{api.WithFetchOnMiss(true)}, wantCode: http.StatusOK, wantFetch: true},
+		{name: "query opts out", opts: []api.HandlerOption{api.WithFetchOnMiss(true)}, query: "?fetch_on_miss=false", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fetched, stored bool
+			repo := noopRepo()
+			repo.getDestinationFn = func(_ context.Context, _ string) (*destination.Destination, error) { return nil, nil }
+			repo.upsertFn = func(_ context.Context, city, country string, _ destination.DestinationData, _ time.Time) (bool, error) {
+				stored = true
+				assert.Equal(t, "Paris", city)
+				assert.Equal(t, "Paris", country)
+				return true, nil
+			}
+			fetcher := &mockFetcher{
+				fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) {
+					fetched = true
+					return sampleResult(), nil
+				},
+			}
+			cache := newMemCache()
+			router := buildRouter(repo, cache, fetcher, nil, nil, tt.opts...)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantCode, w.Code)
+			assert.Equal(t, tt.wantFetch, fetched)
+			assert.Equal(t, tt.wantFetch, stored)
+			if tt.wantFetch {
+				var got destination.DestinationData
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+				assert.Equal(t, 22.5, got.Weather.Temperature)
+				require.NotNil(t, cache.entries["Paris"])
+				assert.False(t, cache.entries["Paris"].NotFound)
+			}
+		})
+	}
+}
+
+func TestGetDestination_FetchOnMissIgnoresNotFoundMarker(t *testing.T) {
+	repo := noopRepo()
+	repo.getDestinationFn = func(_ context.Context, _ string) (*destination.Destination, error) { return nil, nil }
+	fetcher := &mockFetcher{
+		fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) { return sampleResult(), nil },
+	}
+	cache := newMemCache()
+	require.NoError(t, cache.SetNotFound(context.Background(), "Paris"))
+	router := buildRouter(repo, cache, fetcher, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Paris?fetch_on_miss=true", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, cache.entries["Paris"].NotFound)
+}
+
+func TestGetDestination_NegativeCache(t *testing.T) {
+	var dbReads int
+	repo := noopRepo()
+	repo.getDestinationFn = func(_ context.Context, _ string) (*destination.Destination, error) {
+		dbReads++
+		return nil, nil
+	}
+	fetcher := &mockFetcher{
+		fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) { return sampleResult(), nil },
+	}
+	c := newMemCache()
+	router := buildRouter(repo, c, fetcher, nil, nil)
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+testToken)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/api/v1/destinations/Atlantis").Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/api/v1/destinations/Atlantis").Code)
+	assert.Equal(t, 1, dbReads, "second 404 is served from the negative cache entry")
+
+	require.Equal(t, http.StatusOK, do(http.MethodPost, "/api/v1/destinations/Atlantis/refresh").Code)
+	require.NotNil(t, c.entries["Atlantis"])
+	assert.False(t, c.entries["Atlantis"].NotFound, "refresh replaces the negative entry")
+
+	w := do(http.MethodGet, "/api/v1/destinations/Atlantis")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, dbReads)
+}
+
+func TestGetDestination_NegativeCacheSkippedWithNoStore(t *testing.T) {
+	c := newMemCache()
+	router := buildRouter(noopRepo(), c, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Atlantis?no_store=true", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, c.entries)
+}
+
+// ---- Path length limit ----
+
+func TestRouter_RejectsLongPath(t *testing.T) {
+	handlers := api.NewHandlers(noopRepo(), noopCache(), nil, slog.Default())
+	router := api.NewRouter(handlers, testToken, &mockPinger{}, &mockPinger{}, slog.Default(), api.WithMaxPathLength(64))
+
+	serve := func(path string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+testToken)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusRequestURITooLong, serve("/api/v1/destinations/"+strings.Repeat("a", 64)))
+	assert.Equal(t, http.StatusRequestURITooLong, serve("/unknown/"+strings.Repeat("%20", 20)), "escaped length counts, before routing")
+	assert.Equal(t, http.StatusNotFound, serve("/api/v1/destinations/Paris"), "short paths reach the handler")
+}
+
+func TestRouter_DefaultMaxPathLength(t *testing.T) {
+	router := buildRouter(noopRepo(), noopCache(), nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/"+strings.Repeat("a", 2048), nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestURITooLong, w.Code)
+}
+
+// ---- Body logging middleware ----
+
+func TestLogBodies(t *testing.T) {
+	var logs bytes.Buffer
+	log := slog.New(slog.NewJSONHandler(&logs, nil))
+
+	var received string
+	h := api.LogBodies(log, 1024)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		received = string(b)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	body := `{"city":"Paris","api_key":"sk-123","nested":{"Password":"hunter2"}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/batch", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, body, received, "handler must still receive the full, unredacted body")
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+	assert.Equal(t, `{"city":"Paris","api_key":"[redacted]","nested":{"Password":"[redacted]"}}`, entry["body"])
+	assert.Equal(t, "[redacted]", entry["authorization"])
+	assert.Equal(t, false, entry["truncated"])
+	assert.NotContains(t, logs.String(), testToken)
+	assert.NotContains(t, logs.String(), "sk-123")
+}
+
+func TestLogBodies_CapsSizeAndSkipsReads(t *testing.T) {
+	var logs bytes.Buffer
+	log := slog.New(slog.NewJSONHandler(&logs, nil))
+
+	var received string
+	h := api.LogBodies(log, 8)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		received = string(b)
+	}))
+
+	body := strings.Repeat("x", 100)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body)))
+	assert.Equal(t, body, received)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+	assert.Equal(t, "xxxxxxxx", entry["body"])
+	assert.Equal(t, true, entry["truncated"])
+
+	logs.Reset()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Empty(t, logs.String(), "reads are not logged")
+}
+
+func TestAccessLog_Combined(t *testing.T) {
+	var out, logs bytes.Buffer
+	log := slog.New(slog.NewJSONHandler(&logs, nil))
+	h := api.AccessLog(api.AccessLogCombined, log, &out)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hello"))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations/Paris/refresh?debug=true", nil)
+	req.RemoteAddr = "203.0.113.7:51234"
+	req.Header.Set("User-Agent", "curl/8.0")
+	req.Header.Set("Referer", "https://example.com/")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Regexp(t,
+		`^203\.0\.113\.7 - - \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] `+
+			`"POST /api/v1/destinations/Paris/refresh\?debug=true HTTP/1\.1" 201 5 "https://example.com/" "curl/8\.0"\n$`,
+		out.String())
+	assert.Empty(t, logs.String(), "combined lines bypass the structured logger")
+
+	// No body is "-", as are missing headers.
+	out.Reset()
+	h = api.AccessLog(api.AccessLogCombined, log, &out)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/api/v1/destinations/Paris", nil))
+	assert.Contains(t, out.String(), `"DELETE /api/v1/destinations/Paris HTTP/1.1" 204 - "-" "-"`)
+}
+
+func TestAccessLog_JSON(t *testing.T) {
+	var out, logs bytes.Buffer
+	log := slog.New(slog.NewJSONHandler(&logs, nil))
+	h := api.AccessLog(api.AccessLogJSON, log, &out)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/destinations/Atlantis?token=s3cret", nil)
+	req.RemoteAddr = "203.0.113.7:51234"
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Empty(t, out.String())
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+	assert.Equal(t, "request", entry["msg"])
+	assert.Equal(t, "203.0.113.7", entry["remote_ip"])
+	assert.Equal(t, "GET", entry["method"])
+	assert.Equal(t, "/api/v1/destinations/Atlantis?token=[redacted]", entry["path"])
+	assert.Equal(t, 404.0, entry["status"])
+	assert.Equal(t, float64(len("not found\n")), entry["bytes"])
+	assert.Contains(t, entry, "duration_ms")
+	assert.NotContains(t, logs.String(), "s3cret")
+}
+
+func TestProbeProvider(t *testing.T) {
+	fetcher := &mockFetcher{
+		probeProviderFn: func(_ context.Context, provider string) (*destination.ProbeResult, error) {
+			switch provider {
+			case destination.ProviderWeather:
+				return &destination.ProbeResult{
+					Provider: provider,
+					Latency:  42 * time.Millisecond,
+					Result:   &destination.WeatherData{Temperature: 12.5, Description: "light rain"},
+				}, nil
+			case destination.ProviderTeleport:
+				return &destination.ProbeResult{
+					Provider: provider,
+					Latency:  7 * time.Millisecond,
+					Err:      fmt.Errorf("GET https://api.example.com/scores?appid=secret returned status 503"),
+				}, nil
+			default:
+				return nil, fmt.Errorf("%w: %q", destination.ErrUnknownProvider, provider)
+			}
+		},
+	}
+	router := buildAdminRouter(noopRepo(), noopCache(), fetcher)
+
+	probe := func(name, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/providers/"+name+"/probe", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("working", func(t *testing.T) {
+		w := probe("weather", testAdminToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"provider":"weather","status":"ok","latency_ms":42,
+			"result":{"temperature":12.5,"feels_like":0,"humidity":0,"description":"light rain","wind_speed":0}}`, w.Body.String())
+	})
+
+	t.Run("failing", func(t *testing.T) {
+		w := probe("teleport", testAdminToken)
+		require.Equal(t, http.StatusBadGateway, w.Code)
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+		assert.Equal(t, "error", body["status"])
+		assert.Contains(t, body["error"], "503")
+		assert.NotContains(t, body["error"], "secret")
+		assert.NotContains(t, body, "result")
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, probe("nope", testAdminToken).Code)
+	})
+
+	t.Run("user token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, probe("weather", testToken).Code)
+	})
+}
+
+func TestDiffDestination(t *testing.T) {
+	repo := noopRepo()
+	repo.getDestinationFn = func(_ context.Context, _ string) (*destination.Destination, error) {
+		d := sampleDest()
+		d.Data.Country = &destination.CountryData{Region: "Europe", Capital: "Paris"}
+		return d, nil
+	}
+	repo.upsertFn = func(_ context.Context, _, _ string, _ destination.DestinationData, _ time.Time) (bool, error) {
+		t.Error("diff must not store anything")
+		return false, nil
+	}
+	cache := noopCache()
+	cache.setFn = func(_ context.Context, _ string, _ *destination.DestinationData, _ time.Time) error {
+		t.Error("diff must not cache anything")
+		return nil
+	}
+
+	var gotCountry string
+	fetcher := &mockFetcher{
+		fetchAllFn: func(_ context.Context, _, country string) (*destination.FetchResult, error) {
+			gotCountry = country
+			return &destination.FetchResult{
+				Data: &destination.DestinationData{
+					Weather:       &destination.WeatherData{Temperature: 25, Description: "clear sky"},
+					QualityScores: []destination.QualityScore{{Name: "Safety", ScoreOutOf: 7}},
+				},
+				Errors: map[string]error{destination.ProviderCountry: fmt.Errorf("restcountries down")},
+			}, nil
+		},
+	}
+	router := buildAdminRouter(repo, cache, fetcher)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/destinations/Paris/diff", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "France", gotCountry, "the stored country is used")
+	assert.JSONEq(t, `{
+		"city": "Paris",
+		"status": "partial",
+		"sources": {"weather": "ok", "poi": "ok", "country": "error", "teleport": "ok"},
+		"changes": [
+			{"path": "quality_scores", "kind": "added", "new": [{"name": "Safety", "score_out_of_10": 7}]},
+			{"path": "weather.temperature", "kind": "changed", "old": 22.5, "new": 25}
+		]
+	}`, w.Body.String(), "the failed country provider keeps its stored section")
+}
+
+func TestDiffDestination_NotStored(t *testing.T) {
+	fetcher := &mockFetcher{
+		fetchAllFn: func(_ context.Context, _, _ string) (*destination.FetchResult, error) {
+			t.Error("nothing to diff against, so nothing should be fetched")
+			return sampleResult(), nil
+		},
+	}
+	router := buildAdminRouter(noopRepo(), noopCache(), fetcher)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/destinations/Atlantis/diff", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
 }
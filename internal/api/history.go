@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/neexbeast/ygo-test/internal/destination"
+)
+
+// historyResponse is the body returned by GetHistory.
+type historyResponse struct {
+	City    string          `json:"city"`
+	History []snapshotEntry `json:"history"`
+}
+
+// snapshotEntry is one past version of a destination, with its timestamp in the
+// configured format.
+type snapshotEntry struct {
+	Country   string                      `json:"country"`
+	Data      destination.DestinationData `json:"data"`
+	FetchedAt timestamp                   `json:"fetched_at"`
+}
+
+// GetHistory handles GET /api/v1/destinations/{city}/history.
+// Returns the city's past versions newest first, at most ?limit of them. A city
+// with no history gets an empty list rather than 404, as history outlives purges.
+func (h *Handlers) GetHistory(w http.ResponseWriter, r *http.Request) {
+	if !h.checkParams(w, r, "limit") {
+		return
+	}
+	limit, err := parseLimit(r, h.historySizes)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	city := chi.URLParam(r, "city")
+
+	snapshots, err := h.repo.GetHistory(r.Context(), city, limit)
+	if err != nil {
+		h.log.Error("history query failed", "city", city, "err", err)
+		h.writeServerError(w, "internal server error", err)
+		return
+	}
+
+	history := make([]snapshotEntry, 0, len(snapshots))
+	for _, s := range snapshots {
+		history = append(history, snapshotEntry{
+			Country:   s.Country,
+			Data:      s.Data,
+			FetchedAt: timestamp{t: s.FetchedAt, format: h.timestampFormat},
+		})
+	}
+	writeJSON(w, http.StatusOK, historyResponse{City: city, History: history})
+}
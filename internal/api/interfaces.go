@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"time"
 
 	"github.com/neexbeast/ygo-test/internal/destination"
 )
@@ -9,17 +10,42 @@ import (
 // DestinationRepo defines the storage operations needed by handlers.
 type DestinationRepo interface {
 	GetDestination(ctx context.Context, city string) (*destination.Destination, error)
-	UpsertDestination(ctx context.Context, city, country string, data destination.DestinationData) error
+	GetDestinationInCountry(ctx context.Context, city, country string) (*destination.Destination, error)
+	DataFetchedAt(ctx context.Context, city string) (fetchedAt time.Time, ok bool, err error)
+	UpsertDestination(ctx context.Context, city, country string, data destination.DestinationData, fetchedAt time.Time) (inserted bool, err error)
+	GetHistory(ctx context.Context, city string, limit int) ([]destination.Snapshot, error)
+	FindIncomplete(ctx context.Context, page destination.Page) ([]destination.IncompleteDestination, error)
+	FullTextSearch(ctx context.Context, query string, page destination.Page) ([]*destination.Destination, error)
+	FindByMinQuality(ctx context.Context, filter destination.QualityFilter, page destination.Page) ([]*destination.Destination, error)
+	DeleteMatching(ctx context.Context, filter destination.BulkDeleteFilter) ([]string, error)
+	DeleteDestination(ctx context.Context, city string) (deleted bool, err error)
+	DistinctRegions(ctx context.Context) ([]destination.NameCount, error)
+	DistinctCountries(ctx context.Context) ([]destination.NameCount, error)
+	CollectionVersion(ctx context.Context) (destination.CollectionVersion, error)
 }
 
 // DestinationCache defines the cache operations needed by handlers.
 type DestinationCache interface {
-	Get(ctx context.Context, city string) (*destination.DestinationData, error)
-	Set(ctx context.Context, city string, data *destination.DestinationData) error
+	Get(ctx context.Context, city string) (*destination.CachedData, error)
+	Set(ctx context.Context, city string, data *destination.DestinationData, fetchedAt time.Time) error
+	SetNotFound(ctx context.Context, city string) error
 	Delete(ctx context.Context, city string) error
+	DeleteByPattern(ctx context.Context, pattern string) (int, error)
 }
 
 // DestinationFetcher defines the external API aggregation needed by handlers.
 type DestinationFetcher interface {
-	FetchAll(ctx context.Context, city, country string) (*destination.DestinationData, error)
+	FetchAll(ctx context.Context, city, country string) (*destination.FetchResult, error)
+	FetchProviders(ctx context.Context, city, country string, providers []string) (*destination.FetchResult, error)
+	ProbeProvider(ctx context.Context, provider string) (*destination.ProbeResult, error)
+}
+
+// RateLimitStore keeps rate limit buckets outside the process, so the limit is
+// shared by every instance using the same store. Reserve takes one request from
+// key's bucket and returns zero if it is allowed, or how long to wait if not.
+// Peek reports the bucket without taking from it: the requests it would admit
+// now and how long until it is full.
+type RateLimitStore interface {
+	Reserve(ctx context.Context, key string, perMinute, burst int) (time.Duration, error)
+	Peek(ctx context.Context, key string, perMinute, burst int) (remaining int, resetIn time.Duration, err error)
 }
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/neexbeast/ygo-test/internal/destination"
+)
+
+// sourceLanguage is the language destination data is stored in. It needs no
+// localization, so it competes with the supported languages by q-value.
+const sourceLanguage = "en"
+
+// preferredLanguage returns the highest-weighted language from an Accept-Language
+// header that destination data can be localized into, or "" if none match or
+// English is preferred.
+func preferredLanguage(header string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		// Only the primary subtag matters: "fr-CA" localizes as "fr".
+		primary, _, _ := strings.Cut(tag, "-")
+		candidates = append(candidates, candidate{lang: strings.ToLower(primary), q: q})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	for _, c := range candidates {
+		if c.q <= 0 {
+			continue
+		}
+		if c.lang == sourceLanguage {
+			return ""
+		}
+		if destination.SupportsLanguage(c.lang) {
+			return c.lang
+		}
+	}
+	return ""
+}
+
+// localize translates data for the request's Accept-Language, if any is supported.
+func localize(r *http.Request, data *destination.DestinationData) *destination.DestinationData {
+	lang := preferredLanguage(r.Header.Get("Accept-Language"))
+	if lang == "" {
+		return data
+	}
+	return data.Localized(lang)
+}
+
+// varyLanguage marks a response whose body goes through localize as depending
+// on Accept-Language, so shared caches keep the languages apart.
+func varyLanguage(w http.ResponseWriter) {
+	w.Header().Add("Vary", "Accept-Language")
+}
@@ -1,10 +1,25 @@
 package api
 
 import (
+	"bytes"
+	"context"
 	"crypto/subtle"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
 	"net/http"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/neexbeast/ygo-test/internal/metrics"
 )
 
 // BearerAuth returns middleware that validates the Authorization: Bearer <token> header.
@@ -26,3 +41,297 @@ func BearerAuth(token string) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// adminCallerKey marks a request authenticated with the admin token by BearerOrAdminAuth.
+type adminCallerKey struct{}
+
+// BearerOrAdminAuth returns middleware that accepts either the user token or,
+// if set, the admin token, for routes both may call. A request carrying the
+// admin token is marked so the handler can tell (see isAdminCaller); the user
+// token wins if the two are the same.
+func BearerOrAdminAuth(token, adminToken string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth := r.Header.Get("Authorization")
+			provided := []byte(strings.TrimPrefix(auth, "Bearer "))
+
+			switch {
+			case !strings.HasPrefix(auth, "Bearer "):
+			case subtle.ConstantTimeCompare(provided, []byte(token)) == 1:
+				next.ServeHTTP(w, r)
+				return
+			case adminToken != "" && subtle.ConstantTimeCompare(provided, []byte(adminToken)) == 1:
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), adminCallerKey{}, true)))
+				return
+			}
+
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		})
+	}
+}
+
+// isAdminCaller reports whether BearerOrAdminAuth admitted r with the admin token.
+func isAdminCaller(r *http.Request) bool {
+	admin, _ := r.Context().Value(adminCallerKey{}).(bool)
+	return admin
+}
+
+// TrustedRealIP returns middleware that applies chi's middleware.RealIP only when
+// the direct peer is inside one of the trusted CIDRs. Requests from any other
+// peer keep their RemoteAddr, so clients cannot spoof X-Forwarded-For/X-Real-IP.
+func TrustedRealIP(trusted []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		realIP := middleware.RealIP(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if peerTrusted(r.RemoteAddr, trusted) {
+				realIP.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// peerTrusted reports whether remoteAddr (host:port or bare IP) is in any trusted network.
+func peerTrusted(remoteAddr string, trusted []*net.IPNet) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, n := range trusted {
+		if n != nil && n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxPathLength returns middleware that rejects, with 414, any request whose
+// escaped path is longer than maxBytes. It runs before routing, so oversized
+// paths never reach handlers or their logs.
+func MaxPathLength(maxBytes int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(r.URL.EscapedPath()) > maxBytes {
+				writeJSON(w, http.StatusRequestURITooLong, map[string]string{"error": "request path too long"})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Bounds on the deadline a caller may ask for with the X-Request-Timeout header.
+// The server's write timeout must outlast MaxRequestTimeout.
+const (
+	MinRequestTimeout = time.Second
+	MaxRequestTimeout = time.Minute
+)
+
+// requestTimeoutHeader lets a caller set its own deadline for one request.
+const requestTimeoutHeader = "X-Request-Timeout"
+
+// parseRequestTimeout parses an X-Request-Timeout value: a duration such as
+// "30s" or "1500ms", or a whole number of seconds.
+func parseRequestTimeout(v string) (time.Duration, error) {
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		n, convErr := strconv.Atoi(v)
+		if convErr != nil {
+			return 0, errors.New(requestTimeoutHeader + " must be a duration like 30s or a number of seconds")
+		}
+		d = time.Duration(n) * time.Second
+	}
+	if d < MinRequestTimeout || d > MaxRequestTimeout {
+		return 0, errors.New(requestTimeoutHeader + " must be between " + MinRequestTimeout.String() + " and " + MaxRequestTimeout.String())
+	}
+	return d, nil
+}
+
+// RequestTimeout returns middleware that gives each request's context a
+// deadline of d, so provider calls and queries still running when it passes
+// are abandoned. A caller may ask for a different deadline, longer or shorter,
+// with the X-Request-Timeout header; a value that does not parse or is outside
+// MinRequestTimeout..MaxRequestTimeout gets 400.
+func RequestTimeout(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timeout := d
+			if v := strings.TrimSpace(r.Header.Get(requestTimeoutHeader)); v != "" {
+				parsed, err := parseRequestTimeout(v)
+				if err != nil {
+					writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+					return
+				}
+				timeout = parsed
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// InFlight returns middleware that tracks concurrent requests per route pattern.
+// It must run after routing (e.g. inside a chi Group) so the pattern is known.
+// The decrement is deferred, so it still happens when a handler panics and the
+// panic unwinds to the outer recovery middleware.
+func InFlight(m *metrics.Metrics) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := r.URL.Path
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+
+			gauge := m.InFlightRequests.WithLabelValues(route)
+			gauge.Inc()
+			defer gauge.Dec()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Access log formats: how AccessLog writes each request.
+const (
+	// AccessLogJSON logs each request through the structured logger, as attributes.
+	AccessLogJSON = "json"
+	// AccessLogCombined writes each request as an Apache Combined Log Format line.
+	AccessLogCombined = "combined"
+)
+
+// clfTime is the timestamp layout of the Common and Combined Log Formats.
+const clfTime = "02/Jan/2006:15:04:05 -0700"
+
+// AccessLog returns middleware that logs every request once it has been served:
+// client IP, method, path, status, response bytes and duration. With
+// AccessLogCombined the entry is a Combined Log Format line written to w;
+// otherwise it is logged to log. The user field is always "-", and secret-looking
+// query parameters are redacted either way.
+func AccessLog(format string, log *slog.Logger, w io.Writer) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww := middleware.NewWrapResponseWriter(rw, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			ip := r.RemoteAddr
+			if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+				ip = host
+			}
+			uri := redactURLs(r.URL.RequestURI())
+
+			if format == AccessLogCombined {
+				size := "-"
+				if n := ww.BytesWritten(); n > 0 {
+					size = strconv.Itoa(n)
+				}
+				_, _ = fmt.Fprintf(w, "%s - - [%s] %q %d %s %q %q\n",
+					ip, start.Format(clfTime), r.Method+" "+uri+" "+r.Proto, status, size,
+					orDash(r.Referer()), orDash(r.UserAgent()))
+				return
+			}
+			log.Info("request",
+				"remote_ip", ip,
+				"method", r.Method,
+				"path", uri,
+				"status", status,
+				"bytes", ww.BytesWritten(),
+				"duration_ms", time.Since(start).Milliseconds(),
+				"request_id", middleware.GetReqID(r.Context()),
+				"user_agent", r.UserAgent(),
+			)
+		})
+	}
+}
+
+// orDash returns s, or "-" for an empty field as the Common Log Format writes it.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// secretField matches a JSON string member whose name looks secret (token, password,
+// api_key, ...), capturing everything up to the value so the value can be replaced.
+var secretField = regexp.MustCompile(`(?i)("[^"]*(?:token|password|secret|key|authorization|credential)[^"]*"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// redactBody replaces the values of secret-looking JSON string fields. It works on
+// truncated or malformed bodies too, since it does not parse them.
+func redactBody(b []byte) string {
+	return secretField.ReplaceAllString(string(b), `$1"[redacted]"`)
+}
+
+// secretParam matches a URL query parameter whose name looks secret (appid, api_key,
+// token, ...), capturing everything up to the value so the value can be replaced.
+var secretParam = regexp.MustCompile(`(?i)([?&][^=&\s"]*(?:appid|token|password|secret|key|signature)[^=&\s"]*=)[^&\s"]*`)
+
+// redactURLs replaces the values of secret-looking query parameters in any URLs
+// within s, such as the provider URL a *url.Error carries with the API key in it.
+func redactURLs(s string) string {
+	return secretParam.ReplaceAllString(s, "${1}[redacted]")
+}
+
+// LogBodies returns debugging middleware that logs the body of write requests
+// (POST, PUT, PATCH, DELETE), at most maxBytes of it, with secret-looking fields
+// redacted. Only the Authorization header's presence is logged, never its value.
+// The body is buffered and handed on intact, so handlers read it as usual.
+func LogBodies(log *slog.Logger, maxBytes int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			default:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var head []byte
+			if r.Body != nil {
+				var err error
+				head, err = io.ReadAll(io.LimitReader(r.Body, int64(maxBytes)+1))
+				if err != nil {
+					log.Warn("request body logging: read failed", "path", r.URL.Path, "err", err)
+				}
+				r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(head), r.Body), Closer: r.Body}
+			}
+
+			truncated := len(head) > maxBytes
+			if truncated {
+				head = head[:maxBytes]
+			}
+			authorization := "absent"
+			if r.Header.Get("Authorization") != "" {
+				authorization = "[redacted]"
+			}
+			log.Info("request body",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"authorization", authorization,
+				"body", redactBody(head),
+				"truncated", truncated,
+			)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// readCloser joins a Reader replaying a buffered body with the original body's Closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,324 @@
+package api
+
+import (
+	"net"
+	"time"
+
+	"github.com/neexbeast/ygo-test/internal/metrics"
+)
+
+// HandlerOption configures optional Handlers behaviour.
+type HandlerOption func(*Handlers)
+
+// WithMinSuccessfulProviders makes RefreshDestination fail with 502, without persisting,
+// when fewer than n providers return data. Zero (the default) accepts any partial result.
+func WithMinSuccessfulProviders(n int) HandlerOption {
+	return func(h *Handlers) {
+		h.minProviders = n
+	}
+}
+
+// WithHandlerMetrics makes RefreshDestination count stored destinations on m,
+// split by whether the row was inserted or updated.
+func WithHandlerMetrics(m *metrics.Metrics) HandlerOption {
+	return func(h *Handlers) {
+		h.metrics = m
+	}
+}
+
+// WithPageSizes sets the default and maximum page size of every list endpoint.
+// Non-positive values keep the defaults of 50 and 200; a default above the
+// maximum is lowered to it.
+func WithPageSizes(sizes PageSizes) HandlerOption {
+	return func(h *Handlers) {
+		if sizes.Default > 0 {
+			h.pageSizes.Default = sizes.Default
+		}
+		if sizes.Max > 0 {
+			h.pageSizes.Max = sizes.Max
+		}
+		h.pageSizes.Default = min(h.pageSizes.Default, h.pageSizes.Max)
+	}
+}
+
+// WithHistorySizes sets how many snapshots the history endpoint returns when the
+// request has no limit, and the most it returns for any limit. Non-positive values
+// keep the defaults of 10 and 100; a default above the maximum is lowered to it.
+func WithHistorySizes(sizes PageSizes) HandlerOption {
+	return func(h *Handlers) {
+		if sizes.Default > 0 {
+			h.historySizes.Default = sizes.Default
+		}
+		if sizes.Max > 0 {
+			h.historySizes.Max = sizes.Max
+		}
+		h.historySizes.Default = min(h.historySizes.Default, h.historySizes.Max)
+	}
+}
+
+// WithStrictParams makes every handler reject query parameters it does not take
+// with a 400 listing them. Without it, requests opt in one at a time with ?strict=true.
+func WithStrictParams(enabled bool) HandlerOption {
+	return func(h *Handlers) {
+		h.strictParams = enabled
+	}
+}
+
+// WithErrorDetail adds the underlying error text as "detail" to 500 responses,
+// with secret-looking URL parameters and JSON fields redacted. It is meant for
+// development; by default 500s carry only a generic message.
+func WithErrorDetail(enabled bool) HandlerOption {
+	return func(h *Handlers) {
+		h.errorDetail = enabled
+	}
+}
+
+// WithFetchOnMiss makes GetDestination fetch and store a city the DB doesn't
+// have, as a refresh would, instead of returning 404. Requests can still opt
+// out with ?fetch_on_miss=false, or opt in without it with ?fetch_on_miss=true.
+func WithFetchOnMiss(enabled bool) HandlerOption {
+	return func(h *Handlers) {
+		h.fetchOnMiss = enabled
+	}
+}
+
+// WithCacheConsistencyCheck makes GetDestination check, on each cache hit, when
+// the DB's copy was fetched, and serve the DB's copy instead if it is newer. This
+// costs a timestamp-only query per hit but stops a failed cache write from
+// leaving older data in the cache than in the DB until it expires.
+func WithCacheConsistencyCheck(enabled bool) HandlerOption {
+	return func(h *Handlers) {
+		h.consistencyCheck = enabled
+	}
+}
+
+// WithProviderFreshness makes RefreshDestination reuse a stored section, instead
+// of calling its provider again, while it is younger than freshFor[provider]. A
+// provider missing from freshFor, or with zero, is always called; so is every
+// provider for a city not yet stored. Reading the stored record costs a DB query
+// per refresh, so it is only done when some period is set.
+func WithProviderFreshness(freshFor map[string]time.Duration) HandlerOption {
+	return func(h *Handlers) {
+		h.freshFor = make(map[string]time.Duration, len(freshFor))
+		for p, d := range freshFor {
+			if d > 0 {
+				h.freshFor[p] = d
+			}
+		}
+	}
+}
+
+// WithRefreshReturnsStored makes a refresh re-read the record it just stored and
+// return (and cache) that, rather than the data as fetched, so the response is
+// what a later GET serves. It costs a DB query per refresh; if the re-read fails,
+// the fetched data is returned instead.
+func WithRefreshReturnsStored(enabled bool) HandlerOption {
+	return func(h *Handlers) {
+		h.returnStored = enabled
+	}
+}
+
+// WithCountryKey tells handlers that destinations are keyed on city and country
+// (see storage.WithCountryKey). GetDestination then takes ?country= to pick among
+// same-named cities, and each country's copy is cached separately. Without a
+// country, a lookup gets the city's most recently updated record.
+func WithCountryKey(enabled bool) HandlerOption {
+	return func(h *Handlers) {
+		h.countryKey = enabled
+	}
+}
+
+// WithNotFoundError sets the body GetDestination returns for a city with no stored
+// data. An empty message or code keeps the one from DefaultNotFoundError.
+func WithNotFoundError(e APIError) HandlerOption {
+	return func(h *Handlers) {
+		if e.Message != "" {
+			h.notFound.Message = e.Message
+		}
+		if e.Code != "" {
+			h.notFound.Code = e.Code
+		}
+	}
+}
+
+// WithTimestampFormat sets how the envelope's meta.fetched_at and the full
+// record's timestamps are written. The default is TimestampRFC3339; an unknown
+// format keeps it.
+func WithTimestampFormat(format string) HandlerOption {
+	return func(h *Handlers) {
+		if format == TimestampRFC3339 || format == TimestampUnix {
+			h.timestampFormat = format
+		}
+	}
+}
+
+// defaultMaxPathLength is the longest request path NewRouter accepts unless
+// WithMaxPathLength overrides it.
+const defaultMaxPathLength = 2048
+
+// defaultRequestTimeout is the deadline NewRouter gives each request unless
+// WithRequestTimeout or the X-Request-Timeout header overrides it.
+const defaultRequestTimeout = 10 * time.Second
+
+// Default per-IP rate limit used by NewRouter unless WithRateLimit overrides it.
+const (
+	defaultRatePerMinute = 60
+	defaultRateBurst     = 20
+)
+
+// routerConfig holds optional router settings applied by RouterOption.
+type routerConfig struct {
+	trustedProxies []*net.IPNet
+	metrics        *metrics.Metrics
+	adminToken     string
+	ratePerMinute  int
+	rateBurst      int
+	rateLimit      *RateLimitVar
+	rateDisabled   bool
+	apiVersion     APIVersion
+	health         HealthSeverities
+	healthChecks   []HealthCheck
+	logBodiesMax   int
+	rateStore      RateLimitStore
+	rateFallback   bool
+	maxPathLength  int
+	requestTimeout time.Duration
+	accessLog      string
+}
+
+// RouterOption configures optional NewRouter behaviour.
+type RouterOption func(*routerConfig)
+
+// WithTrustedProxies makes the router take the client IP from proxy headers
+// (X-Forwarded-For, X-Real-IP) when the direct peer is in one of the given networks.
+// Rate limiting and logging then see the real client instead of the proxy.
+func WithTrustedProxies(networks []*net.IPNet) RouterOption {
+	return func(c *routerConfig) {
+		c.trustedProxies = networks
+	}
+}
+
+// WithMetrics instruments the router with m and serves it on GET /metrics.
+func WithMetrics(m *metrics.Metrics) RouterOption {
+	return func(c *routerConfig) {
+		c.metrics = m
+	}
+}
+
+// WithAdminToken mounts the /api/v1/admin routes, protected by a bearer token
+// separate from the regular API token. Without it the admin routes are not served.
+func WithAdminToken(token string) RouterOption {
+	return func(c *routerConfig) {
+		c.adminToken = token
+	}
+}
+
+// WithRateLimit sets the per-IP token bucket: perMinute sustained requests per
+// minute, with up to burst requests allowed at once. Non-positive values keep the default.
+func WithRateLimit(perMinute, burst int) RouterOption {
+	return func(c *routerConfig) {
+		if perMinute > 0 {
+			c.ratePerMinute = perMinute
+		}
+		if burst > 0 {
+			c.rateBurst = burst
+		}
+	}
+}
+
+// WithRateLimitVar makes the per-IP token bucket follow v instead of the limit
+// set by WithRateLimit, so it can be changed with v.Set while the router serves.
+func WithRateLimitVar(v *RateLimitVar) RouterOption {
+	return func(c *routerConfig) {
+		c.rateLimit = v
+	}
+}
+
+// WithRateLimitEnabled turns per-IP rate limiting on or off. It is on by default;
+// turned off, the limiting middleware and the admin rate limit status endpoint
+// are left out of the router, for deployments already limited by a gateway.
+func WithRateLimitEnabled(enabled bool) RouterOption {
+	return func(c *routerConfig) {
+		c.rateDisabled = !enabled
+	}
+}
+
+// WithDefaultAPIVersion sets the version responses are written in for requests
+// without an Accept-Version header. The default is APIVersion1; unknown
+// versions are ignored.
+func WithDefaultAPIVersion(v APIVersion) RouterOption {
+	return func(c *routerConfig) {
+		if v >= APIVersion1 && v <= APIVersion2 {
+			c.apiVersion = v
+		}
+	}
+}
+
+// WithHealthChecks adds checks to the health endpoint, after the default DB and
+// Redis pings.
+func WithHealthChecks(checks ...HealthCheck) RouterOption {
+	return func(c *routerConfig) {
+		c.healthChecks = append(c.healthChecks, checks...)
+	}
+}
+
+// WithHealthSeverities sets how each failing dependency affects the health endpoint.
+// Empty fields keep their default (see DefaultHealthSeverities).
+func WithHealthSeverities(s HealthSeverities) RouterOption {
+	return func(c *routerConfig) {
+		if s.DB != "" {
+			c.health.DB = s.DB
+		}
+		if s.Redis != "" {
+			c.health.Redis = s.Redis
+		}
+	}
+}
+
+// WithMaxPathLength sets the longest request path, in bytes, the router accepts;
+// longer ones get 414. Non-positive values keep the default of 2048.
+func WithMaxPathLength(n int) RouterOption {
+	return func(c *routerConfig) {
+		if n > 0 {
+			c.maxPathLength = n
+		}
+	}
+}
+
+// WithRequestTimeout sets the deadline each request's context gets when the
+// caller sends no X-Request-Timeout header. It is clamped to the range the
+// header accepts; zero keeps the default of 10s.
+func WithRequestTimeout(d time.Duration) RouterOption {
+	return func(c *routerConfig) {
+		if d > 0 {
+			c.requestTimeout = min(max(d, MinRequestTimeout), MaxRequestTimeout)
+		}
+	}
+}
+
+// WithSharedRateLimit keeps the per-IP buckets in store instead of in memory,
+// so the rate limit is enforced across all instances. If fallback is set,
+// in-memory buckets take over while the store is unavailable; otherwise
+// requests go unlimited until it recovers.
+func WithSharedRateLimit(store RateLimitStore, fallback bool) RouterOption {
+	return func(c *routerConfig) {
+		c.rateStore = store
+		c.rateFallback = fallback
+	}
+}
+
+// WithAccessLog logs every request in format (see AccessLog). Combined Log
+// Format lines are written to standard output. Off unless format is set.
+func WithAccessLog(format string) RouterOption {
+	return func(c *routerConfig) {
+		c.accessLog = format
+	}
+}
+
+// WithBodyLogging logs the first maxBytes of every write request's body, redacted
+// (see LogBodies). For debugging client integrations; off unless maxBytes > 0.
+func WithBodyLogging(maxBytes int) RouterOption {
+	return func(c *routerConfig) {
+		c.logBodiesMax = maxBytes
+	}
+}
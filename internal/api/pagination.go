@@ -0,0 +1,65 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/neexbeast/ygo-test/internal/destination"
+)
+
+// Page sizes used by list endpoints unless WithPageSizes overrides them.
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// Snapshot counts returned by the history endpoint unless WithHistorySizes
+// overrides them.
+const (
+	defaultHistorySize = 10
+	maxHistorySize     = 100
+)
+
+// PageSizes controls pagination for every list endpoint: Default items are
+// returned when the client gives no limit, and no more than Max in any case.
+type PageSizes struct {
+	Default int
+	Max     int
+}
+
+// parsePagination reads ?limit and ?offset from r. A missing or zero limit gets
+// sizes.Default and a limit above sizes.Max is clamped to it; negative or
+// non-integer values are an error, to be reported as 400.
+func parsePagination(r *http.Request, sizes PageSizes) (destination.Page, error) {
+	limit, err := parseLimit(r, sizes)
+	if err != nil {
+		return destination.Page{}, err
+	}
+	page := destination.Page{Limit: limit}
+
+	if v := r.URL.Query().Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return destination.Page{}, errors.New("offset must be a non-negative integer")
+		}
+		page.Offset = n
+	}
+	return page, nil
+}
+
+// parseLimit reads ?limit from r the way parsePagination does, for endpoints
+// that return only the first page.
+func parseLimit(r *http.Request, sizes PageSizes) (int, error) {
+	limit := sizes.Default
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, errors.New("limit must be a non-negative integer")
+		}
+		if n > 0 {
+			limit = n
+		}
+	}
+	return min(limit, sizes.Max), nil
+}
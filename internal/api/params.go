@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// strictParam opts a single request into strict query parameter checking. It is
+// accepted by every handler.
+const strictParam = "strict"
+
+// unknownParamsResponse is the 400 body listing query parameters a handler does not take.
+type unknownParamsResponse struct {
+	Error   string   `json:"error"`
+	Unknown []string `json:"unknown"`
+}
+
+// strict reports whether r's query parameters are checked: always with
+// WithStrictParams, otherwise only when the request passes ?strict=true.
+func (h *Handlers) strict(r *http.Request) bool {
+	if h.strictParams {
+		return true
+	}
+	v, _ := strconv.ParseBool(r.URL.Query().Get(strictParam))
+	return v
+}
+
+// checkParams is called first by each handler with the query parameters it
+// reads. In strict mode, a request carrying any other parameter gets a 400
+// listing them, so typos like ?contry= are caught instead of silently ignored;
+// it then returns false and the handler must stop. Outside strict mode it
+// always returns true.
+func (h *Handlers) checkParams(w http.ResponseWriter, r *http.Request, allowed ...string) bool {
+	if !h.strict(r) {
+		return true
+	}
+
+	var unknown []string
+	for name := range r.URL.Query() {
+		if name != strictParam && !slices.Contains(allowed, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return true
+	}
+
+	slices.Sort(unknown)
+	writeJSON(w, http.StatusBadRequest, unknownParamsResponse{
+		Error:   "unknown query parameters: " + strings.Join(unknown, ", "),
+		Unknown: unknown,
+	})
+	return false
+}
@@ -0,0 +1,312 @@
+package api
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/time/rate"
+)
+
+// maxTrackedClients bounds how many per-IP buckets RateLimitByIP keeps in memory.
+const maxTrackedClients = 10000
+
+// RateLimitVar is a per-IP rate limit that can be changed while the router
+// serves, as slog.LevelVar does for a log level. Create one with NewRateLimitVar.
+type RateLimitVar struct {
+	v atomic.Pointer[rateLimit]
+}
+
+// rateLimit is a token bucket's shape: perMinute requests per minute sustained,
+// with up to burst at once.
+type rateLimit struct {
+	perMinute int
+	burst     int
+}
+
+// NewRateLimitVar returns a RateLimitVar allowing perMinute requests per minute
+// with bursts of up to burst.
+func NewRateLimitVar(perMinute, burst int) *RateLimitVar {
+	v := &RateLimitVar{}
+	v.Set(perMinute, burst)
+	return v
+}
+
+// Set changes the limit for every request from now on. Buckets in memory keep
+// the tokens they have, up to the new burst.
+func (v *RateLimitVar) Set(perMinute, burst int) {
+	v.v.Store(&rateLimit{perMinute: perMinute, burst: burst})
+}
+
+// Get returns the current limit.
+func (v *RateLimitVar) Get() (perMinute, burst int) {
+	l := v.load()
+	return l.perMinute, l.burst
+}
+
+// load returns the current limit as one value, so a request never mixes the
+// rate of one limit with the burst of another.
+func (v *RateLimitVar) load() rateLimit {
+	return *v.v.Load()
+}
+
+// ipBuckets is a bounded LRU of token buckets keyed by client IP.
+// When full, the least recently seen IP is evicted; if it returns it starts
+// with a full bucket again, which only ever errs on the side of allowing.
+type ipBuckets struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List // front = most recently used
+	entries map[string]*list.Element
+}
+
+type ipBucket struct {
+	ip      string
+	limiter *rate.Limiter
+}
+
+func newIPBuckets(maxClients int) *ipBuckets {
+	return &ipBuckets{
+		max:     maxClients,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// get returns the limiter for ip, creating it and evicting the oldest entry as
+// needed. An existing limiter is brought up to date with l.
+func (b *ipBuckets) get(ip string, l rateLimit) *rate.Limiter {
+	limit := perSecond(l.perMinute)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if el, ok := b.entries[ip]; ok {
+		b.order.MoveToFront(el)
+		lim := el.Value.(*ipBucket).limiter
+		if lim.Limit() != limit {
+			lim.SetLimit(limit)
+		}
+		if lim.Burst() != l.burst {
+			lim.SetBurst(l.burst)
+		}
+		return lim
+	}
+
+	if b.order.Len() >= b.max {
+		oldest := b.order.Back()
+		b.order.Remove(oldest)
+		delete(b.entries, oldest.Value.(*ipBucket).ip)
+	}
+
+	lim := rate.NewLimiter(limit, l.burst)
+	b.entries[ip] = b.order.PushFront(&ipBucket{ip: ip, limiter: lim})
+	return lim
+}
+
+// state reports ip's bucket under l without taking from it. An IP that is not
+// tracked has a full bucket.
+func (b *ipBuckets) state(ip string, l rateLimit) RateLimitState {
+	b.mu.Lock()
+	el, ok := b.entries[ip]
+	b.mu.Unlock()
+	if !ok {
+		return RateLimitState{Remaining: l.burst}
+	}
+
+	tokens := min(el.Value.(*ipBucket).limiter.TokensAt(time.Now()), float64(l.burst))
+	missing := float64(l.burst) - tokens
+	return RateLimitState{
+		Remaining: max(0, int(math.Floor(tokens))),
+		ResetIn:   time.Duration(missing / float64(perSecond(l.perMinute)) * float64(time.Second)),
+	}
+}
+
+// perSecond converts a per-minute rate to a rate.Limit.
+func perSecond(perMinute int) rate.Limit {
+	return rate.Limit(float64(perMinute) / 60)
+}
+
+// clientIP returns the host part of RemoteAddr, which TrustedRealIP may already
+// have replaced with the forwarded client address.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// reserve takes one request from ip's bucket, returning zero if it is allowed
+// or how long to wait before retrying if it is not. A rejected request does not
+// consume a token.
+func (b *ipBuckets) reserve(ip string, l rateLimit) time.Duration {
+	now := time.Now()
+	res := b.get(ip, l).ReserveN(now, 1)
+	if delay := res.DelayFrom(now); !res.OK() || delay > 0 {
+		res.CancelAt(now)
+		return delay
+	}
+	return 0
+}
+
+// limitRequests rejects a request with 429 and a Retry-After header whenever
+// reserve reports a wait, and passes it on otherwise.
+func limitRequests(reserve func(r *http.Request) time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if delay := reserve(r); delay > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+				writeJSON(w, http.StatusTooManyRequests, map[string]string{"error": "rate limit exceeded"})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RateLimitState is a client's rate limit bucket at one moment: how many
+// requests it may make at once, and how long until its bucket is full again.
+type RateLimitState struct {
+	Remaining int
+	ResetIn   time.Duration
+}
+
+// errRateLimitStoreDown is returned by ipRateLimiter.state when the shared store
+// is failing and there is no in-memory fallback to report instead.
+var errRateLimitStoreDown = errors.New("rate limit store unavailable")
+
+// ipRateLimiter is the per-IP limit, kept in memory or, if store is set, in a
+// shared store with the in-memory buckets as the fallback.
+type ipRateLimiter struct {
+	limit    *RateLimitVar
+	local    *ipBuckets
+	store    RateLimitStore
+	fallback bool
+	failing  atomic.Bool
+	log      *slog.Logger
+}
+
+func newIPRateLimiter(limit *RateLimitVar, maxClients int, store RateLimitStore, fallback bool, log *slog.Logger) *ipRateLimiter {
+	return &ipRateLimiter{
+		limit:    limit,
+		local:    newIPBuckets(maxClients),
+		store:    store,
+		fallback: fallback,
+		log:      log,
+	}
+}
+
+// reserve takes one request from the client's bucket, returning zero if it is
+// allowed or how long to wait if not.
+func (l *ipRateLimiter) reserve(r *http.Request) time.Duration {
+	ip := clientIP(r)
+	limit := l.limit.load()
+	if l.store == nil {
+		return l.local.reserve(ip, limit)
+	}
+
+	delay, err := l.store.Reserve(r.Context(), ip, limit.perMinute, limit.burst)
+	if err == nil {
+		l.storeUp()
+		return delay
+	}
+
+	l.storeDown(err)
+	if l.fallback {
+		return l.local.reserve(ip, limit)
+	}
+	return 0
+}
+
+// state reports ip's bucket from wherever reserve would take from it now.
+func (l *ipRateLimiter) state(ctx context.Context, ip string) (RateLimitState, error) {
+	limit := l.limit.load()
+	if l.store == nil {
+		return l.local.state(ip, limit), nil
+	}
+
+	remaining, resetIn, err := l.store.Peek(ctx, ip, limit.perMinute, limit.burst)
+	if err == nil {
+		return RateLimitState{Remaining: remaining, ResetIn: resetIn}, nil
+	}
+	if l.fallback {
+		return l.local.state(ip, limit), nil
+	}
+	return RateLimitState{}, fmt.Errorf("%w: %w", errRateLimitStoreDown, err)
+}
+
+func (l *ipRateLimiter) storeUp() {
+	if l.failing.CompareAndSwap(true, false) {
+		l.log.Info("rate limit store recovered")
+	}
+}
+
+func (l *ipRateLimiter) storeDown(err error) {
+	if l.failing.CompareAndSwap(false, true) {
+		l.log.Warn("rate limit store unavailable", "fallback", l.fallback, "err", err)
+	}
+}
+
+// RateLimitByIP returns token-bucket rate limiting middleware keyed by client IP.
+// Each IP may make burst requests at once, refilled at perMinute per minute, so
+// short bursts are tolerated while sustained traffic above the rate gets 429
+// with a Retry-After header. At most maxClients IPs are tracked at a time.
+func RateLimitByIP(perMinute, burst, maxClients int) func(http.Handler) http.Handler {
+	return limitRequests(newIPRateLimiter(NewRateLimitVar(perMinute, burst), maxClients, nil, false, nil).reserve)
+}
+
+// SharedRateLimitByIP is RateLimitByIP with the buckets kept in store, so the
+// limit holds across all instances rather than per instance. While the store is
+// failing, requests are limited by in-memory buckets if fallback is set and let
+// through unlimited otherwise; the outage and recovery are logged once each.
+func SharedRateLimitByIP(store RateLimitStore, perMinute, burst, maxClients int, fallback bool, log *slog.Logger) func(http.Handler) http.Handler {
+	return limitRequests(newIPRateLimiter(NewRateLimitVar(perMinute, burst), maxClients, store, fallback, log).reserve)
+}
+
+// rateLimitResponse is the body returned by the rate limit inspection endpoint.
+type rateLimitResponse struct {
+	IP        string    `json:"ip"`
+	PerMinute int       `json:"per_minute"`
+	Burst     int       `json:"burst"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+// rateLimitStatusHandler handles GET /api/v1/admin/ratelimit/{ip}, reporting
+// the IP's remaining requests and when its bucket will be full again, without
+// taking from it.
+func rateLimitStatusHandler(l *ipRateLimiter, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ip := chi.URLParam(r, "ip")
+		if net.ParseIP(ip) == nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid IP address"})
+			return
+		}
+
+		st, err := l.state(r.Context(), ip)
+		if err != nil {
+			log.Warn("rate limit inspection failed", "ip", ip, "err", err)
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": errRateLimitStoreDown.Error()})
+			return
+		}
+
+		perMinute, burst := l.limit.Get()
+		writeJSON(w, http.StatusOK, rateLimitResponse{
+			IP:        ip,
+			PerMinute: perMinute,
+			Burst:     burst,
+			Remaining: st.Remaining,
+			ResetAt:   time.Now().UTC().Add(st.ResetIn).Truncate(time.Second),
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Timestamp formats: how response metadata and record timestamps are written.
+const (
+	// TimestampRFC3339 writes timestamps as RFC 3339 strings in UTC, to the second.
+	TimestampRFC3339 = "rfc3339"
+	// TimestampUnix writes timestamps as whole seconds since the Unix epoch.
+	TimestampUnix = "unix"
+)
+
+// timestamp is a time that encodes to JSON in its format.
+type timestamp struct {
+	t      time.Time
+	format string
+}
+
+// MarshalJSON implements json.Marshaler.
+func (ts timestamp) MarshalJSON() ([]byte, error) {
+	if ts.format == TimestampUnix {
+		return strconv.AppendInt(nil, ts.t.Unix(), 10), nil
+	}
+	return json.Marshal(ts.t.UTC().Format(time.RFC3339))
+}
+
+// newTimestamp returns t as a timestamp in format, or nil if t is nil.
+func newTimestamp(t *time.Time, format string) *timestamp {
+	if t == nil {
+		return nil
+	}
+	return &timestamp{t: *t, format: format}
+}
+
+// responseMeta describes where a successful response's data came from.
+type responseMeta struct {
+	RequestID string
+	Cached    bool
+	FetchedAt *time.Time
+	// format is how FetchedAt is written; respond sets it.
+	format string
+}
+
+// MarshalJSON implements json.Marshaler, writing FetchedAt in the meta's format.
+func (m responseMeta) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		RequestID string     `json:"request_id,omitempty"`
+		Cached    bool       `json:"cached"`
+		FetchedAt *timestamp `json:"fetched_at,omitempty"`
+	}{m.RequestID, m.Cached, newTimestamp(m.FetchedAt, m.format)})
+}
+
+// envelope wraps a response body with metadata when the client asks for it.
+type envelope struct {
+	Data any          `json:"data"`
+	Meta responseMeta `json:"meta"`
+}
+
+// wantsEnvelope reports whether the request gets the {data, meta} envelope:
+// always under APIVersion2, and under APIVersion1 when it opts in with ?envelope=true.
+func wantsEnvelope(r *http.Request) bool {
+	if requestAPIVersion(r) >= APIVersion2 {
+		return true
+	}
+	v, _ := strconv.ParseBool(r.URL.Query().Get("envelope"))
+	return v
+}
+
+// respond writes a 200 response with body. When the request wants the envelope
+// (see wantsEnvelope) the body is wrapped as {data, meta} with the request ID
+// filled into meta.
+func (h *Handlers) respond(w http.ResponseWriter, r *http.Request, body any, meta responseMeta) {
+	if !wantsEnvelope(r) {
+		writeJSON(w, http.StatusOK, body)
+		return
+	}
+
+	meta.RequestID = middleware.GetReqID(r.Context())
+	meta.format = h.timestampFormat
+	writeJSON(w, http.StatusOK, envelope{Data: body, Meta: meta})
+}
@@ -3,29 +3,99 @@ package api
 import (
 	"log/slog"
 	"net/http"
-	"time"
+	"os"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/httprate"
 )
 
 // NewRouter builds and returns the Chi router with all routes configured.
 // The health endpoint is unauthenticated; all destination routes require bearer auth.
-// Rate limiting is applied globally: 60 requests per minute per IP.
-func NewRouter(handlers *Handlers, token string, db dbPinger, redisClient redisPinger, log *slog.Logger) *chi.Mux {
+// Admin routes are mounted only with WithAdminToken and require that token instead.
+// Rate limiting is applied globally per IP: by default 60 requests per minute
+// with bursts of up to 20 (see WithRateLimit and WithRateLimitVar), kept in memory
+// unless WithSharedRateLimit is set.
+// WithRateLimitEnabled(false) turns it off. The Accept-Version header picks
+// the response shape (see APIVersion), defaulting to WithDefaultAPIVersion.
+func NewRouter(handlers *Handlers, token string, db dbPinger, redisClient redisPinger, log *slog.Logger, opts ...RouterOption) *chi.Mux {
+	cfg := routerConfig{
+		ratePerMinute:  defaultRatePerMinute,
+		rateBurst:      defaultRateBurst,
+		health:         DefaultHealthSeverities,
+		maxPathLength:  defaultMaxPathLength,
+		requestTimeout: defaultRequestTimeout,
+		apiVersion:     APIVersion1,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	r := chi.NewRouter()
 
 	r.Use(middleware.Recoverer)
+	r.Use(MaxPathLength(cfg.maxPathLength))
 	r.Use(middleware.RequestID)
-	r.Use(httprate.LimitByIP(60, time.Minute))
+	if len(cfg.trustedProxies) > 0 {
+		r.Use(TrustedRealIP(cfg.trustedProxies))
+	}
+	if cfg.accessLog != "" {
+		r.Use(AccessLog(cfg.accessLog, log, os.Stdout))
+	}
+	var limiter *ipRateLimiter
+	if !cfg.rateDisabled {
+		limit := cfg.rateLimit
+		if limit == nil {
+			limit = NewRateLimitVar(cfg.ratePerMinute, cfg.rateBurst)
+		}
+		limiter = newIPRateLimiter(limit, maxTrackedClients, cfg.rateStore, cfg.rateFallback, log)
+		r.Use(limitRequests(limiter.reserve))
+	}
+	if cfg.logBodiesMax > 0 {
+		r.Use(LogBodies(log, cfg.logBodiesMax))
+	}
+	r.Use(RequestTimeout(cfg.requestTimeout))
+	r.Use(AcceptVersion(cfg.apiVersion))
 
-	r.Get("/api/v1/health", HealthHandlerFunc(db, redisClient, log))
+	if cfg.metrics != nil {
+		r.Method(http.MethodGet, "/metrics", cfg.metrics.Handler())
+	}
 
+	// Routes are grouped so in-flight tracking runs after routing and sees the pattern.
 	r.Group(func(r chi.Router) {
-		r.Use(BearerAuth(token))
-		r.Get("/api/v1/destinations/{city}", handlers.GetDestination)
-		r.Post("/api/v1/destinations/{city}/refresh", handlers.RefreshDestination)
+		if cfg.metrics != nil {
+			r.Use(InFlight(cfg.metrics))
+		}
+
+		r.Get("/api/v1/health", HealthHandlerFunc(append(DefaultHealthChecks(db, redisClient, cfg.health), cfg.healthChecks...), log))
+
+		r.Group(func(r chi.Router) {
+			r.Use(BearerAuth(token))
+			r.Get("/api/v1/destinations", handlers.FilterByQuality)
+			r.Get("/api/v1/destinations/fts", handlers.SearchDestinations)
+			r.Get("/api/v1/regions", handlers.ListRegions)
+			r.Get("/api/v1/countries", handlers.ListCountries)
+			r.Get("/api/v1/destinations/{city}", handlers.GetDestination)
+			r.Post("/api/v1/destinations/{city}/refresh", handlers.RefreshDestination)
+			r.Get("/api/v1/destinations/{city}/history", handlers.GetHistory)
+		})
+
+		// Callers with either token may delete; admin deletes are marked in the audit log.
+		r.With(BearerOrAdminAuth(token, cfg.adminToken)).Delete("/api/v1/destinations/{city}", handlers.DeleteDestination)
+
+		if cfg.adminToken != "" {
+			r.Group(func(r chi.Router) {
+				r.Use(BearerAuth(cfg.adminToken))
+				r.Get("/api/v1/admin/repair", handlers.ListIncomplete)
+				if limiter != nil {
+					r.Get("/api/v1/admin/ratelimit/{ip}", rateLimitStatusHandler(limiter, log))
+				}
+				r.Get("/api/v1/admin/providers/{name}/probe", handlers.ProbeProvider)
+				r.Delete("/api/v1/destinations", handlers.BulkDelete)
+				r.Delete("/api/v1/admin/cache", handlers.InvalidateCache)
+				r.Get("/api/v1/destinations/{city}/full", handlers.GetFullDestination)
+				r.Post("/api/v1/admin/destinations/{city}/diff", handlers.DiffDestination)
+			})
+		}
 	})
 
 	return r
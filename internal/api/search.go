@@ -0,0 +1,113 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/neexbeast/ygo-test/internal/destination"
+)
+
+// searchResult is one destination in a search response.
+type searchResult struct {
+	City    string                       `json:"city"`
+	Country string                       `json:"country"`
+	Data    *destination.DestinationData `json:"data"`
+}
+
+// searchResponse is the body returned by SearchDestinations.
+type searchResponse struct {
+	Results []searchResult `json:"results"`
+}
+
+// SearchDestinations handles GET /api/v1/destinations/fts?q=...
+// Matches q against city and country names, most relevant first. Every word in q must match.
+// Results are paginated with ?limit and ?offset.
+func (h *Handlers) SearchDestinations(w http.ResponseWriter, r *http.Request) {
+	if !h.checkParams(w, r, "q", "limit", "offset") {
+		return
+	}
+	varyLanguage(w)
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "query parameter q is required"})
+		return
+	}
+	page, err := parsePagination(r, h.pageSizes)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	dests, err := h.repo.FullTextSearch(r.Context(), q, page)
+	if err != nil {
+		h.log.Error("full-text search failed", "q", q, "err", err)
+		h.writeServerError(w, "internal server error", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, searchResults(r, dests))
+}
+
+// searchResults builds the response body listing dests.
+func searchResults(r *http.Request, dests []*destination.Destination) searchResponse {
+	results := make([]searchResult, 0, len(dests))
+	for _, d := range dests {
+		results = append(results, searchResult{City: d.City, Country: d.Country, Data: localize(r, &d.Data)})
+	}
+	return searchResponse{Results: results}
+}
+
+// parseQualityFilter parses a min_quality value of the form "Category:score",
+// e.g. "Safety:6". The score must be a number from 0 to 10.
+func parseQualityFilter(s string) (destination.QualityFilter, error) {
+	category, value, ok := strings.Cut(s, ":")
+	category = strings.TrimSpace(category)
+	if !ok || category == "" {
+		return destination.QualityFilter{}, errors.New("min_quality must look like Category:score, e.g. Safety:6")
+	}
+	score, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || math.IsNaN(score) || score < 0 || score > 10 {
+		return destination.QualityFilter{}, errors.New("min_quality score must be a number from 0 to 10")
+	}
+	return destination.QualityFilter{Category: category, Min: score}, nil
+}
+
+// FilterByQuality handles GET /api/v1/destinations?min_quality=Category:score.
+// Lists stored destinations scoring at least score (out of 10) in the named
+// quality category, by city. Results are paginated with ?limit and ?offset.
+// The response carries a collection ETag; a matching If-None-Match gets 304.
+func (h *Handlers) FilterByQuality(w http.ResponseWriter, r *http.Request) {
+	if !h.checkParams(w, r, "min_quality", "limit", "offset") {
+		return
+	}
+	v := r.URL.Query().Get("min_quality")
+	if v == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "query parameter min_quality is required"})
+		return
+	}
+	filter, err := parseQualityFilter(v)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	page, err := parsePagination(r, h.pageSizes)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if h.notModified(w, r) {
+		return
+	}
+
+	dests, err := h.repo.FindByMinQuality(r.Context(), filter, page)
+	if err != nil {
+		h.log.Error("quality filter failed", "category", filter.Category, "min", filter.Min, "err", err)
+		h.writeServerError(w, "internal server error", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, searchResults(r, dests))
+}
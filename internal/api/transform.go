@@ -0,0 +1,215 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/neexbeast/ygo-test/internal/destination"
+)
+
+// qualityMapResponse is destination data with quality scores flattened to name → score.
+// The outer QualityScores field shadows the embedded array form when encoded.
+type qualityMapResponse struct {
+	*destination.DestinationData
+	QualityScores map[string]float64 `json:"quality_scores,omitempty"`
+}
+
+// wantsQualityMap reports whether the request asked for ?quality_format=map.
+// Any other value keeps the default array form.
+func wantsQualityMap(r *http.Request) bool {
+	return r.URL.Query().Get("quality_format") == "map"
+}
+
+// qualityMap converts quality scores to a name → score object. If a name repeats, the last score wins.
+func qualityMap(scores []destination.QualityScore) map[string]float64 {
+	if len(scores) == 0 {
+		return nil
+	}
+	m := make(map[string]float64, len(scores))
+	for _, s := range scores {
+		m[s.Name] = s.ScoreOutOf
+	}
+	return m
+}
+
+// explicitResponse is destination data with no field omitted: missing sections are
+// null and empty collections are [] or {}, for clients that validate a fixed schema.
+// Struct tags are static, so this mirrors DestinationData without its omitempty;
+// keep the two in step.
+type explicitResponse struct {
+	Weather       *destination.WeatherData   `json:"weather"`
+	PointsOfInt   []destination.POI          `json:"points_of_interest"`
+	Country       *destination.CountryData   `json:"country"`
+	QualityScores any                        `json:"quality_scores"`
+	ExchangeRates map[string]float64         `json:"exchange_rates"`
+	Alerts        []destination.WeatherAlert `json:"alerts"`
+}
+
+// keepsEmpty reports whether the request asked for ?omit_empty=false.
+func keepsEmpty(r *http.Request) bool {
+	omit, err := strconv.ParseBool(r.URL.Query().Get("omit_empty"))
+	return err == nil && !omit
+}
+
+// explicit converts data to its explicitResponse form. quality is the quality
+// scores already in the requested format.
+func explicit(data *destination.DestinationData, quality any) explicitResponse {
+	resp := explicitResponse{
+		Weather:       data.Weather,
+		PointsOfInt:   nonNilSlice(data.PointsOfInt),
+		Country:       data.Country,
+		QualityScores: quality,
+		ExchangeRates: nonNilMap(data.ExchangeRates),
+		Alerts:        nonNilSlice(data.Alerts),
+	}
+	switch q := quality.(type) {
+	case []destination.QualityScore:
+		resp.QualityScores = nonNilSlice(q)
+	case map[string]float64:
+		resp.QualityScores = nonNilMap(q)
+	}
+	if data.Country != nil {
+		country := *data.Country
+		country.Currencies = nonNilMap(country.Currencies)
+		country.Languages = nonNilSlice(country.Languages)
+		resp.Country = &country
+	}
+	return resp
+}
+
+// nonNilSlice returns s, or an empty slice (encoded as []) when s is nil.
+func nonNilSlice[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+// nonNilMap returns m, or an empty map (encoded as {}) when m is nil.
+func nonNilMap[K comparable, V any](m map[K]V) map[K]V {
+	if m == nil {
+		return map[K]V{}
+	}
+	return m
+}
+
+// sectionSources maps each top-level DestinationData key to the source that fills it.
+var sectionSources = map[string]string{
+	"weather":            destination.ProviderWeather,
+	"points_of_interest": destination.ProviderPOI,
+	"country":            destination.ProviderCountry,
+	"quality_scores":     destination.ProviderTeleport,
+	"exchange_rates":     destination.SupplementExchange,
+	"alerts":             destination.SupplementAlerts,
+}
+
+// groupedResponse is destination data with each section under the name of the
+// source that provided it, e.g. {"providers": {"weather": {...}, "poi": [...]}}.
+type groupedResponse struct {
+	Providers map[string]json.RawMessage `json:"providers"`
+}
+
+// wantsGrouped reports whether the request asked for ?layout=grouped.
+// Any other value keeps the default flat layout.
+func wantsGrouped(r *http.Request) bool {
+	return r.URL.Query().Get("layout") == "grouped"
+}
+
+// grouped regroups sections, the encoded top-level sections of destination data, by source.
+func grouped(sections map[string]json.RawMessage) groupedResponse {
+	resp := groupedResponse{Providers: make(map[string]json.RawMessage, len(sections))}
+	for key, section := range sections {
+		source, ok := sectionSources[key]
+		if !ok {
+			source = key
+		}
+		resp.Providers[source] = section
+	}
+	return resp
+}
+
+// parseFields reads ?fields=weather,country: the top-level sections to return.
+// It returns nil when the parameter is absent, meaning every section, and an
+// error naming any section that does not exist.
+func parseFields(r *http.Request) ([]string, error) {
+	v := r.URL.Query().Get("fields")
+	if v == "" {
+		return nil, nil
+	}
+
+	var fields, unknown []string
+	for _, f := range strings.Split(v, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if _, ok := sectionSources[f]; !ok {
+			unknown = append(unknown, f)
+			continue
+		}
+		fields = append(fields, f)
+	}
+	if len(unknown) > 0 {
+		return nil, errors.New("unknown fields: " + strings.Join(unknown, ", "))
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("fields must name at least one section")
+	}
+	return fields, nil
+}
+
+// present applies the request's response transformations (localization, quality
+// score format, empty field handling, layout) to data, keeps only the sections
+// in fields unless it is nil, and returns the value to encode.
+//
+// Projection and grouping work on the encoded sections, so they compose with
+// every other transformation: an omitted section stays omitted and a null one
+// stays null. If the data cannot be encoded it is returned untransformed, to
+// fail the same way when written.
+func present(r *http.Request, data *destination.DestinationData, fields []string) any {
+	flat := presentFlat(r, data)
+	if fields == nil && !wantsGrouped(r) {
+		return flat
+	}
+
+	raw, err := json.Marshal(flat)
+	if err != nil {
+		return flat
+	}
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &sections); err != nil {
+		return flat
+	}
+
+	if fields != nil {
+		for key := range sections {
+			if !slices.Contains(fields, key) {
+				delete(sections, key)
+			}
+		}
+	}
+	if wantsGrouped(r) {
+		return grouped(sections)
+	}
+	return sections
+}
+
+// presentFlat is present for the default flat layout.
+func presentFlat(r *http.Request, data *destination.DestinationData) any {
+	data = localize(r, data)
+	if keepsEmpty(r) {
+		var quality any = data.QualityScores
+		if wantsQualityMap(r) {
+			quality = qualityMap(data.QualityScores)
+		}
+		return explicit(data, quality)
+	}
+	if wantsQualityMap(r) {
+		return qualityMapResponse{DestinationData: data, QualityScores: qualityMap(data.QualityScores)}
+	}
+	return data
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// APIVersion is a response shape clients can ask for with the Accept-Version
+// header. Versions change how responses are written, not which routes exist.
+type APIVersion int
+
+const (
+	// APIVersion1 writes bare response bodies, wrapped in {data, meta} only
+	// with ?envelope=true.
+	APIVersion1 APIVersion = 1
+	// APIVersion2 always wraps responses that carry metadata in {data, meta}.
+	APIVersion2 APIVersion = 2
+)
+
+// acceptVersionHeader names the version a client wants responses written in.
+const acceptVersionHeader = "Accept-Version"
+
+// parseAPIVersion parses an Accept-Version value such as "2" or "v2".
+func parseAPIVersion(v string) (APIVersion, bool) {
+	n, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(v), "v"))
+	if err != nil || n < int(APIVersion1) || n > int(APIVersion2) {
+		return 0, false
+	}
+	return APIVersion(n), true
+}
+
+type apiVersionKey struct{}
+
+// AcceptVersion returns middleware that resolves the API version a request
+// asked for with Accept-Version, or def without the header, for handlers to
+// shape their responses by. An unknown version gets 400.
+func AcceptVersion(def APIVersion) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			version := def
+			if v := strings.TrimSpace(r.Header.Get(acceptVersionHeader)); v != "" {
+				parsed, ok := parseAPIVersion(v)
+				if !ok {
+					writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported " + acceptVersionHeader + " " + strconv.Quote(v) + ", use 1 or 2"})
+					return
+				}
+				version = parsed
+			}
+
+			w.Header().Add("Vary", acceptVersionHeader)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiVersionKey{}, version)))
+		})
+	}
+}
+
+// requestAPIVersion returns the version AcceptVersion resolved for r, or
+// APIVersion1 if it did not run.
+func requestAPIVersion(r *http.Request) APIVersion {
+	if v, ok := r.Context().Value(apiVersionKey{}).(APIVersion); ok {
+		return v
+	}
+	return APIVersion1
+}
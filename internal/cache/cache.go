@@ -1,10 +1,14 @@
 package cache
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/gob"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
@@ -13,28 +17,161 @@ import (
 	"github.com/neexbeast/ygo-test/internal/destination"
 )
 
-const defaultTTL = time.Hour
+const (
+	defaultTTL       = time.Hour
+	defaultScanCount = 100
+
+	// cacheSchemaVersion is part of every key. Bump it whenever DestinationData's
+	// shape changes incompatibly: entries written by older builds then become
+	// invisible (a miss) and are repopulated from the DB instead of being decoded
+	// into the wrong shape. Old-version keys simply expire with their TTL.
+	cacheSchemaVersion = "v2"
+	keyPrefix          = "destination:" + cacheSchemaVersion + ":"
+
+	// Country data is shared by every city in the country and rarely changes, so
+	// it is cached once per country, for longer than destinations.
+	countryKeyPrefix  = "country:"
+	defaultCountryTTL = 24 * time.Hour
+)
+
+// gzipMagic is the header every gzip stream starts with. JSON never starts with
+// these bytes, so Get uses them to tell compressed values from legacy plain JSON.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Value formats: how values are serialized in Redis.
+const (
+	// FormatJSON stores plain JSON, readable with any Redis client. The default.
+	FormatJSON = "json"
+	// FormatGob stores encoding/gob, smaller and cheaper to decode than JSON for
+	// large values, behind a one-byte marker.
+	FormatGob = "gob"
+)
+
+// gobMarker starts every gob value (before any compression). JSON values carry no
+// marker: they never start with this byte, so Get reads values written in either
+// format whatever the configured one, and switching formats needs no flush.
+const gobMarker byte = 0x01
 
 // Cache wraps a Redis client and provides typed get/set/delete for destination data.
 type Cache struct {
-	client *redis.Client
-	ttl    time.Duration
+	client      *redis.Client
+	ttl         time.Duration
+	compress    bool
+	touchOnRead bool
+	scanCount   int64
+	negativeTTL time.Duration
+	countryTTL  time.Duration
+	format      string
+	maxLifetime time.Duration
+}
+
+// Option configures optional Cache behaviour.
+type Option func(*Cache)
+
+// WithCompression makes Set gzip values before storing them.
+// Get always reads both compressed and uncompressed values.
+func WithCompression(enabled bool) Option {
+	return func(c *Cache) {
+		c.compress = enabled
+	}
+}
+
+// WithFormat sets the format Set writes values in. Values written in either format
+// are always readable, so it can be changed with entries of the other format still
+// cached. Unknown formats keep the default, FormatJSON.
+func WithFormat(f string) Option {
+	return func(c *Cache) {
+		if f == FormatGob {
+			c.format = f
+		}
+	}
+}
+
+// WithTouchOnRead makes every cache hit reset the key's TTL to the full duration,
+// so frequently read destinations stay cached. This changes eviction behaviour:
+// a key read at least once per TTL never expires on its own.
+func WithTouchOnRead(enabled bool) Option {
+	return func(c *Cache) {
+		c.touchOnRead = enabled
+	}
+}
+
+// WithMaxLifetime caps how long a value is served after Set stored it, however
+// often touch-on-read has extended its TTL, so hot keys still pick up DB changes.
+// The deadline is stored with the value and checked by Get, which treats a value
+// past it as a miss. Zero (the default) means no cap.
+func WithMaxLifetime(d time.Duration) Option {
+	return func(c *Cache) {
+		c.maxLifetime = max(d, 0)
+	}
+}
+
+// WithScanCount sets the COUNT hint passed to SCAN when enumerating cached keys.
+// Larger values mean fewer round trips on big keyspaces but longer individual
+// SCAN calls. Values below 1 keep the default of 100.
+func WithScanCount(n int) Option {
+	return func(c *Cache) {
+		if n > 0 {
+			c.scanCount = int64(n)
+		}
+	}
+}
+
+// WithNegativeTTL enables negative caching: SetNotFound records that a city has
+// no data for ttl, so repeated lookups of a missing city skip the DB. Zero (the
+// default) disables it and SetNotFound does nothing.
+func WithNegativeTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.negativeTTL = ttl
+	}
+}
+
+// WithCountryTTL sets how long SetCountry keeps country data. Values below 1
+// keep the default of 24 hours.
+func WithCountryTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		if ttl > 0 {
+			c.countryTTL = ttl
+		}
+	}
 }
 
 // NewCache constructs a Cache with a 1-hour TTL.
-func NewCache(client *redis.Client) *Cache {
-	return &Cache{client: client, ttl: defaultTTL}
+func NewCache(client *redis.Client, opts ...Option) *Cache {
+	c := &Cache{client: client, ttl: defaultTTL, scanCount: defaultScanCount, countryTTL: defaultCountryTTL, format: FormatJSON}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
-// key returns the Redis key for the given city.
-func key(city string) string {
-	return "destination:" + strings.ToLower(strings.TrimSpace(city))
+// CacheKey returns the canonical Redis key under which the given city is stored.
+// The city is trimmed and lowercased, so external tooling that pre-warms the
+// cache can compute the same key this package reads. The key includes the
+// cache schema version, so tooling should call this rather than hardcode it.
+func CacheKey(city string) string {
+	return keyPrefix + strings.ToLower(strings.TrimSpace(city))
 }
 
-// Get retrieves destination data from cache.
-// Returns nil, nil on a cache miss (not an error).
-func (c *Cache) Get(ctx context.Context, city string) (*destination.DestinationData, error) {
-	val, err := c.client.Get(ctx, key(city)).Result()
+// entry is the stored value: the data plus when it was fetched, so readers can tell its age.
+// A negative entry has NotFound set and no data. ExpiresAt, when set, is the
+// absolute deadline after which the entry is no longer served (see WithMaxLifetime).
+type entry struct {
+	FetchedAt time.Time                    `json:"fetched_at"`
+	Data      *destination.DestinationData `json:"data"`
+	NotFound  bool                         `json:"not_found,omitempty"`
+	ExpiresAt time.Time                    `json:"expires_at,omitzero"`
+}
+
+// Get retrieves destination data and its fetch time from cache.
+// Returns nil, nil on a cache miss (not an error), and an entry with NotFound
+// set when the city is known to have no data (see SetNotFound).
+// With touch-on-read enabled, a hit also resets the key's TTL; a not-found
+// marker keeps the rest of its negative TTL, so a missing city is still looked
+// up again once that runs out. A value past the deadline set by WithMaxLifetime
+// is a miss, touched or not.
+func (c *Cache) Get(ctx context.Context, city string) (*destination.CachedData, error) {
+	val, err := c.client.Get(ctx, CacheKey(city)).Bytes()
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
 			return nil, nil
@@ -42,36 +179,238 @@ func (c *Cache) Get(ctx context.Context, city string) (*destination.DestinationD
 		return nil, fmt.Errorf("cache get for city %s: %w", city, err)
 	}
 
-	var data destination.DestinationData
-	if err := json.Unmarshal([]byte(val), &data); err != nil {
+	if bytes.HasPrefix(val, gzipMagic) {
+		val, err = gunzip(val)
+		if err != nil {
+			return nil, fmt.Errorf("decompressing cached data for city %s: %w", city, err)
+		}
+	}
+
+	var e entry
+	if err := unmarshal(val, &e); err != nil {
 		return nil, fmt.Errorf("unmarshaling cached data for city %s: %w", city, err)
 	}
+	if !e.ExpiresAt.IsZero() && !time.Now().Before(e.ExpiresAt) {
+		return nil, nil
+	}
+	if e.NotFound {
+		return &destination.CachedData{NotFound: true}, nil
+	}
+	if e.Data == nil {
+		return nil, nil
+	}
 
-	return &data, nil
+	if c.touchOnRead {
+		// A failed touch only leaves the key on its original expiry, so the hit stands.
+		c.client.Expire(ctx, CacheKey(city), c.ttl)
+	}
+	return &destination.CachedData{Data: e.Data, FetchedAt: e.FetchedAt}, nil
 }
 
-// Set stores destination data in cache with the configured TTL.
-func (c *Cache) Set(ctx context.Context, city string, data *destination.DestinationData) error {
+// Set stores destination data, fetched from the providers at fetchedAt, in cache
+// with the configured TTL.
+func (c *Cache) Set(ctx context.Context, city string, data *destination.DestinationData, fetchedAt time.Time) error {
 	if data == nil {
 		return nil
 	}
 
-	b, err := json.Marshal(data)
+	e := entry{FetchedAt: fetchedAt.UTC(), Data: data}
+	if c.maxLifetime > 0 {
+		e.ExpiresAt = time.Now().Add(c.maxLifetime).UTC()
+	}
+	b, err := c.marshal(e)
 	if err != nil {
 		return fmt.Errorf("marshaling destination data for city %s: %w", city, err)
 	}
 
-	if err := c.client.Set(ctx, key(city), b, c.ttl).Err(); err != nil {
+	if c.compress {
+		b, err = gzipBytes(b)
+		if err != nil {
+			return fmt.Errorf("compressing destination data for city %s: %w", city, err)
+		}
+	}
+
+	if err := c.client.Set(ctx, CacheKey(city), b, c.ttl).Err(); err != nil {
 		return fmt.Errorf("cache set for city %s: %w", city, err)
 	}
 
 	return nil
 }
 
+// SetNotFound records that city has no data, for the negative TTL. It replaces any
+// cached data; the next Set for the city replaces the marker in turn. A no-op
+// unless negative caching is enabled with WithNegativeTTL.
+func (c *Cache) SetNotFound(ctx context.Context, city string) error {
+	if c.negativeTTL <= 0 {
+		return nil
+	}
+
+	b, err := c.marshal(entry{NotFound: true})
+	if err != nil {
+		return fmt.Errorf("marshaling not-found marker for city %s: %w", city, err)
+	}
+	if err := c.client.Set(ctx, CacheKey(city), b, c.negativeTTL).Err(); err != nil {
+		return fmt.Errorf("cache set not-found for city %s: %w", city, err)
+	}
+	return nil
+}
+
 // Delete removes the cached entry for the given city.
 func (c *Cache) Delete(ctx context.Context, city string) error {
-	if err := c.client.Del(ctx, key(city)).Err(); err != nil {
+	if err := c.client.Del(ctx, CacheKey(city)).Err(); err != nil {
 		return fmt.Errorf("cache delete for city %s: %w", city, err)
 	}
 	return nil
 }
+
+// countryKey returns the Redis key for a country name, trimmed and lowercased.
+func countryKey(country string) string {
+	return countryKeyPrefix + strings.ToLower(strings.TrimSpace(country))
+}
+
+// GetCountry retrieves cached country data by country name.
+// Returns nil, nil on a cache miss (not an error).
+func (c *Cache) GetCountry(ctx context.Context, country string) (*destination.CountryData, error) {
+	val, err := c.client.Get(ctx, countryKey(country)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cache get for country %s: %w", country, err)
+	}
+
+	var data destination.CountryData
+	if err := unmarshal(val, &data); err != nil {
+		return nil, fmt.Errorf("unmarshaling cached data for country %s: %w", country, err)
+	}
+	return &data, nil
+}
+
+// SetCountry stores country data under its name with the country TTL.
+func (c *Cache) SetCountry(ctx context.Context, country string, data *destination.CountryData) error {
+	if data == nil {
+		return nil
+	}
+
+	b, err := c.marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshaling data for country %s: %w", country, err)
+	}
+	if err := c.client.Set(ctx, countryKey(country), b, c.countryTTL).Err(); err != nil {
+		return fmt.Errorf("cache set for country %s: %w", country, err)
+	}
+	return nil
+}
+
+// Keys returns every destination key of the current schema version in the cache. It walks the keyspace
+// with SCAN in batches of the configured count rather than blocking Redis with KEYS.
+func (c *Cache) Keys(ctx context.Context) ([]string, error) {
+	var keys []string
+	iter := c.client.Scan(ctx, 0, keyPrefix+"*", c.scanCount).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("scanning cache keys: %w", err)
+	}
+	return keys, nil
+}
+
+// ErrEmptyPattern is returned by DeleteByPattern for an empty pattern, which
+// would otherwise drop every cached destination.
+var ErrEmptyPattern = errors.New("cache invalidation requires a non-empty pattern")
+
+// globEscaper escapes the characters SCAN MATCH treats as glob syntax.
+var globEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+// DeleteByPattern removes every cached destination whose city contains pattern,
+// case-insensitively, and returns how many entries were removed. Keys are found
+// with SCAN MATCH, never KEYS, and deleted a SCAN batch at a time. Pattern is
+// matched literally; glob characters in it have no special meaning.
+func (c *Cache) DeleteByPattern(ctx context.Context, pattern string) (int, error) {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "" {
+		return 0, ErrEmptyPattern
+	}
+
+	match := keyPrefix + "*" + globEscaper.Replace(pattern) + "*"
+	deleted := 0
+	batch := make([]string, 0, c.scanCount)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		n, err := c.client.Del(ctx, batch...).Result()
+		if err != nil {
+			return fmt.Errorf("deleting cache keys matching %q: %w", pattern, err)
+		}
+		deleted += int(n)
+		batch = batch[:0]
+		return nil
+	}
+
+	iter := c.client.Scan(ctx, 0, match, c.scanCount).Iterator()
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if int64(len(batch)) >= c.scanCount {
+			if err := flush(); err != nil {
+				return deleted, err
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return deleted, fmt.Errorf("scanning cache keys matching %q: %w", pattern, err)
+	}
+	if err := flush(); err != nil {
+		return deleted, err
+	}
+	return deleted, nil
+}
+
+// marshal serializes v in the configured format.
+func (c *Cache) marshal(v any) ([]byte, error) {
+	if c.format != FormatGob {
+		return json.Marshal(v)
+	}
+	buf := bytes.NewBuffer([]byte{gobMarker})
+	if err := gob.NewEncoder(buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshal decodes b into v, as gob if it starts with gobMarker and as JSON otherwise.
+func unmarshal(b []byte, v any) error {
+	if len(b) > 0 && b[0] == gobMarker {
+		return gob.NewDecoder(bytes.NewReader(b[1:])).Decode(v)
+	}
+	return json.Unmarshal(b, v)
+}
+
+// gzipBytes compresses b with gzip.
+func gzipBytes(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		return nil, fmt.Errorf("writing gzip stream: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("closing gzip stream: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// gunzip decompresses a gzip stream.
+func gunzip(b []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("opening gzip stream: %w", err)
+	}
+	defer zr.Close()
+
+	out, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("reading gzip stream: %w", err)
+	}
+	return out, nil
+}
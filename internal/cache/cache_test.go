@@ -2,7 +2,13 @@ package cache_test
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/redis/go-redis/v9"
@@ -13,7 +19,7 @@ import (
 	"github.com/neexbeast/ygo-test/internal/destination"
 )
 
-func newTestCache(t *testing.T) (*cache.Cache, *miniredis.Miniredis) {
+func newTestCache(t *testing.T, opts ...cache.Option) (*cache.Cache, *miniredis.Miniredis) {
 	t.Helper()
 	mr, err := miniredis.Run()
 	require.NoError(t, err)
@@ -22,7 +28,7 @@ func newTestCache(t *testing.T) (*cache.Cache, *miniredis.Miniredis) {
 	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
 	t.Cleanup(func() { _ = client.Close() })
 
-	return cache.NewCache(client), mr
+	return cache.NewCache(client, opts...), mr
 }
 
 func sampleData() *destination.DestinationData {
@@ -39,13 +45,26 @@ func TestCache_SetAndGet(t *testing.T) {
 	ctx := context.Background()
 
 	data := sampleData()
-	require.NoError(t, c.Set(ctx, "Paris", data))
+	require.NoError(t, c.Set(ctx, "Paris", data, time.Now()))
 
 	got, err := c.Get(ctx, "Paris")
 	require.NoError(t, err)
 	require.NotNil(t, got)
-	assert.Equal(t, 22.5, got.Weather.Temperature)
-	assert.Equal(t, "clear sky", got.Weather.Description)
+	assert.Equal(t, 22.5, got.Data.Weather.Temperature)
+	assert.Equal(t, "clear sky", got.Data.Weather.Description)
+}
+
+func TestCache_SetAndGet_FetchedAt(t *testing.T) {
+	c, _ := newTestCache(t)
+	ctx := context.Background()
+
+	fetchedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	require.NoError(t, c.Set(ctx, "Paris", sampleData(), fetchedAt))
+
+	got, err := c.Get(ctx, "Paris")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.True(t, fetchedAt.Equal(got.FetchedAt))
 }
 
 func TestCache_Get_Miss(t *testing.T) {
@@ -61,7 +80,7 @@ func TestCache_CityKeyIsLowercased(t *testing.T) {
 	ctx := context.Background()
 
 	data := sampleData()
-	require.NoError(t, c.Set(ctx, "PARIS", data))
+	require.NoError(t, c.Set(ctx, "PARIS", data, time.Now()))
 
 	// Retrieve with different casing — should still hit.
 	got, err := c.Get(ctx, "paris")
@@ -73,11 +92,79 @@ func TestCache_CityKeyIsLowercased(t *testing.T) {
 	require.NotNil(t, got2)
 }
 
+func TestCacheKey_MatchesSet(t *testing.T) {
+	c, mr := newTestCache(t)
+
+	require.NoError(t, c.Set(context.Background(), "Paris", sampleData(), time.Now()))
+
+	assert.Equal(t, "destination:v2:paris", cache.CacheKey("  Paris "))
+	assert.True(t, mr.Exists(cache.CacheKey("  Paris ")), "Set should write under CacheKey")
+}
+
+func TestCache_IgnoresEntriesFromOtherSchemaVersions(t *testing.T) {
+	c, mr := newTestCache(t)
+
+	// An entry in an older, incompatible shape written before the version bump.
+	old := `{"weather":{"temperature":"hot"}}`
+	require.NoError(t, mr.Set("destination:paris", old))
+	require.NoError(t, mr.Set("destination:v0:paris", old))
+	require.NoError(t, mr.Set("destination:v1:paris", old))
+
+	got, err := c.Get(context.Background(), "Paris")
+	require.NoError(t, err, "old entries must read as a miss, not a decode error")
+	assert.Nil(t, got)
+
+	keys, err := c.Keys(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, keys, "enumeration only sees the current version")
+
+	require.NoError(t, c.Set(context.Background(), "Paris", sampleData(), time.Now()))
+	got, err = c.Get(context.Background(), "Paris")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, 22.5, got.Data.Weather.Temperature)
+}
+
+func TestCache_SetNotFound(t *testing.T) {
+	c, mr := newTestCache(t, cache.WithNegativeTTL(time.Minute))
+	ctx := context.Background()
+
+	require.NoError(t, c.SetNotFound(ctx, "Atlantis"))
+	assert.Equal(t, time.Minute, mr.TTL(cache.CacheKey("Atlantis")))
+
+	got, err := c.Get(ctx, "Atlantis")
+	require.NoError(t, err)
+	require.NotNil(t, got, "known-absent is distinct from a miss")
+	assert.True(t, got.NotFound)
+	assert.Nil(t, got.Data)
+
+	require.NoError(t, c.Set(ctx, "Atlantis", sampleData(), time.Now()))
+	got, err = c.Get(ctx, "Atlantis")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.False(t, got.NotFound, "Set replaces the marker")
+	assert.Equal(t, time.Hour, mr.TTL(cache.CacheKey("Atlantis")))
+
+	mr.FastForward(2 * time.Minute)
+	require.NoError(t, c.SetNotFound(ctx, "Nowhere"))
+	mr.FastForward(2 * time.Minute)
+	got, err = c.Get(ctx, "Nowhere")
+	require.NoError(t, err)
+	assert.Nil(t, got, "marker expires after the negative TTL")
+}
+
+func TestCache_SetNotFound_DisabledByDefault(t *testing.T) {
+	c, mr := newTestCache(t)
+
+	require.NoError(t, c.SetNotFound(context.Background(), "Atlantis"))
+	assert.False(t, mr.Exists(cache.CacheKey("Atlantis")))
+}
+
 func TestCache_Delete(t *testing.T) {
 	c, _ := newTestCache(t)
 	ctx := context.Background()
 
-	require.NoError(t, c.Set(ctx, "Paris", sampleData()))
+	require.NoError(t, c.Set(ctx, "Paris", sampleData(), time.Now()))
 	require.NoError(t, c.Delete(ctx, "Paris"))
 
 	got, err := c.Get(ctx, "Paris")
@@ -95,7 +182,7 @@ func TestCache_Delete_NonExistent(t *testing.T) {
 func TestCache_Set_NilData(t *testing.T) {
 	c, _ := newTestCache(t)
 	// Setting nil data should be a no-op, not an error.
-	err := c.Set(context.Background(), "Paris", nil)
+	err := c.Set(context.Background(), "Paris", nil, time.Now())
 	require.NoError(t, err)
 }
 
@@ -103,7 +190,7 @@ func TestCache_TTL(t *testing.T) {
 	c, mr := newTestCache(t)
 	ctx := context.Background()
 
-	require.NoError(t, c.Set(ctx, "Paris", sampleData()))
+	require.NoError(t, c.Set(ctx, "Paris", sampleData(), time.Now()))
 
 	// Fast-forward miniredis time by 2 hours.
 	mr.FastForward(2 * 60 * 60 * 1e9) // 2h in nanoseconds
@@ -113,6 +200,226 @@ func TestCache_TTL(t *testing.T) {
 	assert.Nil(t, got, "entry should be expired after TTL")
 }
 
+func TestCache_Compression_RoundTrip(t *testing.T) {
+	c, mr := newTestCache(t, cache.WithCompression(true))
+	ctx := context.Background()
+
+	data := sampleData()
+	for i := 0; i < 50; i++ {
+		data.PointsOfInt = append(data.PointsOfInt, destination.POI{Name: "Museum", Kinds: "museums,cultural"})
+	}
+	require.NoError(t, c.Set(ctx, "Paris", data, time.Now()))
+
+	raw, err := mr.Get(cache.CacheKey("Paris"))
+	require.NoError(t, err)
+	assert.Equal(t, "\x1f\x8b", raw[:2], "stored value should be gzip")
+
+	plain, err := json.Marshal(data)
+	require.NoError(t, err)
+	assert.Less(t, len(raw), len(plain), "compressed value should be smaller")
+
+	got, err := c.Get(ctx, "Paris")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, data, got.Data)
+}
+
+func TestCache_Compression_ReadsLegacyUncompressed(t *testing.T) {
+	c, mr := newTestCache(t, cache.WithCompression(true))
+
+	require.NoError(t, mr.Set(cache.CacheKey("Paris"), `{"data":{"weather":{"temperature":18,"description":"mist"}}}`))
+
+	got, err := c.Get(context.Background(), "Paris")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "mist", got.Data.Weather.Description)
+}
+
+func TestCache_Compression_CorruptValue(t *testing.T) {
+	c, mr := newTestCache(t)
+
+	require.NoError(t, mr.Set(cache.CacheKey("Paris"), "\x1f\x8bnot-gzip"))
+
+	_, err := c.Get(context.Background(), "Paris")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "decompressing")
+}
+
+func TestCache_Format_RoundTrip(t *testing.T) {
+	for _, tt := range []struct {
+		format   string
+		compress bool
+	}{
+		{format: cache.FormatJSON},
+		{format: cache.FormatGob},
+		{format: cache.FormatGob, compress: true},
+	} {
+		t.Run(string(tt.format)+"/compress="+strconv.FormatBool(tt.compress), func(t *testing.T) {
+			c, mr := newTestCache(t, cache.WithFormat(tt.format), cache.WithCompression(tt.compress))
+			ctx := context.Background()
+			fetchedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+			require.NoError(t, c.Set(ctx, "Paris", sampleData(), fetchedAt))
+
+			raw, err := mr.Get(cache.CacheKey("Paris"))
+			require.NoError(t, err)
+			if tt.format == cache.FormatGob && !tt.compress {
+				assert.Equal(t, byte(0x01), raw[0], "gob values start with the format marker")
+			}
+
+			got, err := c.Get(ctx, "Paris")
+			require.NoError(t, err)
+			require.NotNil(t, got)
+			assert.Equal(t, sampleData(), got.Data)
+			assert.True(t, fetchedAt.Equal(got.FetchedAt))
+
+			country := &destination.CountryData{Region: "Europe", Capital: "Paris", Languages: []string{"French"}}
+			require.NoError(t, c.SetCountry(ctx, "France", country))
+			gotCountry, err := c.GetCountry(ctx, "France")
+			require.NoError(t, err)
+			assert.Equal(t, country, gotCountry)
+		})
+	}
+}
+
+func TestCache_Format_ReadsLegacyJSON(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newTestCache(t)
+	require.NoError(t, c.Set(ctx, "Paris", sampleData(), time.Now()))
+	require.NoError(t, mr.Set(cache.CacheKey("Rome"), `{"data":{"weather":{"temperature":18,"description":"mist"}}}`))
+
+	// The same Redis read after switching to gob: JSON written before is still served.
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	switched := cache.NewCache(client, cache.WithFormat(cache.FormatGob))
+
+	got, err := switched.Get(ctx, "Paris")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, sampleData(), got.Data)
+
+	got, err = switched.Get(ctx, "Rome")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "mist", got.Data.Weather.Description)
+
+	// And switching back reads what gob wrote.
+	require.NoError(t, switched.Set(ctx, "Rome", sampleData(), time.Now()))
+	got, err = c.Get(ctx, "Rome")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, sampleData(), got.Data)
+}
+
+func TestCache_TouchOnRead_ExtendsTTL(t *testing.T) {
+	c, mr := newTestCache(t, cache.WithTouchOnRead(true))
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, "Paris", sampleData(), time.Now()))
+	mr.FastForward(45 * time.Minute)
+	assert.Equal(t, 15*time.Minute, mr.TTL(cache.CacheKey("Paris")))
+
+	got, err := c.Get(ctx, "Paris")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, time.Hour, mr.TTL(cache.CacheKey("Paris")), "read should reset TTL to the full hour")
+
+	// Without the touch, the key would have expired 15 minutes after the original set.
+	mr.FastForward(30 * time.Minute)
+	got, err = c.Get(ctx, "Paris")
+	require.NoError(t, err)
+	assert.NotNil(t, got)
+}
+
+func TestCache_TouchOnRead_KeepsNegativeTTL(t *testing.T) {
+	c, mr := newTestCache(t, cache.WithTouchOnRead(true), cache.WithNegativeTTL(time.Minute))
+	ctx := context.Background()
+
+	require.NoError(t, c.SetNotFound(ctx, "Atlantis"))
+	mr.FastForward(45 * time.Second)
+
+	got, err := c.Get(ctx, "Atlantis")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.True(t, got.NotFound)
+	assert.Equal(t, 15*time.Second, mr.TTL(cache.CacheKey("Atlantis")), "reading a marker must not extend it")
+
+	mr.FastForward(30 * time.Second)
+	got, err = c.Get(ctx, "Atlantis")
+	require.NoError(t, err)
+	assert.Nil(t, got, "marker expires after the negative TTL")
+}
+
+func TestCache_TouchOnRead_DisabledKeepsTTL(t *testing.T) {
+	c, mr := newTestCache(t)
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, "Paris", sampleData(), time.Now()))
+	mr.FastForward(45 * time.Minute)
+
+	_, err := c.Get(ctx, "Paris")
+	require.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, mr.TTL(cache.CacheKey("Paris")))
+}
+
+func TestCache_MaxLifetime_ExpiresTouchedKeys(t *testing.T) {
+	const lifetime = 200 * time.Millisecond
+	c, mr := newTestCache(t, cache.WithTouchOnRead(true), cache.WithMaxLifetime(lifetime))
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, "Paris", sampleData(), time.Now()))
+	time.Sleep(lifetime / 2)
+	got, err := c.Get(ctx, "Paris")
+	require.NoError(t, err)
+	require.NotNil(t, got, "within its lifetime the entry is served")
+
+	time.Sleep(lifetime)
+	assert.Equal(t, time.Hour, mr.TTL(cache.CacheKey("Paris")), "the read touched the key")
+	got, err = c.Get(ctx, "Paris")
+	require.NoError(t, err)
+	assert.Nil(t, got, "past its deadline a recently touched entry is a miss")
+}
+
+func TestCache_MaxLifetime_NoCapByDefault(t *testing.T) {
+	c, mr := newTestCache(t)
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, "Paris", sampleData(), time.Now()))
+	raw, err := mr.Get(cache.CacheKey("Paris"))
+	require.NoError(t, err)
+	assert.NotContains(t, raw, "expires_at")
+}
+
+func TestCache_Keys_EnumeratesAllRegardlessOfScanCount(t *testing.T) {
+	const n = 250
+
+	for _, count := range []int{0, 1, 7, 100, 1000} {
+		t.Run("count="+strconv.Itoa(count), func(t *testing.T) {
+			c, mr := newTestCache(t, cache.WithScanCount(count))
+			want := make([]string, 0, n)
+			for i := 0; i < n; i++ {
+				key := cache.CacheKey("city-" + strconv.Itoa(i))
+				require.NoError(t, mr.Set(key, "{}"))
+				want = append(want, key)
+			}
+			require.NoError(t, mr.Set("unrelated:key", "x"))
+
+			keys, err := c.Keys(context.Background())
+			require.NoError(t, err)
+			assert.ElementsMatch(t, want, keys)
+		})
+	}
+}
+
+func TestCache_Keys_Error(t *testing.T) {
+	c, mr := newTestCache(t)
+	mr.Close()
+
+	_, err := c.Keys(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "scanning cache keys")
+}
+
 func TestConnect_InvalidURL(t *testing.T) {
 	_, err := cache.Connect(context.Background(), "not-a-url")
 	require.Error(t, err)
@@ -122,3 +429,247 @@ func TestConnect_UnreachableServer(t *testing.T) {
 	_, err := cache.Connect(context.Background(), "redis://localhost:19999")
 	require.Error(t, err)
 }
+
+// closingListener accepts TCP connections and closes them immediately, counting
+// each one, so a client's connection attempts against it fail and can be counted.
+func closingListener(t *testing.T) (string, *atomic.Int32) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	var accepts atomic.Int32
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			accepts.Add(1)
+			_ = conn.Close()
+		}
+	}()
+	return ln.Addr().String(), &accepts
+}
+
+func TestConnect_WithTLSRefusesPlaintextServer(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	_, err := cache.Connect(context.Background(), "redis://"+mr.Addr()+"?max_retries=-1",
+		cache.WithTLS(&tls.Config{MinVersion: tls.VersionTLS12}))
+	require.Error(t, err, "TLS is used even though the URL scheme is redis://")
+}
+
+func TestConnect_RetriesConfiguredTimes(t *testing.T) {
+	addr, accepts := closingListener(t)
+
+	// max_retries=-1 turns off go-redis's own per-command retries so each
+	// Connect attempt dials exactly once.
+	_, err := cache.Connect(context.Background(), "redis://"+addr+"?max_retries=-1",
+		cache.WithConnectRetries(3, 10*time.Millisecond))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "after 3 of 3 attempts")
+	assert.Equal(t, int32(3), accepts.Load())
+}
+
+func TestConnect_SucceedsOnceServerIsUp(t *testing.T) {
+	// Reserve a free port, then bring Redis up on it only after Connect has started.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	mr := miniredis.NewMiniRedis()
+	t.Cleanup(mr.Close)
+
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		_ = mr.StartAddr(addr)
+	}()
+
+	client, err := cache.Connect(context.Background(), "redis://"+addr,
+		cache.WithConnectRetries(10, 20*time.Millisecond))
+	require.NoError(t, err)
+	_ = client.Close()
+}
+
+func TestDeleteByPattern(t *testing.T) {
+	c, mr := newTestCache(t, cache.WithScanCount(2))
+	ctx := context.Background()
+
+	for _, city := range []string{"San Francisco", "San Diego", "Santiago", "Paris", "Busan", "Rome"} {
+		require.NoError(t, c.Set(ctx, city, sampleData(), time.Now()))
+	}
+	require.NoError(t, mr.Set("country:san marino", "{}"))
+
+	deleted, err := c.DeleteByPattern(ctx, "SAN")
+	require.NoError(t, err)
+	assert.Equal(t, 4, deleted, "matches anywhere in the city, in batches smaller than the match count")
+
+	for _, city := range []string{"San Francisco", "San Diego", "Santiago", "Busan"} {
+		assert.False(t, mr.Exists(cache.CacheKey(city)), city)
+	}
+	for _, city := range []string{"Paris", "Rome"} {
+		assert.True(t, mr.Exists(cache.CacheKey(city)), city)
+	}
+	assert.True(t, mr.Exists("country:san marino"), "only destination keys are matched")
+}
+
+func TestDeleteByPattern_Literal(t *testing.T) {
+	c, mr := newTestCache(t)
+	ctx := context.Background()
+	require.NoError(t, c.Set(ctx, "Paris", sampleData(), time.Now()))
+	require.NoError(t, c.Set(ctx, "p*s", sampleData(), time.Now()))
+
+	deleted, err := c.DeleteByPattern(ctx, "p*s")
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	assert.True(t, mr.Exists(cache.CacheKey("Paris")), "glob characters are not wildcards")
+
+	deleted, err = c.DeleteByPattern(ctx, "atlantis")
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+
+	_, err = c.DeleteByPattern(ctx, "  ")
+	require.ErrorIs(t, err, cache.ErrEmptyPattern)
+	assert.True(t, mr.Exists(cache.CacheKey("Paris")))
+}
+
+// ---- Rate limiter ----
+
+func TestRateLimiter_SharedAcrossInstances(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	// Two instances, each with its own client, sharing one Redis.
+	newLimiter := func() *cache.RateLimiter {
+		client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		t.Cleanup(func() { _ = client.Close() })
+		return cache.NewRateLimiter(client)
+	}
+	a, b := newLimiter(), newLimiter()
+	ctx := context.Background()
+
+	for i, l := range []*cache.RateLimiter{a, b, a} {
+		wait, err := l.Reserve(ctx, "10.0.0.1", 60, 3)
+		require.NoError(t, err)
+		assert.Zero(t, wait, "request %d within the shared burst", i+1)
+	}
+
+	wait, err := b.Reserve(ctx, "10.0.0.1", 60, 3)
+	require.NoError(t, err)
+	assert.InDelta(t, time.Second, wait, float64(100*time.Millisecond), "burst used up across both instances; one token per second")
+
+	wait, err = b.Reserve(ctx, "10.0.0.2", 60, 3)
+	require.NoError(t, err)
+	assert.Zero(t, wait, "other keys have their own bucket")
+
+	assert.True(t, mr.Exists("ratelimit:10.0.0.1"))
+	assert.Positive(t, mr.TTL("ratelimit:10.0.0.1"), "idle buckets expire")
+}
+
+func TestRateLimiter_RefillsOverTime(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	l := cache.NewRateLimiter(client)
+	ctx := context.Background()
+
+	// 1200/min refills one token every 50ms.
+	wait, err := l.Reserve(ctx, "ip", 1200, 1)
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+	wait, err = l.Reserve(ctx, "ip", 1200, 1)
+	require.NoError(t, err)
+	assert.Positive(t, wait)
+
+	time.Sleep(60 * time.Millisecond)
+	wait, err = l.Reserve(ctx, "ip", 1200, 1)
+	require.NoError(t, err)
+	assert.Zero(t, wait, "token refilled")
+}
+
+func TestRateLimiter_Peek(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	l := cache.NewRateLimiter(client)
+	ctx := context.Background()
+
+	remaining, resetIn, err := l.Peek(ctx, "ip", 60, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, remaining, "unseen key has a full bucket")
+	assert.Zero(t, resetIn)
+
+	for i := 0; i < 2; i++ {
+		_, err := l.Reserve(ctx, "ip", 60, 3)
+		require.NoError(t, err)
+	}
+
+	remaining, resetIn, err = l.Peek(ctx, "ip", 60, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 1, remaining)
+	assert.InDelta(t, 2*time.Second, resetIn, float64(100*time.Millisecond), "two tokens refill at one per second")
+
+	remaining, _, err = l.Peek(ctx, "ip", 60, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 1, remaining, "peeking does not take from the bucket")
+
+	_, err = l.Reserve(ctx, "ip", 60, 3)
+	require.NoError(t, err)
+	remaining, _, err = l.Peek(ctx, "ip", 60, 3)
+	require.NoError(t, err)
+	assert.Zero(t, remaining)
+}
+
+func TestRateLimiter_RedisDown(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = client.Close() })
+	mr.Close()
+
+	_, err = cache.NewRateLimiter(client).Reserve(context.Background(), "ip", 60, 1)
+	require.Error(t, err)
+}
+
+// ---- Country cache ----
+
+func TestCountry_SetAndGet(t *testing.T) {
+	c, mr := newTestCache(t, cache.WithCountryTTL(48*time.Hour))
+	ctx := context.Background()
+
+	got, err := c.GetCountry(ctx, "France")
+	require.NoError(t, err)
+	assert.Nil(t, got, "miss")
+
+	data := &destination.CountryData{Region: "Europe", Capital: "Paris", Currencies: map[string]string{"EUR": "Euro"}}
+	require.NoError(t, c.SetCountry(ctx, "France", data))
+
+	got, err = c.GetCountry(ctx, " france ")
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+	assert.True(t, mr.Exists("country:france"))
+	assert.Equal(t, 48*time.Hour, mr.TTL("country:france"))
+
+	require.NoError(t, c.SetCountry(ctx, "Nowhere", nil))
+	assert.False(t, mr.Exists("country:nowhere"), "nil data is not cached")
+}
+
+func TestCountry_DefaultTTL(t *testing.T) {
+	c, mr := newTestCache(t)
+	require.NoError(t, c.SetCountry(context.Background(), "France", &destination.CountryData{Region: "Europe"}))
+	assert.Equal(t, 24*time.Hour, mr.TTL("country:france"))
+}
+
+func TestCountry_Corrupt(t *testing.T) {
+	c, mr := newTestCache(t)
+	require.NoError(t, mr.Set("country:france", "not json"))
+	_, err := c.GetCountry(context.Background(), "France")
+	require.Error(t, err)
+}
@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const rateLimitKeyPrefix = "ratelimit:"
+
+// gcraScript is a token bucket (GCRA) kept as a single "theoretical arrival time"
+// per key, in microseconds. It admits a request unless that would push the TAT
+// more than burst emission intervals past now, returning 0 when admitted or the
+// number of microseconds to wait otherwise. Running in Redis makes it atomic
+// across every instance sharing the key.
+//
+// KEYS[1] = bucket key; ARGV = emission interval (µs), burst, now (µs).
+var gcraScript = redis.NewScript(`
+local emission = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local tat = tonumber(redis.call("GET", KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+
+local newTAT = tat + emission
+local wait = newTAT - emission * burst - now
+if wait > 0 then
+	return math.ceil(wait)
+end
+
+redis.call("SET", KEYS[1], string.format("%d", newTAT), "PX", math.ceil((newTAT - now) / 1000))
+return 0
+`)
+
+// RateLimiter keeps per-key token buckets in Redis so a rate limit is enforced
+// across all instances sharing the Redis, not per process.
+type RateLimiter struct {
+	client *redis.Client
+}
+
+// NewRateLimiter returns a RateLimiter storing its buckets in client.
+// Instances should have roughly synchronised clocks, as each supplies "now".
+func NewRateLimiter(client *redis.Client) *RateLimiter {
+	return &RateLimiter{client: client}
+}
+
+// Reserve takes one request from key's bucket, which refills at perMinute per
+// minute and holds up to burst requests. It returns zero if the request is
+// allowed, or how long to wait before retrying if it is not.
+func (l *RateLimiter) Reserve(ctx context.Context, key string, perMinute, burst int) (time.Duration, error) {
+	emission := time.Minute.Microseconds() / int64(perMinute)
+	now := time.Now().UnixMicro()
+
+	wait, err := gcraScript.Run(ctx, l.client, []string{rateLimitKeyPrefix + key}, emission, burst, now).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("rate limit %s: %w", key, err)
+	}
+	return time.Duration(wait) * time.Microsecond, nil
+}
+
+// Peek reports key's bucket without taking from it: how many requests it would
+// admit right now, and how long until it is full again. A key with no bucket
+// (never seen, or idle long enough to expire) is full.
+func (l *RateLimiter) Peek(ctx context.Context, key string, perMinute, burst int) (int, time.Duration, error) {
+	emission := time.Minute.Microseconds() / int64(perMinute)
+	now := time.Now().UnixMicro()
+
+	tat, err := l.client.Get(ctx, rateLimitKeyPrefix+key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return burst, 0, nil
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("rate limit peek %s: %w", key, err)
+	}
+	if tat < now {
+		return burst, 0, nil
+	}
+
+	// Each admitted request moves the TAT one emission interval further from now;
+	// the bucket admits requests while it stays within burst intervals.
+	ahead := tat - now
+	remaining := max(0, burst-int((ahead+emission-1)/emission))
+	return remaining, time.Duration(ahead) * time.Microsecond, nil
+}
@@ -2,24 +2,86 @@ package cache
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"net"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// ConnectOption configures optional Connect behaviour.
+type ConnectOption func(*connectConfig)
+
+type connectConfig struct {
+	attempts int
+	interval time.Duration
+	tls      *tls.Config
+}
+
+// WithConnectRetries makes Connect ping up to attempts times before giving up,
+// waiting interval after the first failure, twice that after the second, and so
+// on. Attempts below 1 keep the default of a single attempt.
+func WithConnectRetries(attempts int, interval time.Duration) ConnectOption {
+	return func(c *connectConfig) {
+		if attempts > 0 {
+			c.attempts = attempts
+		}
+		c.interval = interval
+	}
+}
+
+// WithTLS makes Connect use TLS with tlsCfg, whatever the URL scheme. The server
+// name defaults to the host in the URL.
+func WithTLS(tlsCfg *tls.Config) ConnectOption {
+	return func(c *connectConfig) {
+		c.tls = tlsCfg
+	}
+}
+
 // Connect parses redisURL, creates a client, and verifies connectivity with a ping.
-func Connect(ctx context.Context, redisURL string) (*redis.Client, error) {
-	opts, err := redis.ParseURL(redisURL)
+func Connect(ctx context.Context, redisURL string, opts ...ConnectOption) (*redis.Client, error) {
+	cfg := connectConfig{attempts: 1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	redisOpts, err := redis.ParseURL(redisURL)
 	if err != nil {
 		return nil, fmt.Errorf("parsing redis URL: %w", err)
 	}
+	if cfg.tls != nil {
+		redisOpts.TLSConfig = cfg.tls.Clone()
+		if redisOpts.TLSConfig.ServerName == "" {
+			host, _, _ := net.SplitHostPort(redisOpts.Addr)
+			redisOpts.TLSConfig.ServerName = host
+		}
+	}
 
-	client := redis.NewClient(opts)
+	client := redis.NewClient(redisOpts)
 
-	if err := client.Ping(ctx).Err(); err != nil {
-		_ = client.Close()
-		return nil, fmt.Errorf("pinging redis: %w", err)
+	attempt := 1
+	for ; ; attempt++ {
+		if err = client.Ping(ctx).Err(); err == nil {
+			return client, nil
+		}
+		if attempt >= cfg.attempts || !sleepCtx(ctx, time.Duration(attempt)*cfg.interval) {
+			break
+		}
 	}
 
-	return client, nil
+	_ = client.Close()
+	return nil, fmt.Errorf("pinging redis after %d of %d attempts: %w", attempt, cfg.attempts, err)
+}
+
+// sleepCtx waits for d, returning false early if ctx is done first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
 }
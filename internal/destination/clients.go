@@ -3,23 +3,279 @@ package destination
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
+
+	"golang.org/x/sync/semaphore"
+
+	"github.com/neexbeast/ygo-test/internal/metrics"
 )
 
 const httpTimeout = 10 * time.Second
 
+// outbound caps concurrent outbound requests across every doGet call in the process.
+// A nil value means no limit.
+var outbound atomic.Pointer[semaphore.Weighted]
+
+// SetMaxOutboundConcurrency limits how many provider requests may be in flight at
+// once, process-wide, regardless of how many fetches are running. n <= 0 removes the limit.
+// Requests already holding a slot are unaffected by a change.
+func SetMaxOutboundConcurrency(n int) {
+	if n <= 0 {
+		outbound.Store(nil)
+		return
+	}
+	outbound.Store(semaphore.NewWeighted(int64(n)))
+}
+
+// logSchemaDrift decodes body a second time, into plain maps and slices, and
+// logs, without failing, the keys the provider sent that dst's type does not
+// declare.
+func logSchemaDrift(rawURL string, body []byte, dst any) {
+	var generic any
+	if err := json.Unmarshal(body, &generic); err != nil {
+		return
+	}
+	fields := unknownFields(generic, reflect.TypeOf(dst), "")
+	if len(fields) == 0 {
+		return
+	}
+	slices.Sort(fields)
+	host := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		host = u.Host + u.Path
+	}
+	slog.Warn("provider schema drift", "endpoint", host, "fields", strings.Join(fields, ","))
+}
+
+var jsonUnmarshaler = reflect.TypeFor[json.Unmarshaler]()
+
+// unknownFields returns the dotted paths of the object keys in v, a decoded JSON
+// value, that decoding it into a value of type t would ignore. Keys match field
+// names case-insensitively, as in encoding/json. Types that decode themselves
+// or take any value are not looked into.
+func unknownFields(v any, t reflect.Type, path string) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Interface || reflect.PointerTo(t).Implements(jsonUnmarshaler) {
+		return nil
+	}
+
+	var out []string
+	switch v := v.(type) {
+	case map[string]any:
+		switch t.Kind() {
+		case reflect.Struct:
+			fields := jsonFields(t)
+			for key, child := range v {
+				ft, ok := fields[strings.ToLower(key)]
+				if !ok {
+					out = append(out, joinPath(path, key))
+					continue
+				}
+				out = append(out, unknownFields(child, ft, joinPath(path, key))...)
+			}
+		case reflect.Map:
+			for key, child := range v {
+				out = append(out, unknownFields(child, t.Elem(), joinPath(path, key))...)
+			}
+		}
+	case []any:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for _, child := range v {
+				out = append(out, unknownFields(child, t.Elem(), path+"[]")...)
+			}
+		}
+	}
+	return out
+}
+
+// jsonFields maps the lowercased JSON name of each field encoding/json decodes
+// into struct type t, including those of embedded structs, to the field's type.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || (f.Anonymous && f.Type.Kind() == reflect.Struct) {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[strings.ToLower(name)] = f.Type
+	}
+	return fields
+}
+
+// joinPath appends key k to the dotted path.
+func joinPath(path, k string) string {
+	if path == "" {
+		return k
+	}
+	return path + "." + k
+}
+
+// countRequest records a provider request's outcome on in's Metrics, if any:
+// the response status class if one arrived, or the error's class otherwise.
+func (in Instrumentation) countRequest(provider string, status int, err error) {
+	m := in.Metrics
+	if m == nil {
+		return
+	}
+	class := metrics.ClassError
+	switch {
+	case status != 0:
+		class = strconv.Itoa(status/100) + "xx"
+	case errors.Is(err, ErrFetchTimeout):
+		class = metrics.ClassTimeout
+	case errors.Is(err, ErrFetchCanceled):
+		class = metrics.ClassCanceled
+	}
+	m.ProviderRequests.WithLabelValues(provider, class).Inc()
+}
+
+// recordQuota sets provider's quota gauges on in's Metrics from the rate limit
+// headers in h, if in tracks quotas and h carries a valid X-RateLimit-Remaining.
+func (in Instrumentation) recordQuota(provider string, h http.Header) {
+	if !in.TrackQuota || in.Metrics == nil {
+		return
+	}
+	remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	in.Metrics.ProviderQuotaRemaining.WithLabelValues(provider).Set(float64(remaining))
+	if limit, err := strconv.Atoi(h.Get("X-RateLimit-Limit")); err == nil {
+		in.Metrics.ProviderQuotaLimit.WithLabelValues(provider).Set(float64(limit))
+	}
+}
+
+// Instrumentation is the bookkeeping a provider client does around each of its
+// requests. Every client takes one through its options; the zero value does none.
+type Instrumentation struct {
+	// Metrics, if set, counts every request on ProviderRequests, labelled by
+	// provider and status class.
+	Metrics *metrics.Metrics
+	// TrackQuota records the rate limit headers of every response on Metrics'
+	// provider quota gauges, so /metrics shows how much of each API plan is left.
+	TrackQuota bool
+	// SchemaDrift logs a warning naming the fields of a response that the
+	// client does not parse. Decoding itself stays lenient either way.
+	SchemaDrift bool
+}
+
+// Sentinel errors returned (wrapped) by provider clients when the caller's context ends
+// mid-request, so callers can tell a timeout or cancellation apart from a provider failure.
+var (
+	ErrFetchTimeout  = errors.New("provider request timed out")
+	ErrFetchCanceled = errors.New("provider request canceled")
+)
+
+// contextError classifies err as ErrFetchTimeout or ErrFetchCanceled when it was caused by
+// ctx ending, keeping the original error in the chain. Other errors are returned unchanged.
+func contextError(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("%w: %w", ErrFetchTimeout, err)
+	case errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled):
+		return fmt.Errorf("%w: %w", ErrFetchCanceled, err)
+	default:
+		return err
+	}
+}
+
 // newHTTPClient returns an http.Client with a 10-second timeout.
 func newHTTPClient() *http.Client {
 	return &http.Client{Timeout: httpTimeout}
 }
 
+// Sentinel errors returned (wrapped) by provider clients, so callers can tell
+// why a provider failed with errors.Is rather than by matching messages.
+var (
+	// ErrProviderUnavailable means the provider could not be reached or answered
+	// with a server error or 429 Too Many Requests; trying again later may work.
+	ErrProviderUnavailable = errors.New("provider unavailable")
+	// ErrProviderUnauthorized means the provider rejected the API key (401 or 403).
+	ErrProviderUnauthorized = errors.New("provider rejected credentials")
+	// ErrCityNotFound means the provider has no data for the place asked about:
+	// a 404, or an empty answer such as a geocode without coordinates. For REST
+	// Countries the place is the destination's country.
+	ErrCityNotFound = errors.New("city not found")
+	// ErrResponseTooLarge means a response body exceeded the client's size limit;
+	// nothing of the body is decoded.
+	ErrResponseTooLarge = errors.New("provider response too large")
+)
+
+// StatusError is returned (wrapped) by provider clients for a response with a
+// status other than 200. It unwraps to ErrCityNotFound, ErrProviderUnauthorized
+// or ErrProviderUnavailable where the status maps to one; use errors.As to get
+// the status itself.
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+// Error implements error.
+func (e *StatusError) Error() string {
+	return "GET " + e.URL + " returned status " + strconv.Itoa(e.StatusCode)
+}
+
+// Unwrap returns the sentinel error for e's status, or nil if there is none.
+func (e *StatusError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusNotFound:
+		return ErrCityNotFound
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrProviderUnauthorized
+	case e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError:
+		return ErrProviderUnavailable
+	default:
+		return nil
+	}
+}
+
+// Default response body limits per provider, generous next to what each API sends
+// for a single city. The clients' options override them.
+const (
+	DefaultWeatherMaxBody   int64 = 64 << 10
+	DefaultPOIMaxBody       int64 = 1 << 20
+	DefaultCountriesMaxBody int64 = 1 << 20
+	DefaultTeleportMaxBody  int64 = 256 << 10
+
+	exchangeMaxBody int64 = 256 << 10
+	alertsMaxBody   int64 = 1 << 20
+)
+
 // doGet performs a GET request and decodes the JSON response into dst.
-func doGet(ctx context.Context, client *http.Client, rawURL string, dst any) error {
+// A body longer than maxBody bytes fails with ErrResponseTooLarge; maxBody <= 0 means no limit.
+// It waits for an outbound slot first when SetMaxOutboundConcurrency is in effect.
+// If ctx ends before the response arrives, the error wraps ErrFetchTimeout or ErrFetchCanceled;
+// other transport failures wrap ErrProviderUnavailable, and a status other than 200
+// returns a *StatusError.
+// The request is counted under provider when in has Metrics, and its rate limit
+// headers recorded when in also tracks quotas.
+func doGet(ctx context.Context, client *http.Client, in Instrumentation, provider, rawURL string, maxBody int64, dst any) error {
+	if sem := outbound.Load(); sem != nil {
+		if err := sem.Acquire(ctx, 1); err != nil {
+			return fmt.Errorf("waiting for outbound slot for %s: %w", rawURL, contextError(ctx, err))
+		}
+		defer sem.Release(1)
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
 	if err != nil {
 		return fmt.Errorf("creating request for %s: %w", rawURL, err)
@@ -27,17 +283,39 @@ func doGet(ctx context.Context, client *http.Client, rawURL string, dst any) err
 
 	resp, err := client.Do(req)
 	if err != nil {
+		err = contextError(ctx, err)
+		if !errors.Is(err, ErrFetchTimeout) && !errors.Is(err, ErrFetchCanceled) {
+			err = fmt.Errorf("%w: %w", ErrProviderUnavailable, err)
+		}
+		in.countRequest(provider, 0, err)
 		return fmt.Errorf("GET %s: %w", rawURL, err)
 	}
 	defer resp.Body.Close()
+	in.countRequest(provider, resp.StatusCode, nil)
+	in.recordQuota(provider, resp.Header)
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("GET %s returned status %d", rawURL, resp.StatusCode)
+		return &StatusError{URL: rawURL, StatusCode: resp.StatusCode}
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
+	var r io.Reader = resp.Body
+	if maxBody > 0 {
+		// One byte over the limit is enough to tell a body at the limit from a longer one.
+		r = io.LimitReader(resp.Body, maxBody+1)
+	}
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("reading response from %s: %w", rawURL, contextError(ctx, err))
+	}
+	if maxBody > 0 && int64(len(body)) > maxBody {
+		return fmt.Errorf("response from %s: %w (limit %d bytes)", rawURL, ErrResponseTooLarge, maxBody)
+	}
+	if err := json.Unmarshal(body, dst); err != nil {
 		return fmt.Errorf("decoding response from %s: %w", rawURL, err)
 	}
+	if in.SchemaDrift {
+		logSchemaDrift(rawURL, body, dst)
+	}
 
 	return nil
 }
@@ -49,18 +327,75 @@ type WeatherClient struct {
 	apiKey  string
 	baseURL string
 	client  *http.Client
+	instr   Instrumentation
+	maxBody int64
+
+	noValidation bool
+}
+
+// WeatherOption configures optional WeatherClient behaviour.
+type WeatherOption func(*WeatherClient)
+
+// WithWeatherMaxBody sets the largest response body accepted from OpenWeatherMap,
+// in bytes. Values below 1 keep DefaultWeatherMaxBody.
+func WithWeatherMaxBody(n int64) WeatherOption {
+	return func(c *WeatherClient) {
+		if n > 0 {
+			c.maxBody = n
+		}
+	}
+}
+
+// WithWeatherInstrumentation sets the bookkeeping done around each OpenWeatherMap request.
+func WithWeatherInstrumentation(in Instrumentation) WeatherOption {
+	return func(c *WeatherClient) { c.instr = in }
 }
 
 const owmDefaultURL = "https://api.openweathermap.org/data/2.5/weather"
 
+// WithWeatherValidation sets whether Fetch rejects responses that look empty or
+// implausible (see ErrImplausibleWeather). It does by default.
+func WithWeatherValidation(enabled bool) WeatherOption {
+	return func(c *WeatherClient) {
+		c.noValidation = !enabled
+	}
+}
+
+// ErrImplausibleWeather is returned (wrapped) by WeatherClient.Fetch for a response
+// with no description and a temperature that is either missing, zero together with
+// zero humidity as in a default-zeroed parse, or outside the range ever recorded.
+var ErrImplausibleWeather = errors.New("implausible weather response")
+
+// Temperatures outside this range, in °C, have never been recorded on Earth.
+const (
+	minPlausibleTemp = -90
+	maxPlausibleTemp = 60
+)
+
+// plausibleWeather reports whether wd looks like real conditions rather than an
+// empty or glitched response. A description alone is enough.
+func plausibleWeather(wd *WeatherData) bool {
+	if wd.Description != "" {
+		return true
+	}
+	if wd.Temperature == 0 && wd.Humidity == 0 {
+		return false
+	}
+	return wd.Temperature >= minPlausibleTemp && wd.Temperature <= maxPlausibleTemp
+}
+
 // NewWeatherClient constructs a WeatherClient with the given API key.
-func NewWeatherClient(apiKey string) *WeatherClient {
-	return &WeatherClient{apiKey: apiKey, baseURL: owmDefaultURL, client: newHTTPClient()}
+func NewWeatherClient(apiKey string, opts ...WeatherOption) *WeatherClient {
+	return NewWeatherClientWithURL(owmDefaultURL, apiKey, opts...)
 }
 
 // NewWeatherClientWithURL constructs a WeatherClient pointing at a custom base URL (for tests).
-func NewWeatherClientWithURL(baseURL, apiKey string) *WeatherClient {
-	return &WeatherClient{apiKey: apiKey, baseURL: baseURL, client: newHTTPClient()}
+func NewWeatherClientWithURL(baseURL, apiKey string, opts ...WeatherOption) *WeatherClient {
+	c := &WeatherClient{apiKey: apiKey, baseURL: baseURL, client: newHTTPClient(), maxBody: DefaultWeatherMaxBody}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 type owmResponse struct {
@@ -75,6 +410,15 @@ type owmResponse struct {
 	Wind struct {
 		Speed float64 `json:"speed"`
 	} `json:"wind"`
+	Coord *Coordinates `json:"coord"`
+	Sys   struct {
+		Country string `json:"country"`
+		// Sunrise and Sunset are Unix times; 0 when not reported.
+		Sunrise int64 `json:"sunrise"`
+		Sunset  int64 `json:"sunset"`
+	} `json:"sys"`
+	// Timezone is the shift in seconds from UTC; a pointer since 0 (UTC) is valid.
+	Timezone *int `json:"timezone"`
 }
 
 // Fetch retrieves weather data for the given city.
@@ -82,7 +426,7 @@ func (c *WeatherClient) Fetch(ctx context.Context, city string) (*WeatherData, e
 	endpoint := c.baseURL + "?q=" + url.QueryEscape(city) + "&appid=" + c.apiKey + "&units=metric"
 
 	var raw owmResponse
-	if err := doGet(ctx, c.client, endpoint, &raw); err != nil {
+	if err := doGet(ctx, c.client, c.instr, ProviderWeather, endpoint, c.maxBody, &raw); err != nil {
 		return nil, fmt.Errorf("openweathermap fetch for %s: %w", city, err)
 	}
 
@@ -91,13 +435,35 @@ func (c *WeatherClient) Fetch(ctx context.Context, city string) (*WeatherData, e
 		description = raw.Weather[0].Description
 	}
 
-	return &WeatherData{
+	wd := &WeatherData{
 		Temperature: raw.Main.Temp,
 		FeelsLike:   raw.Main.FeelsLike,
 		Humidity:    raw.Main.Humidity,
 		Description: description,
 		WindSpeed:   raw.Wind.Speed,
-	}, nil
+		CountryCode: raw.Sys.Country,
+		Coordinates: raw.Coord,
+	}
+	zone := time.UTC
+	if raw.Timezone != nil {
+		wd.Timezone = NewTimezone(*raw.Timezone)
+		zone = time.FixedZone("", *raw.Timezone)
+	}
+	wd.Sunrise = localTime(raw.Sys.Sunrise, zone)
+	wd.Sunset = localTime(raw.Sys.Sunset, zone)
+	if !c.noValidation && !plausibleWeather(wd) {
+		return nil, fmt.Errorf("openweathermap fetch for %s: %w (temperature %g, humidity %d)",
+			city, ErrImplausibleWeather, wd.Temperature, wd.Humidity)
+	}
+	return wd, nil
+}
+
+// localTime formats the Unix time sec in zone as RFC 3339, or "" if sec is 0.
+func localTime(sec int64, zone *time.Location) string {
+	if sec == 0 {
+		return ""
+	}
+	return time.Unix(sec, 0).In(zone).Format(time.RFC3339)
 }
 
 // ---- OpenTripMap ----
@@ -108,31 +474,120 @@ type POIClient struct {
 	geoBaseURL string
 	poiBaseURL string
 	client     *http.Client
+	instr      Instrumentation
+
+	geoRetries    int
+	radiusRetries int
+	retryDelay    time.Duration
+
+	limit   int
+	maxPOIs int
+	maxBody int64
+}
+
+// POIOption configures optional POIClient behaviour.
+type POIOption func(*POIClient)
+
+// WithGeocodeRetries sets how many times a failed geocode request is retried.
+func WithGeocodeRetries(n int) POIOption {
+	return func(c *POIClient) { c.geoRetries = n }
+}
+
+// WithRadiusRetries sets how many times a failed radius request is retried.
+// Retries reuse the coordinates from the geocode step instead of geocoding again.
+func WithRadiusRetries(n int) POIOption {
+	return func(c *POIClient) { c.radiusRetries = n }
+}
+
+// WithPOILimit sets how many points of interest to request per city.
+// It is clamped to the cap set by WithMaxPOIs.
+func WithPOILimit(n int) POIOption {
+	return func(c *POIClient) { c.limit = n }
 }
 
+// WithMaxPOIs sets the hard cap on points of interest kept per city, bounding the
+// size of stored data whatever limit is requested. Values below 1 keep the default.
+func WithMaxPOIs(n int) POIOption {
+	return func(c *POIClient) {
+		if n > 0 {
+			c.maxPOIs = n
+		}
+	}
+}
+
+// WithPOIMaxBody sets the largest response body accepted from either OpenTripMap
+// endpoint, in bytes. Values below 1 keep DefaultPOIMaxBody.
+func WithPOIMaxBody(n int64) POIOption {
+	return func(c *POIClient) {
+		if n > 0 {
+			c.maxBody = n
+		}
+	}
+}
+
+// WithPOIInstrumentation sets the bookkeeping done around each OpenTripMap request.
+func WithPOIInstrumentation(in Instrumentation) POIOption {
+	return func(c *POIClient) { c.instr = in }
+}
+
+// Default number of POIs requested per city, and the cap no requested limit may exceed.
+const (
+	defaultPOILimit = 5
+	DefaultMaxPOIs  = 20
+)
+
+// poiRetryDelay is the pause before the first retry of a POI step; it grows linearly per attempt.
+const poiRetryDelay = 200 * time.Millisecond
+
 const (
 	otmGeoDefault = "https://api.opentripmap.com/0.1/en/places/geoname"
 	otmPOIDefault = "https://api.opentripmap.com/0.1/en/places/radius"
 )
 
 // NewPOIClient constructs a POIClient with the given API key.
-func NewPOIClient(apiKey string) *POIClient {
-	return &POIClient{
-		apiKey:     apiKey,
-		geoBaseURL: otmGeoDefault,
-		poiBaseURL: otmPOIDefault,
-		client:     newHTTPClient(),
-	}
+func NewPOIClient(apiKey string, opts ...POIOption) *POIClient {
+	return NewPOIClientWithURLs(otmGeoDefault, otmPOIDefault, apiKey, opts...)
 }
 
 // NewPOIClientWithURLs constructs a POIClient pointing at custom URLs (for tests).
-func NewPOIClientWithURLs(geoBaseURL, poiBaseURL, apiKey string) *POIClient {
-	return &POIClient{
+func NewPOIClientWithURLs(geoBaseURL, poiBaseURL, apiKey string, opts ...POIOption) *POIClient {
+	c := &POIClient{
 		apiKey:     apiKey,
 		geoBaseURL: geoBaseURL,
 		poiBaseURL: poiBaseURL,
 		client:     newHTTPClient(),
+		retryDelay: poiRetryDelay,
+		limit:      defaultPOILimit,
+		maxPOIs:    DefaultMaxPOIs,
+		maxBody:    DefaultPOIMaxBody,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
+}
+
+// effectiveLimit is the requested POI limit clamped to [1, maxPOIs].
+func (c *POIClient) effectiveLimit() int {
+	return max(1, min(c.limit, c.maxPOIs))
+}
+
+// withRetries calls fn up to retries+1 times, waiting between attempts.
+// It gives up early when ctx ends, since further attempts cannot succeed.
+func (c *POIClient) withRetries(ctx context.Context, retries int, fn func() error) error {
+	err := fn()
+	for attempt := 1; attempt <= retries && err != nil; attempt++ {
+		if errors.Is(err, ErrFetchTimeout) || errors.Is(err, ErrFetchCanceled) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt) * c.retryDelay):
+		}
+		err = fn()
+	}
+	return err
 }
 
 type otmGeoResponse struct {
@@ -150,35 +605,73 @@ type otmRadiusResponse struct {
 	} `json:"features"`
 }
 
-// Fetch retrieves the top 5 points of interest near the given city.
-func (c *POIClient) Fetch(ctx context.Context, city string) ([]POI, error) {
+// Fetch retrieves the top points of interest near the given city, at most the
+// configured limit (5 by default) and never more than the cap.
+// countryHint disambiguates cities sharing a name ("Springfield"). It may be a
+// two-letter country code or a country name; names are converted to the code,
+// the only form OpenTripMap's geoname country filter accepts, and a hint that
+// is neither is ignored.
+// The geocode and radius steps are retried independently, so a flaky radius
+// call does not cost another geocode.
+func (c *POIClient) Fetch(ctx context.Context, city, countryHint string) ([]POI, error) {
 	geoURL := c.geoBaseURL + "?name=" + url.QueryEscape(city) + "&apikey=" + c.apiKey
+	if code, ok := countryCode(countryHint); ok {
+		geoURL += "&country=" + code
+	}
 
 	var geo otmGeoResponse
-	if err := doGet(ctx, c.client, geoURL, &geo); err != nil {
+	if err := c.withRetries(ctx, c.geoRetries, func() error {
+		return doGet(ctx, c.client, c.instr, ProviderPOI, geoURL, c.maxBody, &geo)
+	}); err != nil {
 		return nil, fmt.Errorf("opentripmap geocode for %s: %w", city, err)
 	}
+	// A miss can come back as 200 with no coordinates, which decodes to 0,0 in
+	// the Gulf of Guinea; searching there would return unrelated POIs.
+	if geo.Lat == 0 && geo.Lon == 0 {
+		return nil, fmt.Errorf("opentripmap geocode for %s: %w: no coordinates", city, ErrCityNotFound)
+	}
 
 	poiURL := fmt.Sprintf(
-		"%s?radius=5000&lon=%f&lat=%f&limit=5&format=geojson&apikey=%s",
-		c.poiBaseURL, geo.Lon, geo.Lat, c.apiKey,
+		"%s?radius=5000&lon=%f&lat=%f&limit=%d&format=geojson&apikey=%s",
+		c.poiBaseURL, geo.Lon, geo.Lat, c.effectiveLimit(), c.apiKey,
 	)
 
 	var raw otmRadiusResponse
-	if err := doGet(ctx, c.client, poiURL, &raw); err != nil {
+	if err := c.withRetries(ctx, c.radiusRetries, func() error {
+		raw = otmRadiusResponse{}
+		return doGet(ctx, c.client, c.instr, ProviderPOI, poiURL, c.maxBody, &raw)
+	}); err != nil {
 		return nil, fmt.Errorf("opentripmap radius for %s: %w", city, err)
 	}
 
-	pois := make([]POI, 0, len(raw.Features))
+	limit := c.effectiveLimit()
+	pois := make([]POI, 0, min(len(raw.Features), limit))
+	// OpenTripMap can list one attraction several times; index maps a normalized
+	// name to its entry so duplicates keep only the best-rated one, in the place
+	// of the first.
+	index := make(map[string]int)
 	for _, f := range raw.Features {
 		if f.Properties.Name == "" {
 			continue
 		}
-		pois = append(pois, POI{
+		poi := POI{
 			Name:  f.Properties.Name,
 			Kinds: f.Properties.Kinds,
 			Rate:  f.Properties.Rate,
-		})
+		}
+		key := strings.ToLower(strings.TrimSpace(poi.Name))
+		if i, ok := index[key]; ok {
+			if poi.Rate > pois[i].Rate {
+				pois[i] = poi
+			}
+			continue
+		}
+		// The provider is not trusted to honour limit; never store more than asked.
+		if len(pois) == limit {
+			break
+		}
+		index[key] = len(pois)
+		pois = append(pois, poi)
 	}
 
 	return pois, nil
@@ -190,18 +683,52 @@ func (c *POIClient) Fetch(ctx context.Context, city string) ([]POI, error) {
 type CountriesClient struct {
 	baseURL string
 	client  *http.Client
+	instr   Instrumentation
+
+	maxLanguages int
+	maxBody      int64
+}
+
+// CountriesOption configures optional CountriesClient behaviour.
+type CountriesOption func(*CountriesClient)
+
+// WithMaxLanguages keeps at most n of a country's languages, the first n in
+// alphabetical order. Zero or less (the default) keeps them all.
+func WithMaxLanguages(n int) CountriesOption {
+	return func(c *CountriesClient) {
+		c.maxLanguages = max(n, 0)
+	}
+}
+
+// WithCountriesMaxBody sets the largest response body accepted from RestCountries,
+// in bytes. Values below 1 keep DefaultCountriesMaxBody.
+func WithCountriesMaxBody(n int64) CountriesOption {
+	return func(c *CountriesClient) {
+		if n > 0 {
+			c.maxBody = n
+		}
+	}
+}
+
+// WithCountriesInstrumentation sets the bookkeeping done around each RestCountries request.
+func WithCountriesInstrumentation(in Instrumentation) CountriesOption {
+	return func(c *CountriesClient) { c.instr = in }
 }
 
 const countriesDefaultURL = "https://restcountries.com/v3.1/name"
 
 // NewCountriesClient constructs a CountriesClient.
-func NewCountriesClient() *CountriesClient {
-	return &CountriesClient{baseURL: countriesDefaultURL, client: newHTTPClient()}
+func NewCountriesClient(opts ...CountriesOption) *CountriesClient {
+	return NewCountriesClientWithURL(countriesDefaultURL, opts...)
 }
 
 // NewCountriesClientWithURL constructs a CountriesClient pointing at a custom base URL (for tests).
-func NewCountriesClientWithURL(baseURL string) *CountriesClient {
-	return &CountriesClient{baseURL: baseURL, client: newHTTPClient()}
+func NewCountriesClientWithURL(baseURL string, opts ...CountriesOption) *CountriesClient {
+	c := &CountriesClient{baseURL: baseURL, client: newHTTPClient(), maxBody: DefaultCountriesMaxBody}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 type restCountriesEntry struct {
@@ -215,15 +742,15 @@ type restCountriesEntry struct {
 
 // Fetch retrieves country data for the given country name.
 func (c *CountriesClient) Fetch(ctx context.Context, country string) (*CountryData, error) {
-	endpoint := c.baseURL + "/" + url.QueryEscape(country) + "?fullText=true"
+	endpoint := c.baseURL + "/" + url.PathEscape(country) + "?fullText=true"
 
 	var raw []restCountriesEntry
-	if err := doGet(ctx, c.client, endpoint, &raw); err != nil {
+	if err := doGet(ctx, c.client, c.instr, ProviderCountry, endpoint, c.maxBody, &raw); err != nil {
 		return nil, fmt.Errorf("restcountries fetch for %s: %w", country, err)
 	}
 
 	if len(raw) == 0 {
-		return nil, fmt.Errorf("restcountries: no results for %s", country)
+		return nil, fmt.Errorf("restcountries fetch for %s: %w: no results", country, ErrCityNotFound)
 	}
 
 	entry := raw[0]
@@ -233,10 +760,15 @@ func (c *CountriesClient) Fetch(ctx context.Context, country string) (*CountryDa
 		currencies[code] = cur.Name
 	}
 
+	// Languages come as a map, so sort them for the same order on every fetch.
 	languages := make([]string, 0, len(entry.Languages))
 	for _, lang := range entry.Languages {
 		languages = append(languages, lang)
 	}
+	slices.Sort(languages)
+	if c.maxLanguages > 0 && len(languages) > c.maxLanguages {
+		languages = languages[:c.maxLanguages]
+	}
 
 	capital := ""
 	if len(entry.Capital) > 0 {
@@ -257,25 +789,47 @@ func (c *CountriesClient) Fetch(ctx context.Context, country string) (*CountryDa
 type TeleportClient struct {
 	urlBuilder func(city string) string
 	client     *http.Client
+	instr      Instrumentation
+	maxBody    int64
 }
 
-// NewTeleportClient constructs a TeleportClient using the production Teleport API URL.
-func NewTeleportClient() *TeleportClient {
-	return &TeleportClient{
-		urlBuilder: func(city string) string {
-			return "https://api.teleport.org/api/urban_areas/slug:" + cityToSlug(city) + "/scores/"
-		},
-		client: newHTTPClient(),
+// TeleportOption configures optional TeleportClient behaviour.
+type TeleportOption func(*TeleportClient)
+
+// WithTeleportMaxBody sets the largest response body accepted from Teleport, in
+// bytes. Values below 1 keep DefaultTeleportMaxBody.
+func WithTeleportMaxBody(n int64) TeleportOption {
+	return func(c *TeleportClient) {
+		if n > 0 {
+			c.maxBody = n
+		}
 	}
 }
 
+// WithTeleportInstrumentation sets the bookkeeping done around each Teleport request.
+func WithTeleportInstrumentation(in Instrumentation) TeleportOption {
+	return func(c *TeleportClient) { c.instr = in }
+}
+
+// NewTeleportClient constructs a TeleportClient using the production Teleport API URL.
+func NewTeleportClient(opts ...TeleportOption) *TeleportClient {
+	return newTeleportClient(func(city string) string {
+		return "https://api.teleport.org/api/urban_areas/slug:" + cityToSlug(city) + "/scores/"
+	}, opts)
+}
+
 // NewTeleportClientWithURL constructs a TeleportClient that always uses the given URL (for tests).
 // The city slug is ignored — the full URL is used directly.
-func NewTeleportClientWithURL(fixedURL string) *TeleportClient {
-	return &TeleportClient{
-		urlBuilder: func(_ string) string { return fixedURL },
-		client:     newHTTPClient(),
+func NewTeleportClientWithURL(fixedURL string, opts ...TeleportOption) *TeleportClient {
+	return newTeleportClient(func(_ string) string { return fixedURL }, opts)
+}
+
+func newTeleportClient(urlBuilder func(city string) string, opts []TeleportOption) *TeleportClient {
+	c := &TeleportClient{urlBuilder: urlBuilder, client: newHTTPClient(), maxBody: DefaultTeleportMaxBody}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
 
 type teleportScoresResponse struct {
@@ -295,7 +849,7 @@ func (c *TeleportClient) Fetch(ctx context.Context, city string) ([]QualityScore
 	endpoint := c.urlBuilder(city)
 
 	var raw teleportScoresResponse
-	if err := doGet(ctx, c.client, endpoint, &raw); err != nil {
+	if err := doGet(ctx, c.client, c.instr, ProviderTeleport, endpoint, c.maxBody, &raw); err != nil {
 		slog.Warn("teleport fetch failed", "city", city, "err", err)
 		return nil, fmt.Errorf("teleport fetch for %s: %w", city, err)
 	}
@@ -310,3 +864,136 @@ func (c *TeleportClient) Fetch(ctx context.Context, city string) ([]QualityScore
 
 	return scores, nil
 }
+
+// ---- ExchangeRate-API ----
+
+// ExchangeRateClient fetches currency exchange rates from ExchangeRate-API's open
+// endpoint (no API key required), relative to a fixed base currency.
+type ExchangeRateClient struct {
+	baseURL string
+	base    string
+	client  *http.Client
+	instr   Instrumentation
+}
+
+// ExchangeRateOption configures optional ExchangeRateClient behaviour.
+type ExchangeRateOption func(*ExchangeRateClient)
+
+// WithExchangeRateInstrumentation sets the bookkeeping done around each ExchangeRate-API request.
+func WithExchangeRateInstrumentation(in Instrumentation) ExchangeRateOption {
+	return func(c *ExchangeRateClient) { c.instr = in }
+}
+
+const exchangeRatesDefaultURL = "https://open.er-api.com/v6/latest"
+
+// NewExchangeRateClient constructs an ExchangeRateClient quoting rates against base
+// (an ISO 4217 code such as "USD").
+func NewExchangeRateClient(base string, opts ...ExchangeRateOption) *ExchangeRateClient {
+	return NewExchangeRateClientWithURL(exchangeRatesDefaultURL, base, opts...)
+}
+
+// NewExchangeRateClientWithURL constructs an ExchangeRateClient pointing at a custom base URL (for tests).
+func NewExchangeRateClientWithURL(baseURL, base string, opts ...ExchangeRateOption) *ExchangeRateClient {
+	c := &ExchangeRateClient{baseURL: baseURL, base: strings.ToUpper(base), client: newHTTPClient()}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+type exchangeRatesResponse struct {
+	Result    string             `json:"result"`
+	ErrorType string             `json:"error-type"`
+	Rates     map[string]float64 `json:"rates"`
+}
+
+// Fetch returns how many units of each of the given currencies one unit of the base
+// currency buys, with the base itself included at 1. Currencies the API does not
+// quote are left out; it is an error if none of them are quoted.
+func (c *ExchangeRateClient) Fetch(ctx context.Context, currencies []string) (map[string]float64, error) {
+	var raw exchangeRatesResponse
+	if err := doGet(ctx, c.client, c.instr, SupplementExchange, c.baseURL+"/"+url.PathEscape(c.base), exchangeMaxBody, &raw); err != nil {
+		return nil, fmt.Errorf("exchange rates for %s: %w", c.base, err)
+	}
+	if raw.Result != "success" {
+		return nil, fmt.Errorf("exchange rates for %s: %s", c.base, raw.ErrorType)
+	}
+
+	rates := map[string]float64{c.base: 1}
+	for _, code := range currencies {
+		code = strings.ToUpper(code)
+		if rate, ok := raw.Rates[code]; ok {
+			rates[code] = rate
+		}
+	}
+	if len(rates) == 1 && !slices.Contains(currencies, c.base) {
+		return nil, fmt.Errorf("exchange rates for %s: no rate for %v", c.base, currencies)
+	}
+	return rates, nil
+}
+
+// ---- OpenWeatherMap One Call ----
+
+// AlertsClient fetches severe-weather alerts from OpenWeatherMap's One Call API,
+// which needs a One Call subscription on top of the current weather one.
+type AlertsClient struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+	instr   Instrumentation
+}
+
+// AlertsOption configures optional AlertsClient behaviour.
+type AlertsOption func(*AlertsClient)
+
+// WithAlertsInstrumentation sets the bookkeeping done around each One Call request.
+func WithAlertsInstrumentation(in Instrumentation) AlertsOption {
+	return func(c *AlertsClient) { c.instr = in }
+}
+
+const oneCallDefaultURL = "https://api.openweathermap.org/data/3.0/onecall"
+
+// NewAlertsClient constructs an AlertsClient using the production One Call URL.
+func NewAlertsClient(apiKey string, opts ...AlertsOption) *AlertsClient {
+	return NewAlertsClientWithURL(oneCallDefaultURL, apiKey, opts...)
+}
+
+// NewAlertsClientWithURL constructs an AlertsClient pointing at a custom base URL (for tests).
+func NewAlertsClientWithURL(baseURL, apiKey string, opts ...AlertsOption) *AlertsClient {
+	c := &AlertsClient{apiKey: apiKey, baseURL: baseURL, client: newHTTPClient()}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+type oneCallResponse struct {
+	Alerts []struct {
+		Event       string `json:"event"`
+		Description string `json:"description"`
+		Start       int64  `json:"start"`
+		End         int64  `json:"end"`
+	} `json:"alerts"`
+}
+
+// Fetch returns the alerts in effect at the given point; none is not an error.
+func (c *AlertsClient) Fetch(ctx context.Context, at Coordinates) ([]WeatherAlert, error) {
+	endpoint := fmt.Sprintf("%s?lat=%f&lon=%f&exclude=current,minutely,hourly,daily&appid=%s",
+		c.baseURL, at.Lat, at.Lon, c.apiKey)
+
+	var raw oneCallResponse
+	if err := doGet(ctx, c.client, c.instr, SupplementAlerts, endpoint, alertsMaxBody, &raw); err != nil {
+		return nil, fmt.Errorf("one call alerts at %f,%f: %w", at.Lat, at.Lon, err)
+	}
+
+	alerts := make([]WeatherAlert, 0, len(raw.Alerts))
+	for _, a := range raw.Alerts {
+		alerts = append(alerts, WeatherAlert{
+			Event:       a.Event,
+			Description: a.Description,
+			Start:       time.Unix(a.Start, 0).UTC(),
+			End:         time.Unix(a.End, 0).UTC(),
+		})
+	}
+	return alerts, nil
+}
@@ -0,0 +1,108 @@
+package destination
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// Change kinds: how a field differs between two versions of destination data.
+const (
+	// FieldAdded is a field only the newer data has.
+	FieldAdded = "added"
+	// FieldRemoved is a field only the older data has.
+	FieldRemoved = "removed"
+	// FieldChanged is a field both have, with different values.
+	FieldChanged = "changed"
+)
+
+// FieldChange is one difference found by Diff. Path names the field as it appears
+// in the JSON form of the data, e.g. "weather.temperature" or
+// "points_of_interest[2].name". Old is unset for an added field and New for a
+// removed one.
+type FieldChange struct {
+	Path string `json:"path"`
+	Kind string `json:"kind"`
+	Old  any    `json:"old,omitempty"`
+	New  any    `json:"new,omitempty"`
+}
+
+// Diff returns the field-level changes from d to other, ordered by path. Objects
+// are compared key by key and lists element by element, so a reordered list shows
+// as changed elements. SourcesFetchedAt, not part of the JSON form, is not
+// compared. Two equal values have no changes.
+func (d *DestinationData) Diff(other *DestinationData) ([]FieldChange, error) {
+	from, err := diffable(d)
+	if err != nil {
+		return nil, err
+	}
+	to, err := diffable(other)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []FieldChange
+	diffValues("", from, to, &changes)
+	slices.SortFunc(changes, func(a, b FieldChange) int { return cmp.Compare(a.Path, b.Path) })
+	return changes, nil
+}
+
+// diffable returns d's JSON form as generic values. A nil d is empty.
+func diffable(d *DestinationData) (map[string]any, error) {
+	if d == nil {
+		return map[string]any{}, nil
+	}
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, fmt.Errorf("encoding destination data for diff: %w", err)
+	}
+	var v map[string]any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Errorf("decoding destination data for diff: %w", err)
+	}
+	return v, nil
+}
+
+// diffValues appends the changes between from and to, found at path, to changes.
+func diffValues(path string, from, to any, changes *[]FieldChange) {
+	switch f := from.(type) {
+	case map[string]any:
+		if t, ok := to.(map[string]any); ok {
+			for k, fv := range f {
+				tv, ok := t[k]
+				if !ok {
+					*changes = append(*changes, FieldChange{Path: joinPath(path, k), Kind: FieldRemoved, Old: fv})
+					continue
+				}
+				diffValues(joinPath(path, k), fv, tv, changes)
+			}
+			for k, tv := range t {
+				if _, ok := f[k]; !ok {
+					*changes = append(*changes, FieldChange{Path: joinPath(path, k), Kind: FieldAdded, New: tv})
+				}
+			}
+			return
+		}
+	case []any:
+		if t, ok := to.([]any); ok {
+			for i := range max(len(f), len(t)) {
+				elem := path + "[" + strconv.Itoa(i) + "]"
+				switch {
+				case i >= len(t):
+					*changes = append(*changes, FieldChange{Path: elem, Kind: FieldRemoved, Old: f[i]})
+				case i >= len(f):
+					*changes = append(*changes, FieldChange{Path: elem, Kind: FieldAdded, New: t[i]})
+				default:
+					diffValues(elem, f[i], t[i], changes)
+				}
+			}
+			return
+		}
+	}
+	if !reflect.DeepEqual(from, to) {
+		*changes = append(*changes, FieldChange{Path: path, Kind: FieldChanged, Old: from, New: to})
+	}
+}
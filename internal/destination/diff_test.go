@@ -0,0 +1,58 @@
+package destination_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neexbeast/ygo-test/internal/destination"
+)
+
+func TestDestinationData_Diff(t *testing.T) {
+	stored := &destination.DestinationData{
+		Weather: &destination.WeatherData{Temperature: 18, Description: "mist", CountryCode: "FR"},
+		PointsOfInt: []destination.POI{
+			{Name: "Louvre", Kinds: "museums", Rate: 3},
+			{Name: "Pantheon", Kinds: "historic", Rate: 2},
+		},
+		Country:          &destination.CountryData{Region: "Europe", Capital: "Paris", Languages: []string{"French"}},
+		SourcesFetchedAt: map[string]time.Time{destination.ProviderWeather: time.Now()},
+	}
+	fresh := &destination.DestinationData{
+		Weather:       &destination.WeatherData{Temperature: 21.5, Description: "mist", Timezone: destination.NewTimezone(3600)},
+		PointsOfInt:   []destination.POI{{Name: "Louvre", Kinds: "museums", Rate: 3}},
+		Country:       &destination.CountryData{Region: "Europe", Capital: "Paris", Languages: []string{"French"}},
+		QualityScores: []destination.QualityScore{{Name: "Safety", ScoreOutOf: 6.5}},
+	}
+
+	changes, err := stored.Diff(fresh)
+	require.NoError(t, err)
+	assert.Equal(t, []destination.FieldChange{
+		{Path: "points_of_interest[1]", Kind: destination.FieldRemoved, Old: map[string]any{"name": "Pantheon", "kinds": "historic", "rate": 2.0}},
+		{Path: "quality_scores", Kind: destination.FieldAdded, New: []any{map[string]any{"name": "Safety", "score_out_of_10": 6.5}}},
+		{Path: "weather.country_code", Kind: destination.FieldRemoved, Old: "FR"},
+		{Path: "weather.temperature", Kind: destination.FieldChanged, Old: 18.0, New: 21.5},
+		{Path: "weather.timezone", Kind: destination.FieldAdded, New: map[string]any{"offset_seconds": 3600.0, "utc_offset": "+01:00"}},
+	}, changes, "unchanged fields and sources_fetched_at are left out")
+}
+
+func TestDestinationData_Diff_Equal(t *testing.T) {
+	data := &destination.DestinationData{Weather: &destination.WeatherData{Temperature: 18}}
+
+	changes, err := data.Diff(&destination.DestinationData{Weather: &destination.WeatherData{Temperature: 18}})
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestDestinationData_Diff_Nil(t *testing.T) {
+	var empty *destination.DestinationData
+	data := &destination.DestinationData{Country: &destination.CountryData{Region: "Asia"}}
+
+	changes, err := empty.Diff(data)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "country", changes[0].Path)
+	assert.Equal(t, destination.FieldAdded, changes[0].Kind)
+}
@@ -2,20 +2,50 @@ package destination
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
+	"sync"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 )
 
-// weatherFetcher is the interface satisfied by WeatherClient.
-type weatherFetcher interface {
+// Provider names used to key per-provider diagnostics in FetchResult.
+const (
+	ProviderWeather  = "weather"
+	ProviderPOI      = "poi"
+	ProviderCountry  = "country"
+	ProviderTeleport = "teleport"
+)
+
+// SupplementExchange keys the exchange rate lookup's timing in FetchResult. It is
+// a supplement, not a provider: it is not in Providers(), and its failure is
+// logged but not recorded in Errors or counted against the result.
+const SupplementExchange = "exchange"
+
+// SupplementAlerts keys the weather alert lookup's timing in FetchResult. Like
+// SupplementExchange it is not a provider and its failure is only logged.
+const SupplementAlerts = "alerts"
+
+// WeatherProvider is the interface satisfied by WeatherClient and any alternative weather source.
+type WeatherProvider interface {
 	Fetch(ctx context.Context, city string) (*WeatherData, error)
 }
 
+// WeatherSourceOpenWeatherMap names the default OpenWeatherMap weather source.
+const WeatherSourceOpenWeatherMap = "openweathermap"
+
+// WeatherSource is a named weather provider. Fetcher tries its sources in priority order.
+type WeatherSource struct {
+	Name     string
+	Provider WeatherProvider
+}
+
 // poiFetcher is the interface satisfied by POIClient.
 type poiFetcher interface {
-	Fetch(ctx context.Context, city string) ([]POI, error)
+	Fetch(ctx context.Context, city, countryHint string) ([]POI, error)
 }
 
 // countriesFetcher is the interface satisfied by CountriesClient.
@@ -28,111 +58,658 @@ type teleportFetcher interface {
 	Fetch(ctx context.Context, city string) ([]QualityScore, error)
 }
 
+// CountryCache stores country data by country name, so cities in the same
+// country share one lookup. GetCountry returns nil, nil on a miss.
+type CountryCache interface {
+	GetCountry(ctx context.Context, country string) (*CountryData, error)
+	SetCountry(ctx context.Context, country string, data *CountryData) error
+}
+
+// exchangeRateFetcher is the interface satisfied by ExchangeRateClient.
+type exchangeRateFetcher interface {
+	Fetch(ctx context.Context, currencies []string) (map[string]float64, error)
+}
+
+// alertsFetcher is the interface satisfied by AlertsClient.
+type alertsFetcher interface {
+	Fetch(ctx context.Context, at Coordinates) ([]WeatherAlert, error)
+}
+
 // Fetcher aggregates data from all external APIs in parallel.
 type Fetcher struct {
-	weather   weatherFetcher
-	poi       poiFetcher
-	countries countriesFetcher
-	teleport  teleportFetcher
+	// weather is in priority order; the first source that succeeds populates WeatherData.
+	weather           []WeatherSource
+	weatherPriority   []string
+	weatherOpts       []WeatherOption
+	poiOpts           []POIOption
+	countriesOpts     []CountriesOption
+	teleportOpts      []TeleportOption
+	inferCountry      bool
+	noCountryFallback bool
+	poi               poiFetcher
+	countries         countriesFetcher
+	teleport          teleportFetcher
+	exchange          exchangeRateFetcher
+	alerts            alertsFetcher
+	countryCache      CountryCache
+	probeCity         string
+	probeCountry      string
+}
+
+// FetcherOption configures optional Fetcher behaviour.
+type FetcherOption func(*Fetcher)
+
+// WithWeatherOptions passes opts to the OpenWeatherMap client built by NewFetcher.
+func WithWeatherOptions(opts ...WeatherOption) FetcherOption {
+	return func(f *Fetcher) {
+		f.weatherOpts = append(f.weatherOpts, opts...)
+	}
+}
+
+// WithPOIOptions passes opts to the POI client built by NewFetcher.
+func WithPOIOptions(opts ...POIOption) FetcherOption {
+	return func(f *Fetcher) {
+		f.poiOpts = append(f.poiOpts, opts...)
+	}
+}
+
+// WithCountriesOptions passes opts to the RestCountries client built by NewFetcher.
+func WithCountriesOptions(opts ...CountriesOption) FetcherOption {
+	return func(f *Fetcher) {
+		f.countriesOpts = append(f.countriesOpts, opts...)
+	}
+}
+
+// WithTeleportOptions passes opts to the Teleport client built by NewFetcher.
+func WithTeleportOptions(opts ...TeleportOption) FetcherOption {
+	return func(f *Fetcher) {
+		f.teleportOpts = append(f.teleportOpts, opts...)
+	}
+}
+
+// WithWeatherSources replaces the weather sources, in priority order.
+// The default is the single OpenWeatherMap source.
+func WithWeatherSources(sources ...WeatherSource) FetcherOption {
+	return func(f *Fetcher) {
+		f.weather = sources
+	}
+}
+
+// WithWeatherPriority reorders the weather sources by name: the named sources
+// come first in the given order, followed by any others in their existing order.
+// Unknown names are ignored.
+func WithWeatherPriority(names ...string) FetcherOption {
+	return func(f *Fetcher) {
+		f.weatherPriority = names
+	}
+}
+
+// WithCountryInference makes FetchAll derive the country from the weather response's
+// ISO code when the caller passes no country, instead of using the city name.
+// The country lookup then waits for weather rather than running in parallel.
+func WithCountryInference(enabled bool) FetcherOption {
+	return func(f *Fetcher) {
+		f.inferCountry = enabled
+	}
+}
+
+// WithCountryFallback sets whether FetchAll looks up the city name as the country
+// when it has no country, neither passed nor inferred. It does by default; when
+// disabled, the country provider is skipped instead of queried with a city name.
+func WithCountryFallback(enabled bool) FetcherOption {
+	return func(f *Fetcher) {
+		f.noCountryFallback = !enabled
+	}
+}
+
+// WithExchangeRates makes FetchAll look up exchange rates for the country's
+// currencies with r once the country is known. Without it, ExchangeRates stays empty.
+func WithExchangeRates(r exchangeRateFetcher) FetcherOption {
+	return func(f *Fetcher) {
+		f.exchange = r
+	}
+}
+
+// WithWeatherAlerts makes FetchAll look up severe-weather alerts with a once the
+// weather is known, at the coordinates it reports. Without it, or without
+// coordinates, Alerts stays empty; so it does if the lookup fails.
+func WithWeatherAlerts(a alertsFetcher) FetcherOption {
+	return func(f *Fetcher) {
+		f.alerts = a
+	}
+}
+
+// WithCountryCache makes FetchAll check cache for the country before calling
+// RestCountries, and store what RestCountries returns. Cache errors are logged
+// and fall through to the API.
+func WithCountryCache(cache CountryCache) FetcherOption {
+	return func(f *Fetcher) {
+		f.countryCache = cache
+	}
+}
+
+// WithProbeCity sets the city, and its country, that Probe and ProbeProvider
+// fetch to check the providers. It should be one every provider has full data
+// for. An empty city keeps DefaultProbeCity and DefaultProbeCountry.
+func WithProbeCity(city, country string) FetcherOption {
+	return func(f *Fetcher) {
+		if city != "" {
+			f.probeCity = city
+			f.probeCountry = country
+		}
+	}
 }
 
 // NewFetcher constructs a Fetcher with all four API clients using production URLs.
-func NewFetcher(weatherKey, poiKey string) *Fetcher {
-	return &Fetcher{
-		weather:   NewWeatherClient(weatherKey),
-		poi:       NewPOIClient(poiKey),
-		countries: NewCountriesClient(),
-		teleport:  NewTeleportClient(),
+func NewFetcher(weatherKey, poiKey string, opts ...FetcherOption) *Fetcher {
+	owm := NewWeatherClient(weatherKey)
+	f := &Fetcher{
+		weather:      []WeatherSource{{Name: WeatherSourceOpenWeatherMap, Provider: owm}},
+		probeCity:    DefaultProbeCity,
+		probeCountry: DefaultProbeCountry,
+	}
+	f.apply(opts)
+	// The client is built before apply so WithWeatherSources can replace it; its
+	// own options are applied once they are known.
+	for _, opt := range f.weatherOpts {
+		opt(owm)
 	}
+	f.poi = NewPOIClient(poiKey, f.poiOpts...)
+	f.countries = NewCountriesClient(f.countriesOpts...)
+	f.teleport = NewTeleportClient(f.teleportOpts...)
+	return f
 }
 
 // NewFetcherWithClients constructs a Fetcher with injectable clients (used in tests).
-func NewFetcherWithClients(w weatherFetcher, p poiFetcher, c countriesFetcher, t teleportFetcher) *Fetcher {
-	return &Fetcher{weather: w, poi: p, countries: c, teleport: t}
+// w becomes the OpenWeatherMap weather source.
+func NewFetcherWithClients(w WeatherProvider, p poiFetcher, c countriesFetcher, t teleportFetcher, opts ...FetcherOption) *Fetcher {
+	f := &Fetcher{
+		weather:   []WeatherSource{{Name: WeatherSourceOpenWeatherMap, Provider: w}},
+		poi:       p,
+		countries: c,
+		teleport:  t,
+
+		probeCity:    DefaultProbeCity,
+		probeCountry: DefaultProbeCountry,
+	}
+	f.apply(opts)
+	return f
+}
+
+// apply runs opts and then orders the weather sources by the configured priority.
+func (f *Fetcher) apply(opts []FetcherOption) {
+	for _, opt := range opts {
+		opt(f)
+	}
+	if len(f.weatherPriority) == 0 {
+		return
+	}
+
+	rank := make(map[string]int, len(f.weatherPriority))
+	for i, name := range f.weatherPriority {
+		if _, dup := rank[name]; !dup {
+			rank[name] = i
+		}
+	}
+	sort.SliceStable(f.weather, func(i, j int) bool {
+		ri, iRanked := rank[f.weather[i].Name]
+		rj, jRanked := rank[f.weather[j].Name]
+		switch {
+		case iRanked && jRanked:
+			return ri < rj
+		default:
+			return iRanked && !jRanked
+		}
+	})
+}
+
+// fetchWeather tries each weather source in priority order and returns the first
+// success with its source name. Lower-priority sources are only called when every
+// source ahead of them failed, so fallbacks cost no quota while the primary is healthy.
+func (f *Fetcher) fetchWeather(ctx context.Context, city string) (*WeatherData, string, error) {
+	var errs []error
+	for _, src := range f.weather {
+		wd, err := src.Provider.Fetch(ctx, city)
+		if err == nil && wd != nil {
+			return wd, src.Name, nil
+		}
+		if err == nil {
+			err = errors.New("no data")
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", src.Name, err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if len(errs) == 0 {
+		return nil, "", errors.New("no weather sources configured")
+	}
+	return nil, "", errors.Join(errs...)
+}
+
+// fetchCountry returns country data from the country cache when configured and
+// populated, and from RestCountries otherwise, caching what it returns.
+func (f *Fetcher) fetchCountry(ctx context.Context, country string) (*CountryData, error) {
+	if f.countryCache == nil {
+		return f.countries.Fetch(ctx, country)
+	}
+
+	cached, err := f.countryCache.GetCountry(ctx, country)
+	if err != nil {
+		slog.Warn("country cache get failed", "country", country, "err", err)
+	}
+	if cached != nil {
+		return cached, nil
+	}
+
+	cd, err := f.countries.Fetch(ctx, country)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.countryCache.SetCountry(ctx, country, cd); err != nil {
+		slog.Warn("country cache set failed", "country", country, "err", err)
+	}
+	return cd, nil
+}
+
+// FetchResult is the outcome of FetchAll: the aggregated data plus per-provider diagnostics.
+type FetchResult struct {
+	Data *DestinationData
+	// Timings holds how long each provider call took.
+	Timings map[string]time.Duration
+	// Errors holds the failure for each provider that did not return data.
+	// Providers absent from Errors succeeded.
+	Errors map[string]error
+	// Statuses holds every provider's outcome, telling a provider that answered
+	// with nothing (SourceEmpty) apart from one that failed (SourceError).
+	Statuses map[string]string
+	// WeatherSource names the weather source that supplied Data.Weather, if any.
+	WeatherSource string
+	// Country is the country name the country lookup used: the caller's, the one
+	// derived from weather, or the city name as a last resort.
+	Country string
+}
+
+// Providers lists every provider FetchAll calls, in a stable order.
+func Providers() []string {
+	return []string{ProviderWeather, ProviderPOI, ProviderCountry, ProviderTeleport}
+}
+
+// ErrNoCountry is recorded in FetchResult.Errors for the country provider when
+// there was no country to look up (see WithCountryFallback), so the missing
+// country data counts against SuccessCount although the provider was not called.
+var ErrNoCountry = errors.New("no country to look up")
+
+// SuccessCount returns how many providers returned without error, including
+// those that had no data to return.
+func (r *FetchResult) SuccessCount() int {
+	if r == nil {
+		return 0
+	}
+	return len(Providers()) - len(r.Errors)
+}
+
+// Provider outcomes in a FetchResult, as reported by FetchResult.Sources.
+const (
+	// SourceOK means the provider returned data.
+	SourceOK = "ok"
+	// SourceEmpty means the provider answered successfully but had no data, e.g. no POIs nearby.
+	SourceEmpty = "empty"
+	// SourceError means the provider call failed.
+	SourceError = "error"
+	// SourceSkipped means the provider was not called: FetchProviders was asked for others.
+	SourceSkipped = "skipped"
+)
+
+// Sources maps every provider to its status. Results not built by FetchAll, which
+// have no Statuses, report SourceOK or SourceError from Errors alone.
+func (r *FetchResult) Sources() map[string]string {
+	sources := make(map[string]string, len(Providers()))
+	for _, p := range Providers() {
+		switch {
+		case r == nil:
+			sources[p] = SourceError
+		case r.Statuses[p] != "":
+			sources[p] = r.Statuses[p]
+		case r.Errors[p] != nil:
+			sources[p] = SourceError
+		default:
+			sources[p] = SourceOK
+		}
+	}
+	return sources
+}
+
+// Overall outcomes of a FetchResult across all providers, as reported by
+// FetchResult.Status.
+const (
+	// FetchComplete means every provider answered, with or without data.
+	FetchComplete = "complete"
+	// FetchPartial means some providers failed and others answered.
+	FetchPartial = "partial"
+	// FetchFailed means every provider failed.
+	FetchFailed = "failed"
+)
+
+// Status derives the overall outcome from the per-provider results. A provider
+// that answered with no data counts as answered, as in SuccessCount.
+func (r *FetchResult) Status() string {
+	switch r.SuccessCount() {
+	case len(Providers()):
+		return FetchComplete
+	case 0:
+		return FetchFailed
+	default:
+		return FetchPartial
+	}
+}
+
+// sourceStatuses derives each provider's status from its error and what it returned,
+// or whether it was called at all.
+func sourceStatuses(data *DestinationData, errs map[string]error, called map[string]bool) map[string]string {
+	empty := map[string]bool{
+		ProviderWeather:  data.Weather == nil,
+		ProviderPOI:      len(data.PointsOfInt) == 0,
+		ProviderCountry:  data.Country == nil,
+		ProviderTeleport: len(data.QualityScores) == 0,
+	}
+	statuses := make(map[string]string, len(empty))
+	for _, p := range Providers() {
+		switch {
+		case !called[p]:
+			statuses[p] = SourceSkipped
+		case errs[p] != nil:
+			statuses[p] = SourceError
+		case empty[p]:
+			statuses[p] = SourceEmpty
+		default:
+			statuses[p] = SourceOK
+		}
+	}
+	return statuses
+}
+
+// Canceled reports whether any provider stopped because the caller's context was canceled,
+// as opposed to failing on its own.
+func (r *FetchResult) Canceled() bool {
+	if r == nil {
+		return false
+	}
+	for _, err := range r.Errors {
+		if errors.Is(err, ErrFetchCanceled) {
+			return true
+		}
+	}
+	return false
+}
+
+// logFetchError logs a provider failure, reporting timeouts and cancellations separately
+// from errors returned by the provider itself.
+func logFetchError(name string, err error, args ...any) {
+	args = append(args, "err", err)
+	switch {
+	case errors.Is(err, ErrFetchTimeout):
+		slog.Warn(name+" fetch timed out", args...)
+	case errors.Is(err, ErrFetchCanceled):
+		slog.Info(name+" fetch canceled", args...)
+	default:
+		slog.Warn(name+" fetch failed", args...)
+	}
+}
+
+// resultRecorder collects provider timings and failures from concurrent goroutines.
+type resultRecorder struct {
+	mu      sync.Mutex
+	timings map[string]time.Duration
+	errs    map[string]error
+}
+
+func newResultRecorder() *resultRecorder {
+	return &resultRecorder{
+		timings: make(map[string]time.Duration, 4),
+		errs:    make(map[string]error, 4),
+	}
+}
+
+// timed stores the time elapsed since start for the given provider.
+func (rr *resultRecorder) timed(provider string, start time.Time) {
+	elapsed := time.Since(start)
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	rr.timings[provider] = elapsed
+}
+
+// failed records that the given provider did not return data.
+func (rr *resultRecorder) failed(provider string, err error) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	rr.errs[provider] = err
 }
 
 // FetchAll fetches data from all external APIs in parallel using errgroup.
-// All API failures are non-fatal: partial data is returned with failures logged.
-func (f *Fetcher) FetchAll(ctx context.Context, city, country string) (*DestinationData, error) {
+// All API failures are non-fatal: partial data is returned with failures logged
+// and recorded per provider in the result's Errors. A provider that panics is
+// recorded as failed in the same way, without affecting the others.
+// The duration of every provider call is recorded in the result's Timings.
+// An empty country defaults to the city name, or with WithCountryInference to the
+// country named by the weather response's ISO code. With WithCountryFallback(false)
+// the city name is never used and the country provider is skipped instead.
+// With WithExchangeRates, rates for the country's currencies are fetched once the
+// country lookup finishes; their failure only leaves ExchangeRates empty.
+// With WithWeatherAlerts, alerts are fetched once the weather call finishes;
+// their failure only leaves Alerts empty.
+func (f *Fetcher) FetchAll(ctx context.Context, city, country string) (*FetchResult, error) {
+	return f.FetchProviders(ctx, city, country, Providers())
+}
+
+// FetchProviders is FetchAll calling only the named providers. The others are
+// reported as SourceSkipped, and their sections of the result's Data are empty.
+// Exchange rates follow the country provider, as they need its currencies, and
+// alerts follow the weather provider, as they need its coordinates.
+func (f *Fetcher) FetchProviders(ctx context.Context, city, country string, providers []string) (*FetchResult, error) {
+	called := make(map[string]bool, len(providers))
+	for _, p := range providers {
+		called[p] = true
+	}
+
 	g, gCtx := errgroup.WithContext(ctx)
+	rec := newResultRecorder()
+
+	inferring := country == "" && f.inferCountry
+	weatherDone := make(chan struct{})
+	countryDone := make(chan struct{})
+	// RestCountries matches full names only, so a country given as a code is
+	// looked up by its name.
+	lookupCountry := country
+	if name, ok := countryName(country); ok {
+		lookupCountry = name
+	}
+	if lookupCountry == "" && !f.noCountryFallback {
+		lookupCountry = city
+	}
+	// countrySkipped is set when there turns out to be no country to look up;
+	// the provider is then reported as skipped but still failed with ErrNoCountry.
+	var countrySkipped bool
 
 	var weatherData *WeatherData
+	var weatherSource string
 	var poiData []POI
 	var countryData *CountryData
 	var qualityScores []QualityScore
+	var exchangeRates map[string]float64
+	var alerts []WeatherAlert
 
-	g.Go(func() (err error) {
-		defer func() {
-			if r := recover(); r != nil {
-				slog.Error("weather fetch panicked", "recover", r)
-				err = fmt.Errorf("weather fetch panicked: %v", r)
+	if called[ProviderWeather] {
+		g.Go(func() error {
+			defer close(weatherDone)
+			defer rec.timed(ProviderWeather, time.Now())
+			defer func() {
+				if r := recover(); r != nil {
+					slog.Error("weather fetch panicked", "recover", r)
+					rec.failed(ProviderWeather, fmt.Errorf("weather fetch panicked: %v", r))
+				}
+			}()
+			wd, source, fetchErr := f.fetchWeather(gCtx, city)
+			if fetchErr != nil {
+				logFetchError("weather", fetchErr, "city", city)
+				rec.failed(ProviderWeather, fetchErr)
+				return nil
 			}
-		}()
-		wd, fetchErr := f.weather.Fetch(gCtx, city)
-		if fetchErr != nil {
-			slog.Warn("weather fetch failed", "city", city, "err", fetchErr)
+			weatherData = wd
+			weatherSource = source
 			return nil
-		}
-		weatherData = wd
-		return nil
-	})
+		})
+	} else {
+		close(weatherDone)
+	}
 
-	g.Go(func() (err error) {
-		defer func() {
-			if r := recover(); r != nil {
-				slog.Error("poi fetch panicked", "recover", r)
-				err = fmt.Errorf("poi fetch panicked: %v", r)
+	if called[ProviderPOI] {
+		g.Go(func() error {
+			defer rec.timed(ProviderPOI, time.Now())
+			defer func() {
+				if r := recover(); r != nil {
+					slog.Error("poi fetch panicked", "recover", r)
+					rec.failed(ProviderPOI, fmt.Errorf("poi fetch panicked: %v", r))
+				}
+			}()
+			pd, fetchErr := f.poi.Fetch(gCtx, city, country)
+			if fetchErr != nil {
+				logFetchError("poi", fetchErr, "city", city)
+				rec.failed(ProviderPOI, fetchErr)
+				return nil
 			}
-		}()
-		pd, fetchErr := f.poi.Fetch(gCtx, city)
-		if fetchErr != nil {
-			slog.Warn("poi fetch failed", "city", city, "err", fetchErr)
+			poiData = pd
 			return nil
-		}
-		poiData = pd
-		return nil
-	})
+		})
+	}
+
+	if called[ProviderCountry] {
+		g.Go(func() error {
+			defer close(countryDone)
+			if inferring {
+				<-weatherDone
+				if weatherData != nil {
+					if name, ok := countryName(weatherData.CountryCode); ok {
+						lookupCountry = name
+					}
+				}
+			}
+			if lookupCountry == "" {
+				slog.Info("country lookup skipped, no country", "city", city)
+				countrySkipped = true
+				rec.failed(ProviderCountry, ErrNoCountry)
+				return nil
+			}
+			defer rec.timed(ProviderCountry, time.Now())
+			defer func() {
+				if r := recover(); r != nil {
+					slog.Error("countries fetch panicked", "recover", r)
+					rec.failed(ProviderCountry, fmt.Errorf("countries fetch panicked: %v", r))
+				}
+			}()
+			cd, fetchErr := f.fetchCountry(gCtx, lookupCountry)
+			if fetchErr != nil {
+				logFetchError("countries", fetchErr, "country", lookupCountry)
+				rec.failed(ProviderCountry, fetchErr)
+				return nil
+			}
+			countryData = cd
+			return nil
+		})
+	} else {
+		close(countryDone)
+	}
 
-	g.Go(func() (err error) {
-		defer func() {
-			if r := recover(); r != nil {
-				slog.Error("countries fetch panicked", "recover", r)
-				err = fmt.Errorf("countries fetch panicked: %v", r)
+	if called[ProviderTeleport] {
+		g.Go(func() error {
+			defer rec.timed(ProviderTeleport, time.Now())
+			defer func() {
+				if r := recover(); r != nil {
+					slog.Error("teleport fetch panicked", "recover", r)
+					rec.failed(ProviderTeleport, fmt.Errorf("teleport fetch panicked: %v", r))
+				}
+			}()
+			qs, fetchErr := f.teleport.Fetch(gCtx, city)
+			if fetchErr != nil {
+				logFetchError("teleport", fetchErr, "city", city)
+				rec.failed(ProviderTeleport, fetchErr)
+				return nil
 			}
-		}()
-		cd, fetchErr := f.countries.Fetch(gCtx, country)
-		if fetchErr != nil {
-			slog.Warn("countries fetch failed", "country", country, "err", fetchErr)
+			qualityScores = qs
 			return nil
-		}
-		countryData = cd
-		return nil
-	})
+		})
+	}
 
-	g.Go(func() (err error) {
-		defer func() {
-			if r := recover(); r != nil {
-				slog.Error("teleport fetch panicked", "recover", r)
-				err = fmt.Errorf("teleport fetch panicked: %v", r)
+	if f.exchange != nil && called[ProviderCountry] {
+		g.Go(func() error {
+			<-countryDone
+			if countryData == nil || len(countryData.Currencies) == 0 {
+				return nil
+			}
+			defer rec.timed(SupplementExchange, time.Now())
+			defer func() {
+				if r := recover(); r != nil {
+					slog.Error("exchange rates fetch panicked", "recover", r)
+				}
+			}()
+			currencies := make([]string, 0, len(countryData.Currencies))
+			for code := range countryData.Currencies {
+				currencies = append(currencies, code)
 			}
-		}()
-		qs, fetchErr := f.teleport.Fetch(gCtx, city)
-		if fetchErr != nil {
-			slog.Warn("teleport fetch failed", "city", city, "err", fetchErr)
+			sort.Strings(currencies)
+			rates, fetchErr := f.exchange.Fetch(gCtx, currencies)
+			if fetchErr != nil {
+				logFetchError("exchange rates", fetchErr, "currencies", currencies)
+				return nil
+			}
+			exchangeRates = rates
 			return nil
-		}
-		qualityScores = qs
-		return nil
-	})
+		})
+	}
+
+	if f.alerts != nil && called[ProviderWeather] {
+		g.Go(func() error {
+			<-weatherDone
+			if weatherData == nil || weatherData.Coordinates == nil {
+				return nil
+			}
+			defer rec.timed(SupplementAlerts, time.Now())
+			defer func() {
+				if r := recover(); r != nil {
+					slog.Error("weather alerts fetch panicked", "recover", r)
+				}
+			}()
+			found, fetchErr := f.alerts.Fetch(gCtx, *weatherData.Coordinates)
+			if fetchErr != nil {
+				logFetchError("weather alerts", fetchErr, "city", city)
+				return nil
+			}
+			alerts = found
+			return nil
+		})
+	}
 
 	if err := g.Wait(); err != nil {
 		return nil, fmt.Errorf("fetching destination data for %s: %w", city, err)
 	}
+	if countrySkipped {
+		called[ProviderCountry] = false
+	}
 
-	return &DestinationData{
+	data := &DestinationData{
 		Weather:       weatherData,
 		PointsOfInt:   poiData,
 		Country:       countryData,
 		QualityScores: qualityScores,
+		ExchangeRates: exchangeRates,
+		Alerts:        alerts,
+	}
+	return &FetchResult{
+		Data:          data,
+		Timings:       rec.timings,
+		Errors:        rec.errs,
+		Statuses:      sourceStatuses(data, rec.errs, called),
+		WeatherSource: weatherSource,
+		Country:       lookupCountry,
 	}, nil
 }
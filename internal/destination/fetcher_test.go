@@ -1,17 +1,28 @@
 package destination_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/neexbeast/ygo-test/internal/destination"
+	"github.com/neexbeast/ygo-test/internal/destination/testutil"
+	"github.com/neexbeast/ygo-test/internal/metrics"
 )
 
 // buildTestFetcher creates a Fetcher that points all clients at the given test servers.
@@ -95,25 +106,12 @@ func teleportHandler(t *testing.T) http.HandlerFunc {
 }
 
 func TestFetchAll_Success(t *testing.T) {
-	wSrv := httptest.NewServer(weatherHandler(t))
-	defer wSrv.Close()
+	mp := testutil.NewMockProviders(t)
 
-	geoSrv := httptest.NewServer(geoHandler(t))
-	defer geoSrv.Close()
-
-	poiSrv := httptest.NewServer(poiHandler(t))
-	defer poiSrv.Close()
-
-	cSrv := httptest.NewServer(countriesHandler(t))
-	defer cSrv.Close()
-
-	tSrv := httptest.NewServer(teleportHandler(t))
-	defer tSrv.Close()
-
-	f := buildTestFetcher(wSrv.URL, geoSrv.URL, poiSrv.URL, cSrv.URL, tSrv.URL)
-
-	data, err := f.FetchAll(context.Background(), "Paris", "France")
+	res, err := mp.Fetcher.FetchAll(context.Background(), "Paris", "France")
 	require.NoError(t, err)
+	require.NotNil(t, res)
+	data := res.Data
 	require.NotNil(t, data)
 
 	require.NotNil(t, data.Weather)
@@ -130,33 +128,68 @@ func TestFetchAll_Success(t *testing.T) {
 	require.Len(t, data.QualityScores, 2)
 }
 
-func TestFetchAll_WeatherFails_PartialData(t *testing.T) {
-	badSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		http.Error(w, "internal error", http.StatusInternalServerError)
-	}))
-	defer badSrv.Close()
+func TestFetchProviders_SkipsOthers(t *testing.T) {
+	mp := testutil.NewMockProviders(t)
+	notCalled := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected call to %s", r.URL.Path)
+	})
+	mp.SetHandler(testutil.Geo, notCalled)
+	mp.SetHandler(testutil.Radius, notCalled)
+	mp.SetHandler(testutil.Countries, notCalled)
 
-	geoSrv := httptest.NewServer(geoHandler(t))
-	defer geoSrv.Close()
+	res, err := mp.Fetcher.FetchProviders(context.Background(), "Paris", "France",
+		[]string{destination.ProviderWeather, destination.ProviderTeleport})
+	require.NoError(t, err)
 
-	poiSrv := httptest.NewServer(poiHandler(t))
-	defer poiSrv.Close()
+	require.NotNil(t, res.Data.Weather)
+	assert.Len(t, res.Data.QualityScores, 2)
+	assert.Nil(t, res.Data.PointsOfInt)
+	assert.Nil(t, res.Data.Country)
+	assert.Equal(t, map[string]string{
+		destination.ProviderWeather:  destination.SourceOK,
+		destination.ProviderPOI:      destination.SourceSkipped,
+		destination.ProviderCountry:  destination.SourceSkipped,
+		destination.ProviderTeleport: destination.SourceOK,
+	}, res.Sources())
+	assert.Equal(t, destination.FetchComplete, res.Status())
+	assert.NotContains(t, res.Timings, destination.ProviderPOI)
+}
 
-	cSrv := httptest.NewServer(countriesHandler(t))
-	defer cSrv.Close()
+func TestFetchAll_RecordsTimings(t *testing.T) {
+	mp := testutil.NewMockProviders(t)
 
-	tSrv := httptest.NewServer(teleportHandler(t))
-	defer tSrv.Close()
+	res, err := mp.Fetcher.FetchAll(context.Background(), "Paris", "France")
+	require.NoError(t, err)
+	require.NotNil(t, res)
 
-	f := buildTestFetcher(badSrv.URL, geoSrv.URL, poiSrv.URL, cSrv.URL, tSrv.URL)
+	for _, p := range []string{
+		destination.ProviderWeather,
+		destination.ProviderPOI,
+		destination.ProviderCountry,
+		destination.ProviderTeleport,
+	} {
+		d, ok := res.Timings[p]
+		assert.True(t, ok, "missing timing for %s", p)
+		assert.Positive(t, d, "timing for %s should be non-zero", p)
+	}
+}
+
+func TestFetchAll_WeatherFails_PartialData(t *testing.T) {
+	mp := testutil.NewMockProviders(t)
+	mp.SetHandler(testutil.Weather, testutil.StatusHandler(http.StatusInternalServerError))
 
-	data, err := f.FetchAll(context.Background(), "Paris", "France")
+	res, err := mp.Fetcher.FetchAll(context.Background(), "Paris", "France")
 	require.NoError(t, err)
+	require.NotNil(t, res)
+	data := res.Data
 	require.NotNil(t, data)
 
 	assert.Nil(t, data.Weather, "weather should be nil on failure")
 	require.NotNil(t, data.Country)
 	require.Len(t, data.QualityScores, 2)
+
+	assert.Equal(t, 3, res.SuccessCount())
+	assert.Contains(t, res.Errors, destination.ProviderWeather)
 }
 
 func TestFetchAll_AllAPIsFail_ReturnsPartial(t *testing.T) {
@@ -167,14 +200,17 @@ func TestFetchAll_AllAPIsFail_ReturnsPartial(t *testing.T) {
 
 	f := buildTestFetcher(badSrv.URL, badSrv.URL, badSrv.URL, badSrv.URL, badSrv.URL)
 
-	data, err := f.FetchAll(context.Background(), "Paris", "France")
+	res, err := f.FetchAll(context.Background(), "Paris", "France")
 	require.NoError(t, err)
+	require.NotNil(t, res)
+	data := res.Data
 	require.NotNil(t, data)
 
 	assert.Nil(t, data.Weather)
 	assert.Nil(t, data.Country)
 	assert.Empty(t, data.PointsOfInt)
 	assert.Empty(t, data.QualityScores)
+	assert.Zero(t, res.SuccessCount())
 }
 
 func TestFetchAll_Timeout(t *testing.T) {
@@ -189,10 +225,57 @@ func TestFetchAll_Timeout(t *testing.T) {
 	defer cancel()
 
 	// With partial-failure mode, timeout causes all fetches to return nil — no error.
-	data, err := f.FetchAll(ctx, "Paris", "France")
+	res, err := f.FetchAll(ctx, "Paris", "France")
 	require.NoError(t, err)
+	require.NotNil(t, res)
+	data := res.Data
 	require.NotNil(t, data)
 	assert.Nil(t, data.Weather)
+	assert.ErrorIs(t, res.Errors[destination.ProviderWeather], destination.ErrFetchTimeout)
+	assert.False(t, res.Canceled())
+}
+
+func TestWeatherClient_CanceledContext(t *testing.T) {
+	srv := httptest.NewServer(weatherHandler(t))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c := destination.NewWeatherClientWithURL(srv.URL, "key")
+	_, err := c.Fetch(ctx, "Paris")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, destination.ErrFetchCanceled)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, errors.Is(err, destination.ErrFetchTimeout))
+}
+
+func TestWeatherClient_DeadlineExceeded(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	c := destination.NewWeatherClientWithURL(srv.URL, "key")
+	_, err := c.Fetch(ctx, "Paris")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, destination.ErrFetchTimeout)
+	assert.False(t, errors.Is(err, destination.ErrFetchCanceled))
+}
+
+func TestFetchAll_CanceledContext(t *testing.T) {
+	m := testutil.NewMockProviders(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	res, err := m.Fetcher.FetchAll(ctx, "Paris", "France")
+	require.NoError(t, err)
+	assert.True(t, res.Canceled())
+	assert.ErrorIs(t, res.Errors[destination.ProviderWeather], destination.ErrFetchCanceled)
 }
 
 func TestWeatherClient_Fetch(t *testing.T) {
@@ -207,6 +290,157 @@ func TestWeatherClient_Fetch(t *testing.T) {
 	assert.Equal(t, 60, wd.Humidity)
 }
 
+func TestWeatherClient_Timezone(t *testing.T) {
+	mp := testutil.NewMockProviders(t)
+	body := testutil.DefaultWeatherResponse()
+	body["timezone"] = 3600
+	mp.SetHandler(testutil.Weather, testutil.JSONHandler(body))
+
+	res, err := mp.Fetcher.FetchAll(context.Background(), "Paris", "France")
+	require.NoError(t, err)
+	require.NotNil(t, res.Data.Weather)
+	assert.Equal(t, &destination.Timezone{OffsetSeconds: 3600, UTCOffset: "+01:00"}, res.Data.Weather.Timezone)
+
+	// Without the field the timezone is unknown rather than UTC.
+	wd, err := destination.NewWeatherClientWithURL(mp.Weather.URL, "key").Fetch(context.Background(), "Paris")
+	require.NoError(t, err)
+	assert.NotNil(t, wd.Timezone)
+	mp.SetHandler(testutil.Weather, testutil.JSONHandler(testutil.DefaultWeatherResponse()))
+	wd, err = destination.NewWeatherClientWithURL(mp.Weather.URL, "key").Fetch(context.Background(), "Paris")
+	require.NoError(t, err)
+	assert.Nil(t, wd.Timezone)
+}
+
+func TestWeatherClient_SunriseSunset(t *testing.T) {
+	mp := testutil.NewMockProviders(t)
+	body := testutil.DefaultWeatherResponse()
+	body["sys"] = map[string]any{"country": "IN", "sunrise": 1718841600, "sunset": 1718890200}
+	body["timezone"] = 19800
+	mp.SetHandler(testutil.Weather, testutil.JSONHandler(body))
+
+	c := destination.NewWeatherClientWithURL(mp.Weather.URL, "key")
+	wd, err := c.Fetch(context.Background(), "Mumbai")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-06-20T05:30:00+05:30", wd.Sunrise)
+	assert.Equal(t, "2024-06-20T19:00:00+05:30", wd.Sunset)
+
+	// Without an offset the times are still reported, in UTC.
+	delete(body, "timezone")
+	wd, err = c.Fetch(context.Background(), "Mumbai")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-06-20T00:00:00Z", wd.Sunrise)
+	assert.Equal(t, "2024-06-20T13:30:00Z", wd.Sunset)
+
+	// Without the fields they are left out.
+	mp.SetHandler(testutil.Weather, testutil.JSONHandler(testutil.DefaultWeatherResponse()))
+	wd, err = c.Fetch(context.Background(), "Mumbai")
+	require.NoError(t, err)
+	assert.Empty(t, wd.Sunrise)
+	assert.Empty(t, wd.Sunset)
+}
+
+func TestNewTimezone(t *testing.T) {
+	assert.Equal(t, "+00:00", destination.NewTimezone(0).UTCOffset)
+	assert.Equal(t, "+05:30", destination.NewTimezone(19800).UTCOffset)
+	assert.Equal(t, "-03:30", destination.NewTimezone(-12600).UTCOffset)
+	assert.Equal(t, -12600, destination.NewTimezone(-12600).OffsetSeconds)
+}
+
+func TestSchemaDriftLogging(t *testing.T) {
+	var logs bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"main":{"temp":22.5,"humidity":60,"pressure_trend":"up"},"weather":[],"wind":{}}`))
+	}))
+	defer srv.Close()
+
+	c := destination.NewWeatherClientWithURL(srv.URL, "key")
+
+	_, err := c.Fetch(context.Background(), "Paris")
+	require.NoError(t, err)
+	assert.NotContains(t, logs.String(), "schema drift", "drift check is off by default")
+
+	c = destination.NewWeatherClientWithURL(srv.URL, "key",
+		destination.WithWeatherInstrumentation(destination.Instrumentation{SchemaDrift: true}))
+	wd, err := c.Fetch(context.Background(), "Paris")
+	require.NoError(t, err, "unknown fields must not fail the fetch")
+	assert.Equal(t, 22.5, wd.Temperature)
+	assert.Contains(t, logs.String(), "provider schema drift")
+	assert.Contains(t, logs.String(), "fields=main.pressure_trend")
+	assert.NotContains(t, logs.String(), "appid=key", "logged endpoint must not leak the API key")
+}
+
+func TestSchemaDriftLogging_ListsEveryUnknownField(t *testing.T) {
+	var logs bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"Main":{"temp":22.5,"humidity":60},"weather":[{"description":"clear sky","icon":"01d"}],"base":"stations"}`))
+	}))
+	defer srv.Close()
+
+	c := destination.NewWeatherClientWithURL(srv.URL, "key",
+		destination.WithWeatherInstrumentation(destination.Instrumentation{SchemaDrift: true}))
+	_, err := c.Fetch(context.Background(), "Paris")
+	require.NoError(t, err)
+	assert.Contains(t, logs.String(), "fields=base,weather[].icon", "keys are matched case-insensitively, as when decoding")
+}
+
+func TestWeatherClient_RejectsImplausible(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		ok   bool
+	}{
+		{name: "all zero", body: `{"main":{"temp":0,"humidity":0},"weather":[],"wind":{"speed":0}}`},
+		{name: "empty object", body: `{}`},
+		{name: "out of range", body: `{"main":{"temp":-273.15,"humidity":40}}`},
+		{name: "freezing without description", body: `{"main":{"temp":0,"humidity":85}}`, ok: true},
+		{name: "description only", body: `{"weather":[{"description":"fog"}]}`, ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			wd, err := destination.NewWeatherClientWithURL(srv.URL, "key").Fetch(context.Background(), "Paris")
+			if tt.ok {
+				require.NoError(t, err)
+				assert.NotNil(t, wd)
+				return
+			}
+			assert.ErrorIs(t, err, destination.ErrImplausibleWeather)
+			assert.Nil(t, wd)
+
+			// Validation can be turned off.
+			wd, err = destination.NewWeatherClientWithURL(srv.URL, "key", destination.WithWeatherValidation(false)).
+				Fetch(context.Background(), "Paris")
+			require.NoError(t, err)
+			assert.NotNil(t, wd)
+		})
+	}
+}
+
+func TestFetchAll_ImplausibleWeatherIsFailedProvider(t *testing.T) {
+	mp := testutil.NewMockProviders(t)
+	mp.SetHandler(testutil.Weather, testutil.JSONHandler(map[string]any{"main": map[string]any{"temp": 0, "humidity": 0}}))
+
+	res, err := mp.Fetcher.FetchAll(context.Background(), "Paris", "France")
+	require.NoError(t, err)
+	assert.ErrorIs(t, res.Errors[destination.ProviderWeather], destination.ErrImplausibleWeather)
+	assert.Nil(t, res.Data.Weather)
+	assert.Equal(t, destination.SourceError, res.Statuses[destination.ProviderWeather])
+}
+
 func TestWeatherClient_ServerError(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "err", http.StatusInternalServerError)
@@ -215,7 +449,76 @@ func TestWeatherClient_ServerError(t *testing.T) {
 
 	c := destination.NewWeatherClientWithURL(srv.URL, "key")
 	_, err := c.Fetch(context.Background(), "Paris")
+	assert.ErrorIs(t, err, destination.ErrProviderUnavailable)
+}
+
+func TestWeatherClient_StatusErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{status: http.StatusNotFound, want: destination.ErrCityNotFound},
+		{status: http.StatusUnauthorized, want: destination.ErrProviderUnauthorized},
+		{status: http.StatusForbidden, want: destination.ErrProviderUnauthorized},
+		{status: http.StatusTooManyRequests, want: destination.ErrProviderUnavailable},
+		{status: http.StatusBadGateway, want: destination.ErrProviderUnavailable},
+		{status: http.StatusBadRequest},
+	}
+
+	sentinels := []error{destination.ErrCityNotFound, destination.ErrProviderUnauthorized, destination.ErrProviderUnavailable}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			srv := httptest.NewServer(testutil.StatusHandler(tt.status))
+			defer srv.Close()
+
+			_, err := destination.NewWeatherClientWithURL(srv.URL, "key").Fetch(context.Background(), "Paris")
+			var statusErr *destination.StatusError
+			require.ErrorAs(t, err, &statusErr)
+			assert.Equal(t, tt.status, statusErr.StatusCode)
+			for _, sentinel := range sentinels {
+				assert.Equal(t, sentinel == tt.want, errors.Is(err, sentinel), sentinel.Error())
+			}
+		})
+	}
+}
+
+func TestWeatherClient_Unreachable(t *testing.T) {
+	srv := httptest.NewServer(testutil.StatusHandler(http.StatusOK))
+	srv.Close()
+
+	_, err := destination.NewWeatherClientWithURL(srv.URL, "key").Fetch(context.Background(), "Paris")
+	assert.ErrorIs(t, err, destination.ErrProviderUnavailable)
+	assert.False(t, errors.Is(err, destination.ErrFetchTimeout))
+}
+
+func TestProviderMetrics(t *testing.T) {
+	m := metrics.New()
+	in := destination.Instrumentation{Metrics: m}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "err", http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	ok := httptest.NewServer(countriesHandler(t))
+	defer ok.Close()
+
+	_, err := destination.NewWeatherClientWithURL(failing.URL, "key", destination.WithWeatherInstrumentation(in)).Fetch(context.Background(), "Paris")
 	require.Error(t, err)
+	countries := destination.NewCountriesClientWithURL(ok.URL, destination.WithCountriesInstrumentation(in))
+	_, err = countries.Fetch(context.Background(), "France")
+	require.NoError(t, err)
+	_, err = destination.NewCountriesClientWithURL(ok.URL).Fetch(context.Background(), "France")
+	require.NoError(t, err, "a client without metrics is not counted")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = countries.Fetch(ctx, "France")
+	require.Error(t, err)
+
+	assert.Equal(t, 1.0, promtestutil.ToFloat64(m.ProviderRequests.WithLabelValues(destination.ProviderWeather, "5xx")))
+	assert.Equal(t, 1.0, promtestutil.ToFloat64(m.ProviderRequests.WithLabelValues(destination.ProviderCountry, "2xx")))
+	assert.Equal(t, 1.0, promtestutil.ToFloat64(m.ProviderRequests.WithLabelValues(destination.ProviderCountry, metrics.ClassCanceled)))
+	assert.Equal(t, 3, promtestutil.CollectAndCount(m.ProviderRequests))
 }
 
 func TestPOIClient_Fetch(t *testing.T) {
@@ -226,12 +529,63 @@ func TestPOIClient_Fetch(t *testing.T) {
 	defer poiSrv.Close()
 
 	c := destination.NewPOIClientWithURLs(geoSrv.URL, poiSrv.URL, "key")
-	pois, err := c.Fetch(context.Background(), "Paris")
+	pois, err := c.Fetch(context.Background(), "Paris", "")
 	require.NoError(t, err)
 	require.Len(t, pois, 1)
 	assert.Equal(t, "Eiffel Tower", pois[0].Name)
 }
 
+func TestPOIClient_DedupesByName(t *testing.T) {
+	geoSrv := httptest.NewServer(geoHandler(t))
+	defer geoSrv.Close()
+
+	poiSrv := httptest.NewServer(testutil.JSONHandler(map[string]any{"features": []any{
+		map[string]any{"properties": map[string]any{"name": "Louvre", "kinds": "museums", "rate": 3}},
+		map[string]any{"properties": map[string]any{"name": "Eiffel Tower", "kinds": "towers", "rate": 5}},
+		map[string]any{"properties": map[string]any{"name": " louvre ", "kinds": "museums,art", "rate": 7}},
+		map[string]any{"properties": map[string]any{"name": "LOUVRE", "kinds": "museums", "rate": 2}},
+	}}))
+	defer poiSrv.Close()
+
+	c := destination.NewPOIClientWithURLs(geoSrv.URL, poiSrv.URL, "key", destination.WithPOILimit(2))
+	pois, err := c.Fetch(context.Background(), "Paris", "")
+	require.NoError(t, err)
+	assert.Equal(t, []destination.POI{
+		{Name: " louvre ", Kinds: "museums,art", Rate: 7},
+		{Name: "Eiffel Tower", Kinds: "towers", Rate: 5},
+	}, pois, "duplicates keep the best-rated entry and do not count towards the limit")
+}
+
+func TestPOIClient_LimitClampedToCap(t *testing.T) {
+	geoSrv := httptest.NewServer(geoHandler(t))
+	defer geoSrv.Close()
+
+	var requested string
+	poiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Query().Get("limit")
+		// Misbehave by returning more than asked for.
+		features := make([]string, 0, 10)
+		for i := 0; i < 10; i++ {
+			features = append(features, `{"properties":{"name":"POI `+strconv.Itoa(i)+`"}}`)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"features":[` + strings.Join(features, ",") + `]}`))
+	}))
+	defer poiSrv.Close()
+
+	c := destination.NewPOIClientWithURLs(geoSrv.URL, poiSrv.URL, "key",
+		destination.WithPOILimit(50), destination.WithMaxPOIs(3))
+	pois, err := c.Fetch(context.Background(), "Paris", "")
+	require.NoError(t, err)
+	assert.Equal(t, "3", requested, "requested limit above the cap is clamped")
+	assert.Len(t, pois, 3, "results beyond the cap are dropped")
+
+	c = destination.NewPOIClientWithURLs(geoSrv.URL, poiSrv.URL, "key")
+	_, err = c.Fetch(context.Background(), "Paris", "")
+	require.NoError(t, err)
+	assert.Equal(t, "5", requested, "default limit")
+}
+
 func TestPOIClient_GeoFails(t *testing.T) {
 	badSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "bad", http.StatusInternalServerError)
@@ -239,8 +593,130 @@ func TestPOIClient_GeoFails(t *testing.T) {
 	defer badSrv.Close()
 
 	c := destination.NewPOIClientWithURLs(badSrv.URL, badSrv.URL, "key")
-	_, err := c.Fetch(context.Background(), "Paris")
+	_, err := c.Fetch(context.Background(), "Paris", "")
+	require.Error(t, err)
+}
+
+func TestPOIClient_RadiusRetriedWithoutRegeocoding(t *testing.T) {
+	var geoCalls, radiusCalls atomic.Int32
+	geoSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		geoCalls.Add(1)
+		geoHandler(t)(w, r)
+	}))
+	defer geoSrv.Close()
+	poiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if radiusCalls.Add(1) == 1 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		poiHandler(t)(w, r)
+	}))
+	defer poiSrv.Close()
+
+	c := destination.NewPOIClientWithURLs(geoSrv.URL, poiSrv.URL, "key", destination.WithRadiusRetries(1))
+	pois, err := c.Fetch(context.Background(), "Paris", "")
+	require.NoError(t, err)
+	require.NotEmpty(t, pois)
+	assert.Equal(t, int32(1), geoCalls.Load())
+	assert.Equal(t, int32(2), radiusCalls.Load())
+}
+
+func TestPOIClient_RetriesExhausted(t *testing.T) {
+	var geoCalls atomic.Int32
+	geoSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		geoCalls.Add(1)
+		http.Error(w, "busy", http.StatusServiceUnavailable)
+	}))
+	defer geoSrv.Close()
+
+	c := destination.NewPOIClientWithURLs(geoSrv.URL, geoSrv.URL, "key", destination.WithGeocodeRetries(1))
+	_, err := c.Fetch(context.Background(), "Paris", "")
 	require.Error(t, err)
+	assert.ErrorIs(t, err, destination.ErrProviderUnavailable)
+	assert.Equal(t, int32(2), geoCalls.Load())
+}
+
+func TestPOIClient_CountryHint(t *testing.T) {
+	tests := []struct {
+		name        string
+		hint        string
+		wantCountry string
+	}{
+		{name: "iso code", hint: "us", wantCountry: "US"},
+		{name: "country name", hint: "United States", wantCountry: "US"},
+		{name: "unknown name ignored", hint: "Atlantis", wantCountry: ""},
+		{name: "no hint", hint: "", wantCountry: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotCountry string
+			geoSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotCountry = r.URL.Query().Get("country")
+				assert.Equal(t, "Springfield", r.URL.Query().Get("name"))
+				geoHandler(t)(w, r)
+			}))
+			defer geoSrv.Close()
+			poiSrv := httptest.NewServer(poiHandler(t))
+			defer poiSrv.Close()
+
+			c := destination.NewPOIClientWithURLs(geoSrv.URL, poiSrv.URL, "key")
+			_, err := c.Fetch(context.Background(), "Springfield", tt.hint)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCountry, gotCountry)
+		})
+	}
+}
+
+func TestPOIClient_RejectsZeroCoordinates(t *testing.T) {
+	var radiusCalled bool
+	geoSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"Nowhere"}`))
+	}))
+	defer geoSrv.Close()
+	poiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		radiusCalled = true
+		poiHandler(t)(w, r)
+	}))
+	defer poiSrv.Close()
+
+	c := destination.NewPOIClientWithURLs(geoSrv.URL, poiSrv.URL, "key", destination.WithGeocodeRetries(2))
+	_, err := c.Fetch(context.Background(), "Nowhere", "")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, destination.ErrCityNotFound)
+	assert.False(t, radiusCalled, "radius search must not run on 0,0")
+}
+
+func TestFetchAll_CountryReachesPOIAsCode(t *testing.T) {
+	tests := []struct {
+		country     string
+		wantGeo     string
+		wantCountry string
+	}{
+		{country: "France", wantGeo: "FR", wantCountry: "France"},
+		{country: "us", wantGeo: "US", wantCountry: "United States"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.country, func(t *testing.T) {
+			mp := testutil.NewMockProviders(t)
+			var geoCountry, lookedUp string
+			mp.SetHandler(testutil.Geo, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				geoCountry = r.URL.Query().Get("country")
+				testutil.JSONHandler(testutil.DefaultGeoResponse()).ServeHTTP(w, r)
+			}))
+			mp.SetHandler(testutil.Countries, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				lookedUp = path.Base(r.URL.Path)
+				testutil.JSONHandler(testutil.DefaultCountriesResponse()).ServeHTTP(w, r)
+			}))
+
+			_, err := mp.Fetcher.FetchAll(context.Background(), "Paris", tt.country)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantGeo, geoCountry, "the geocoder gets the ISO code")
+			assert.Equal(t, tt.wantCountry, lookedUp, "RestCountries gets the full name")
+		})
+	}
 }
 
 func TestCountriesClient_Fetch(t *testing.T) {
@@ -255,6 +731,57 @@ func TestCountriesClient_Fetch(t *testing.T) {
 	assert.Equal(t, "Paris", cd.Capital)
 }
 
+// southAfricaHandler serves a country with many languages, keyed so that key
+// order differs from name order.
+func southAfricaHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode([]map[string]any{{
+		"capital": []string{"Pretoria"},
+		"region":  "Africa",
+		"languages": map[string]string{
+			"afr": "Afrikaans", "eng": "English", "nbl": "Southern Ndebele", "nso": "Northern Sotho",
+			"sot": "Southern Sotho", "ssw": "Swazi", "tsn": "Tswana", "tso": "Tsonga",
+			"ven": "Venda", "xho": "Xhosa", "zul": "Zulu",
+		},
+	}})
+}
+
+func TestCountriesClient_MaxLanguages(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(southAfricaHandler))
+	defer srv.Close()
+
+	c := destination.NewCountriesClientWithURL(srv.URL, destination.WithMaxLanguages(4))
+	cd, err := c.Fetch(context.Background(), "South Africa")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Afrikaans", "English", "Northern Sotho", "Southern Ndebele"}, cd.Languages)
+
+	// The default keeps every language, still sorted.
+	cd, err = destination.NewCountriesClientWithURL(srv.URL).Fetch(context.Background(), "South Africa")
+	require.NoError(t, err)
+	assert.Len(t, cd.Languages, 11)
+	assert.IsNonDecreasing(t, cd.Languages)
+}
+
+func TestCountriesClient_LanguagesDeterministic(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(southAfricaHandler))
+	defer srv.Close()
+
+	c := destination.NewCountriesClientWithURL(srv.URL)
+	first, err := c.Fetch(context.Background(), "South Africa")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"Afrikaans", "English", "Northern Sotho", "Southern Ndebele", "Southern Sotho", "Swazi",
+		"Tsonga", "Tswana", "Venda", "Xhosa", "Zulu",
+	}, first.Languages)
+
+	// Map iteration order is randomised per range, so a few fetches would catch an unsorted result.
+	for range 20 {
+		cd, err := c.Fetch(context.Background(), "South Africa")
+		require.NoError(t, err)
+		require.Equal(t, first.Languages, cd.Languages)
+	}
+}
+
 func TestCountriesClient_EmptyResponse(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -267,6 +794,188 @@ func TestCountriesClient_EmptyResponse(t *testing.T) {
 	require.Error(t, err)
 }
 
+func exchangeRatesHandler(t *testing.T) http.HandlerFunc {
+	t.Helper()
+	return func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/USD", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"result":"success","base_code":"USD","rates":{"USD":1,"EUR":0.92,"GBP":0.79,"JPY":151.3}}`))
+	}
+}
+
+// memCountryCache is an in-memory CountryCache.
+type memCountryCache struct {
+	mu   sync.Mutex
+	data map[string]*destination.CountryData
+}
+
+func (c *memCountryCache) GetCountry(_ context.Context, country string) (*destination.CountryData, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.data[country], nil
+}
+
+func (c *memCountryCache) SetCountry(_ context.Context, country string, data *destination.CountryData) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[country] = data
+	return nil
+}
+
+func TestFetchAll_CountryCache(t *testing.T) {
+	mp := testutil.NewMockProviders(t)
+	var countryCalls atomic.Int32
+	mp.SetHandler(testutil.Countries, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		countryCalls.Add(1)
+		testutil.JSONHandler(testutil.DefaultCountriesResponse()).ServeHTTP(w, r)
+	}))
+
+	cc := &memCountryCache{data: map[string]*destination.CountryData{}}
+	f := destination.NewFetcherWithClients(
+		destination.NewWeatherClientWithURL(mp.Weather.URL, "test-key"),
+		destination.NewPOIClientWithURLs(mp.Geo.URL, mp.Radius.URL, "test-key"),
+		destination.NewCountriesClientWithURL(mp.Countries.URL),
+		destination.NewTeleportClientWithURL(mp.Teleport.URL),
+		destination.WithCountryCache(cc),
+	)
+
+	res, err := f.FetchAll(context.Background(), "Paris", "France")
+	require.NoError(t, err)
+	require.NotNil(t, res.Data.Country)
+	assert.Equal(t, int32(1), countryCalls.Load())
+	assert.Equal(t, res.Data.Country, cc.data["France"], "miss populates the cache")
+
+	res, err = f.FetchAll(context.Background(), "Lyon", "France")
+	require.NoError(t, err)
+	assert.Equal(t, "Europe", res.Data.Country.Region)
+	assert.Equal(t, int32(1), countryCalls.Load(), "hit skips RestCountries")
+}
+
+func TestExchangeRateClient_Fetch(t *testing.T) {
+	srv := httptest.NewServer(exchangeRatesHandler(t))
+	defer srv.Close()
+
+	c := destination.NewExchangeRateClientWithURL(srv.URL, "usd")
+	rates, err := c.Fetch(context.Background(), []string{"EUR", "XXX"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"USD": 1, "EUR": 0.92}, rates, "unquoted currencies are left out")
+
+	_, err = c.Fetch(context.Background(), []string{"XXX"})
+	require.Error(t, err, "no quoted currency at all")
+}
+
+func TestExchangeRateClient_ErrorResult(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"result":"error","error-type":"unsupported-code"}`))
+	}))
+	defer srv.Close()
+
+	_, err := destination.NewExchangeRateClientWithURL(srv.URL, "ZZZ").Fetch(context.Background(), []string{"EUR"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported-code")
+}
+
+func TestFetchAll_ExchangeRates(t *testing.T) {
+	mp := testutil.NewMockProviders(t)
+	rates := httptest.NewServer(exchangeRatesHandler(t))
+	defer rates.Close()
+
+	f := destination.NewFetcherWithClients(
+		destination.NewWeatherClientWithURL(mp.Weather.URL, "test-key"),
+		destination.NewPOIClientWithURLs(mp.Geo.URL, mp.Radius.URL, "test-key"),
+		destination.NewCountriesClientWithURL(mp.Countries.URL),
+		destination.NewTeleportClientWithURL(mp.Teleport.URL),
+		destination.WithExchangeRates(destination.NewExchangeRateClientWithURL(rates.URL, "USD")),
+	)
+
+	res, err := f.FetchAll(context.Background(), "Paris", "France")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"USD": 1, "EUR": 0.92}, res.Data.ExchangeRates, "rates for France's currency")
+	assert.Contains(t, res.Timings, destination.SupplementExchange)
+
+	// A failing rates API only leaves the rates out.
+	rates.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	})
+	res, err = f.FetchAll(context.Background(), "Paris", "France")
+	require.NoError(t, err)
+	assert.Nil(t, res.Data.ExchangeRates)
+	assert.Equal(t, destination.FetchComplete, res.Status(), "exchange rates are not a provider")
+	assert.NotNil(t, res.Data.Country)
+}
+
+// oneCallHandler serves a One Call response with a storm warning, checking it is
+// asked about the point the weather response reports and only for alerts.
+func oneCallHandler(t *testing.T) http.HandlerFunc {
+	t.Helper()
+	return func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "48.856600", r.URL.Query().Get("lat"))
+		assert.Equal(t, "2.352200", r.URL.Query().Get("lon"))
+		assert.Equal(t, "current,minutely,hourly,daily", r.URL.Query().Get("exclude"))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"lat": 48.8566,
+			"lon": 2.3522,
+			"alerts": []map[string]any{{
+				"sender_name": "METEO-FRANCE",
+				"event":       "Thunderstorm warning",
+				"start":       1718884800,
+				"end":         1718928000,
+				"description": "Severe thunderstorms expected.",
+			}},
+		})
+	}
+}
+
+func TestFetchAll_WeatherAlerts(t *testing.T) {
+	mp := testutil.NewMockProviders(t)
+	body := testutil.DefaultWeatherResponse()
+	body["coord"] = map[string]any{"lat": 48.8566, "lon": 2.3522}
+	mp.SetHandler(testutil.Weather, testutil.JSONHandler(body))
+	oneCall := httptest.NewServer(oneCallHandler(t))
+	defer oneCall.Close()
+
+	f := destination.NewFetcherWithClients(
+		destination.NewWeatherClientWithURL(mp.Weather.URL, "test-key"),
+		destination.NewPOIClientWithURLs(mp.Geo.URL, mp.Radius.URL, "test-key"),
+		destination.NewCountriesClientWithURL(mp.Countries.URL),
+		destination.NewTeleportClientWithURL(mp.Teleport.URL),
+		destination.WithWeatherAlerts(destination.NewAlertsClientWithURL(oneCall.URL, "test-key")),
+	)
+
+	res, err := f.FetchAll(context.Background(), "Paris", "France")
+	require.NoError(t, err)
+	assert.Equal(t, &destination.Coordinates{Lat: 48.8566, Lon: 2.3522}, res.Data.Weather.Coordinates)
+	assert.Equal(t, []destination.WeatherAlert{{
+		Event:       "Thunderstorm warning",
+		Description: "Severe thunderstorms expected.",
+		Start:       time.Date(2024, 6, 20, 12, 0, 0, 0, time.UTC),
+		End:         time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC),
+	}}, res.Data.Alerts)
+	assert.Contains(t, res.Timings, destination.SupplementAlerts)
+
+	// A failing One Call API only leaves the alerts out.
+	oneCall.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "subscription required", http.StatusUnauthorized)
+	})
+	res, err = f.FetchAll(context.Background(), "Paris", "France")
+	require.NoError(t, err)
+	assert.Nil(t, res.Data.Alerts)
+	assert.NotNil(t, res.Data.Weather)
+	assert.Equal(t, destination.FetchComplete, res.Status(), "alerts are not a provider")
+
+	// Without coordinates there is nothing to ask about.
+	mp.SetHandler(testutil.Weather, testutil.JSONHandler(testutil.DefaultWeatherResponse()))
+	oneCall.Config.Handler = http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		t.Error("One Call must not be called without coordinates")
+	})
+	res, err = f.FetchAll(context.Background(), "Paris", "France")
+	require.NoError(t, err)
+	assert.Nil(t, res.Data.Alerts)
+	assert.NotContains(t, res.Timings, destination.SupplementAlerts)
+}
+
 func TestTeleportClient_Fetch(t *testing.T) {
 	srv := httptest.NewServer(teleportHandler(t))
 	defer srv.Close()
@@ -285,5 +994,546 @@ func TestTeleportClient_NotFound(t *testing.T) {
 
 	c := destination.NewTeleportClientWithURL(srv.URL)
 	_, err := c.Fetch(context.Background(), "Unknown")
+	assert.ErrorIs(t, err, destination.ErrCityNotFound)
+}
+
+// paddedHandler serves body padded with trailing whitespace to exactly size bytes.
+func paddedHandler(body string, size int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body + strings.Repeat(" ", size-len(body))))
+	}
+}
+
+func TestWeatherClient_MaxBody(t *testing.T) {
+	const size = 2048
+	srv := httptest.NewServer(paddedHandler(`{"main":{"temp":22.5},"weather":[{"description":"clear sky"}]}`, size))
+	defer srv.Close()
+
+	// A body exactly at the limit is accepted.
+	wd, err := destination.NewWeatherClientWithURL(srv.URL, "key", destination.WithWeatherMaxBody(size)).
+		Fetch(context.Background(), "Paris")
+	require.NoError(t, err)
+	assert.Equal(t, 22.5, wd.Temperature)
+
+	// One byte over is not.
+	_, err = destination.NewWeatherClientWithURL(srv.URL, "key", destination.WithWeatherMaxBody(size-1)).
+		Fetch(context.Background(), "Paris")
+	assert.ErrorIs(t, err, destination.ErrResponseTooLarge)
+}
+
+func TestFetchAll_MaxBodyIsPerProvider(t *testing.T) {
+	const size = 4096
+	m := testutil.NewMockProviders(t)
+	m.SetHandler(testutil.Teleport, paddedHandler(`{"categories":[{"name":"Safety","score_out_of_10":7.5}]}`, size))
+
+	f := destination.NewFetcherWithClients(
+		destination.NewWeatherClientWithURL(m.Weather.URL, "key", destination.WithWeatherMaxBody(size-1)),
+		destination.NewPOIClientWithURLs(m.Geo.URL, m.Radius.URL, "key"),
+		destination.NewCountriesClientWithURL(m.Countries.URL),
+		destination.NewTeleportClientWithURL(m.Teleport.URL, destination.WithTeleportMaxBody(size-1)),
+	)
+
+	res, err := f.FetchAll(context.Background(), "Paris", "France")
+	require.NoError(t, err)
+	assert.ErrorIs(t, res.Errors[destination.ProviderTeleport], destination.ErrResponseTooLarge)
+	assert.NoError(t, res.Errors[destination.ProviderWeather], "a small weather body is within the weather limit")
+}
+
+func TestQuotaTracking(t *testing.T) {
+	m := metrics.New()
+	in := destination.Instrumentation{Metrics: m, TrackQuota: true}
+
+	weather := weatherHandler(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "940")
+		w.Header().Set("X-RateLimit-Limit", "1000")
+		weather.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+	teleport := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+	}))
+	defer teleport.Close()
+
+	_, err := destination.NewWeatherClientWithURL(srv.URL, "key", destination.WithWeatherInstrumentation(in)).Fetch(context.Background(), "Paris")
+	require.NoError(t, err)
+	_, err = destination.NewTeleportClientWithURL(teleport.URL, destination.WithTeleportInstrumentation(in)).Fetch(context.Background(), "Paris")
 	require.Error(t, err)
+
+	assert.Equal(t, 940.0, promtestutil.ToFloat64(m.ProviderQuotaRemaining.WithLabelValues(destination.ProviderWeather)))
+	assert.Equal(t, 1000.0, promtestutil.ToFloat64(m.ProviderQuotaLimit.WithLabelValues(destination.ProviderWeather)))
+	assert.Equal(t, 0.0, promtestutil.ToFloat64(m.ProviderQuotaRemaining.WithLabelValues(destination.ProviderTeleport)), "a rejected request still reports its quota")
+	assert.Equal(t, 2, promtestutil.CollectAndCount(m.ProviderQuotaRemaining))
+	assert.Equal(t, 1, promtestutil.CollectAndCount(m.ProviderQuotaLimit), "teleport sent no limit")
+}
+
+func TestQuotaTracking_Disabled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "12")
+		testutil.JSONHandler(testutil.DefaultTeleportResponse()).ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	m := metrics.New()
+	_, err := destination.NewTeleportClientWithURL(srv.URL,
+		destination.WithTeleportInstrumentation(destination.Instrumentation{Metrics: m}),
+	).Fetch(context.Background(), "Paris")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, promtestutil.CollectAndCount(m.ProviderRequests), "the request is still counted")
+	assert.Zero(t, promtestutil.CollectAndCount(m.ProviderQuotaRemaining), "quotas are only recorded with TrackQuota")
+}
+
+func TestSetMaxOutboundConcurrency_CapsInFlightRequests(t *testing.T) {
+	const limit = 2
+	destination.SetMaxOutboundConcurrency(limit)
+	t.Cleanup(func() { destination.SetMaxOutboundConcurrency(0) })
+
+	var inFlight, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		weatherHandler(t)(w, r)
+	}))
+	defer srv.Close()
+
+	c := destination.NewWeatherClientWithURL(srv.URL, "key")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.Fetch(context.Background(), "Paris")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, peak.Load(), int32(limit))
+	assert.Equal(t, int32(limit), peak.Load(), "requests should still run concurrently up to the limit")
+}
+
+func TestSetMaxOutboundConcurrency_ContextCancelledWhileWaiting(t *testing.T) {
+	destination.SetMaxOutboundConcurrency(1)
+	t.Cleanup(func() { destination.SetMaxOutboundConcurrency(0) })
+
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		weatherHandler(t)(w, r)
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c := destination.NewWeatherClientWithURL(srv.URL, "key")
+
+	// Hold the only slot.
+	go func() { _, _ = c.Fetch(context.Background(), "Paris") }()
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := c.Fetch(ctx, "Paris")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "waiting for outbound slot")
+}
+
+func TestFetchResult_Sources(t *testing.T) {
+	res := &destination.FetchResult{Errors: map[string]error{destination.ProviderPOI: errors.New("down")}}
+	assert.Equal(t, map[string]string{
+		destination.ProviderWeather:  destination.SourceOK,
+		destination.ProviderPOI:      destination.SourceError,
+		destination.ProviderCountry:  destination.SourceOK,
+		destination.ProviderTeleport: destination.SourceOK,
+	}, res.Sources())
+
+	var nilRes *destination.FetchResult
+	for _, status := range nilRes.Sources() {
+		assert.Equal(t, destination.SourceError, status)
+	}
+}
+
+func TestFetchResult_Status(t *testing.T) {
+	down := errors.New("down")
+	assert.Equal(t, destination.FetchComplete, (&destination.FetchResult{}).Status())
+	assert.Equal(t, destination.FetchPartial, (&destination.FetchResult{
+		Errors: map[string]error{destination.ProviderPOI: down},
+	}).Status())
+	assert.Equal(t, destination.FetchFailed, (&destination.FetchResult{Errors: map[string]error{
+		destination.ProviderWeather:  down,
+		destination.ProviderPOI:      down,
+		destination.ProviderCountry:  down,
+		destination.ProviderTeleport: down,
+	}}).Status())
+
+	var nilRes *destination.FetchResult
+	assert.Equal(t, destination.FetchFailed, nilRes.Status())
+}
+
+func TestFetchAll_SourceStatuses(t *testing.T) {
+	all := func(status string) map[string]string {
+		m := map[string]string{}
+		for _, p := range destination.Providers() {
+			m[p] = status
+		}
+		return m
+	}
+
+	tests := []struct {
+		name  string
+		setup func(mp *testutil.MockProviders)
+		want  map[string]string
+	}{
+		{
+			name:  "all succeed",
+			setup: func(*testutil.MockProviders) {},
+			want:  all(destination.SourceOK),
+		},
+		{
+			name: "all fail",
+			setup: func(mp *testutil.MockProviders) {
+				for _, e := range []testutil.Endpoint{testutil.Weather, testutil.Geo, testutil.Countries, testutil.Teleport} {
+					mp.SetHandler(e, testutil.StatusHandler(http.StatusInternalServerError))
+				}
+			},
+			want: all(destination.SourceError),
+		},
+		{
+			name: "mixed, with empty successes",
+			setup: func(mp *testutil.MockProviders) {
+				mp.SetHandler(testutil.Weather, testutil.StatusHandler(http.StatusInternalServerError))
+				mp.SetHandler(testutil.Radius, testutil.JSONHandler(map[string]any{"features": []any{}}))
+				mp.SetHandler(testutil.Teleport, testutil.JSONHandler(map[string]any{"categories": []any{}}))
+			},
+			want: map[string]string{
+				destination.ProviderWeather:  destination.SourceError,
+				destination.ProviderPOI:      destination.SourceEmpty,
+				destination.ProviderCountry:  destination.SourceOK,
+				destination.ProviderTeleport: destination.SourceEmpty,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mp := testutil.NewMockProviders(t)
+			tt.setup(mp)
+
+			res, err := mp.Fetcher.FetchAll(context.Background(), "Paris", "France")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, res.Statuses)
+			assert.Equal(t, tt.want, res.Sources())
+		})
+	}
+}
+
+// weatherFunc adapts a function to destination.WeatherProvider.
+type weatherFunc func(ctx context.Context, city string) (*destination.WeatherData, error)
+
+func (f weatherFunc) Fetch(ctx context.Context, city string) (*destination.WeatherData, error) {
+	return f(ctx, city)
+}
+
+// countingWeather returns a weather source that reports temp, or fails with err, and counts calls.
+func countingWeather(name string, temp float64, err error, calls *atomic.Int32) destination.WeatherSource {
+	return destination.WeatherSource{Name: name, Provider: weatherFunc(func(_ context.Context, _ string) (*destination.WeatherData, error) {
+		calls.Add(1)
+		if err != nil {
+			return nil, err
+		}
+		return &destination.WeatherData{Temperature: temp}, nil
+	})}
+}
+
+// panickingTeleport is a quality score source that panics on every call.
+type panickingTeleport struct{}
+
+func (panickingTeleport) Fetch(_ context.Context, _ string) ([]destination.QualityScore, error) {
+	panic("teleport exploded")
+}
+
+func TestFetchAll_PanickingProviderIsNonFatal(t *testing.T) {
+	mp := testutil.NewMockProviders(t)
+	f := destination.NewFetcherWithClients(
+		weatherFunc(func(_ context.Context, _ string) (*destination.WeatherData, error) { panic("weather exploded") }),
+		destination.NewPOIClientWithURLs(mp.Geo.URL, mp.Radius.URL, "test-key"),
+		destination.NewCountriesClientWithURL(mp.Countries.URL),
+		panickingTeleport{},
+		destination.WithCountryInference(true), // the country lookup waits on the panicking weather call
+	)
+
+	res, err := f.FetchAll(context.Background(), "Paris", "")
+	require.NoError(t, err)
+	require.NotNil(t, res)
+
+	require.Len(t, res.Data.PointsOfInt, 1)
+	require.NotNil(t, res.Data.Country)
+	assert.Nil(t, res.Data.Weather)
+	assert.Nil(t, res.Data.QualityScores)
+	assert.ErrorContains(t, res.Errors[destination.ProviderWeather], "weather fetch panicked: weather exploded")
+	assert.ErrorContains(t, res.Errors[destination.ProviderTeleport], "teleport fetch panicked: teleport exploded")
+	assert.Equal(t, destination.FetchPartial, res.Status())
+}
+
+func TestFetchAll_WeatherPriority(t *testing.T) {
+	tests := []struct {
+		name          string
+		primaryErr    error
+		priority      []string
+		wantTemp      float64
+		wantSource    string
+		wantBackupHit int32
+	}{
+		{name: "primary succeeds", wantTemp: 10, wantSource: "primary", wantBackupHit: 0},
+		{name: "primary fails, fallback used", primaryErr: errors.New("down"), wantTemp: 20, wantSource: "backup", wantBackupHit: 1},
+		{name: "priority reorders sources", priority: []string{"backup", "primary"}, wantTemp: 20, wantSource: "backup", wantBackupHit: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testutil.NewMockProviders(t)
+			var primaryCalls, backupCalls atomic.Int32
+
+			f := destination.NewFetcherWithClients(
+				nil,
+				destination.NewPOIClientWithURLs(m.Geo.URL, m.Radius.URL, "test-key"),
+				destination.NewCountriesClientWithURL(m.Countries.URL),
+				destination.NewTeleportClientWithURL(m.Teleport.URL),
+				destination.WithWeatherSources(
+					countingWeather("primary", 10, tt.primaryErr, &primaryCalls),
+					countingWeather("backup", 20, nil, &backupCalls),
+				),
+				destination.WithWeatherPriority(tt.priority...),
+			)
+
+			res, err := f.FetchAll(context.Background(), "Paris", "France")
+			require.NoError(t, err)
+			require.NotNil(t, res.Data.Weather)
+			assert.Equal(t, tt.wantTemp, res.Data.Weather.Temperature)
+			assert.Equal(t, tt.wantSource, res.WeatherSource)
+			assert.Equal(t, tt.wantBackupHit, backupCalls.Load())
+			assert.NotContains(t, res.Errors, destination.ProviderWeather)
+		})
+	}
+}
+
+func TestFetchAll_AllWeatherSourcesFail(t *testing.T) {
+	m := testutil.NewMockProviders(t)
+	var calls atomic.Int32
+
+	f := destination.NewFetcherWithClients(
+		nil,
+		destination.NewPOIClientWithURLs(m.Geo.URL, m.Radius.URL, "test-key"),
+		destination.NewCountriesClientWithURL(m.Countries.URL),
+		destination.NewTeleportClientWithURL(m.Teleport.URL),
+		destination.WithWeatherSources(
+			countingWeather("primary", 0, errors.New("primary down"), &calls),
+			countingWeather("backup", 0, errors.New("backup down"), &calls),
+		),
+	)
+
+	res, err := f.FetchAll(context.Background(), "Paris", "France")
+	require.NoError(t, err)
+	assert.Nil(t, res.Data.Weather)
+	assert.Empty(t, res.WeatherSource)
+	require.Error(t, res.Errors[destination.ProviderWeather])
+	assert.Contains(t, res.Errors[destination.ProviderWeather].Error(), "primary: primary down")
+	assert.Contains(t, res.Errors[destination.ProviderWeather].Error(), "backup: backup down")
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestFetchAll_InfersCountryFromWeather(t *testing.T) {
+	weatherSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"main": map[string]any{"temp": 22.5},
+			"sys":  map[string]any{"country": "FR"},
+		})
+	}))
+	defer weatherSrv.Close()
+
+	var lookedUp atomic.Value
+	countriesSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookedUp.Store(r.URL.Path)
+		countriesHandler(t)(w, r)
+	}))
+	defer countriesSrv.Close()
+
+	geoSrv := httptest.NewServer(geoHandler(t))
+	defer geoSrv.Close()
+	poiSrv := httptest.NewServer(poiHandler(t))
+	defer poiSrv.Close()
+	teleportSrv := httptest.NewServer(teleportHandler(t))
+	defer teleportSrv.Close()
+
+	build := func(opts ...destination.FetcherOption) *destination.Fetcher {
+		return destination.NewFetcherWithClients(
+			destination.NewWeatherClientWithURL(weatherSrv.URL, "test-key"),
+			destination.NewPOIClientWithURLs(geoSrv.URL, poiSrv.URL, "test-key"),
+			destination.NewCountriesClientWithURL(countriesSrv.URL),
+			destination.NewTeleportClientWithURL(teleportSrv.URL),
+			opts...,
+		)
+	}
+
+	t.Run("derived when enabled", func(t *testing.T) {
+		res, err := build(destination.WithCountryInference(true)).FetchAll(context.Background(), "Lyon", "")
+		require.NoError(t, err)
+		assert.Equal(t, "/France", lookedUp.Load())
+		assert.Equal(t, "France", res.Country)
+		assert.Equal(t, "FR", res.Data.Weather.CountryCode)
+		require.NotNil(t, res.Data.Country)
+	})
+
+	t.Run("caller country wins", func(t *testing.T) {
+		res, err := build(destination.WithCountryInference(true)).FetchAll(context.Background(), "Lyon", "Belgium")
+		require.NoError(t, err)
+		assert.Equal(t, "/Belgium", lookedUp.Load())
+		assert.Equal(t, "Belgium", res.Country)
+	})
+
+	t.Run("city name when disabled", func(t *testing.T) {
+		res, err := build().FetchAll(context.Background(), "Lyon", "")
+		require.NoError(t, err)
+		assert.Equal(t, "/Lyon", lookedUp.Load())
+		assert.Equal(t, "Lyon", res.Country)
+	})
+
+	t.Run("skipped without fallback", func(t *testing.T) {
+		lookedUp.Store("")
+		res, err := build(destination.WithCountryFallback(false)).FetchAll(context.Background(), "Lyon", "")
+		require.NoError(t, err)
+		assert.Empty(t, lookedUp.Load(), "RestCountries must not be queried with the city name")
+		assert.Empty(t, res.Country)
+		assert.Nil(t, res.Data.Country)
+		assert.Equal(t, destination.SourceSkipped, res.Sources()[destination.ProviderCountry])
+		assert.ErrorIs(t, res.Errors[destination.ProviderCountry], destination.ErrNoCountry)
+		assert.Equal(t, 3, res.SuccessCount(), "missing country data is not a success")
+		assert.Equal(t, destination.FetchPartial, res.Status())
+		assert.NotContains(t, res.Timings, destination.ProviderCountry)
+	})
+
+	t.Run("caller or inferred country without fallback", func(t *testing.T) {
+		res, err := build(destination.WithCountryFallback(false)).FetchAll(context.Background(), "Lyon", "Belgium")
+		require.NoError(t, err)
+		assert.Equal(t, "/Belgium", lookedUp.Load())
+		assert.Equal(t, destination.SourceOK, res.Sources()[destination.ProviderCountry])
+
+		res, err = build(destination.WithCountryFallback(false), destination.WithCountryInference(true)).
+			FetchAll(context.Background(), "Lyon", "")
+		require.NoError(t, err)
+		assert.Equal(t, "/France", lookedUp.Load())
+		assert.Equal(t, "France", res.Country)
+	})
+}
+
+func TestProbe_HealthyProviders(t *testing.T) {
+	mp := testutil.NewMockProviders(t)
+
+	assert.Nil(t, mp.Fetcher.Probe(context.Background()))
+}
+
+func TestProbe_SchemaMismatch(t *testing.T) {
+	mp := testutil.NewMockProviders(t)
+	// Wrong URLs: both answer 200 with JSON from some other API.
+	mp.SetHandler(testutil.Weather, testutil.JSONHandler(map[string]any{"status": "ok", "items": []int{1, 2}}))
+	mp.SetHandler(testutil.Teleport, testutil.JSONHandler(map[string]any{"data": map[string]any{}}))
+	mp.SetHandler(testutil.Countries, testutil.StatusHandler(http.StatusNotFound))
+
+	var logs bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	problems := mp.Fetcher.Probe(context.Background())
+
+	require.Len(t, problems, 3)
+	assert.ErrorIs(t, problems[destination.ProviderWeather], destination.ErrImplausibleWeather, "an all-zero weather body is rejected by the client")
+	assert.Contains(t, problems[destination.ProviderTeleport].Error(), "missing expected fields")
+	assert.ErrorIs(t, problems[destination.ProviderCountry], destination.ErrCityNotFound)
+	assert.NotContains(t, problems, destination.ProviderPOI)
+
+	assert.Contains(t, logs.String(), "provider probe: weather is misbehaving")
+	assert.Contains(t, logs.String(), "level=ERROR")
+}
+
+func TestProbeProvider(t *testing.T) {
+	mp := testutil.NewMockProviders(t)
+	var teleportCalls atomic.Int32
+	mp.SetHandler(testutil.Teleport, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		teleportCalls.Add(1)
+		testutil.JSONHandler(testutil.DefaultTeleportResponse()).ServeHTTP(w, r)
+	}))
+
+	res, err := mp.Fetcher.ProbeProvider(context.Background(), destination.ProviderWeather)
+	require.NoError(t, err)
+	assert.NoError(t, res.Err)
+	assert.Equal(t, destination.ProviderWeather, res.Provider)
+	require.IsType(t, &destination.WeatherData{}, res.Result)
+	assert.NotEmpty(t, res.Result.(*destination.WeatherData).Description)
+	assert.Zero(t, teleportCalls.Load(), "only the probed provider is called")
+}
+
+func TestProbe_UsesProbeCity(t *testing.T) {
+	mp := testutil.NewMockProviders(t)
+	var weatherCities, countryNames []string
+	var mu sync.Mutex
+	mp.SetHandler(testutil.Weather, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		weatherCities = append(weatherCities, r.URL.Query().Get("q"))
+		mu.Unlock()
+		testutil.JSONHandler(testutil.DefaultWeatherResponse()).ServeHTTP(w, r)
+	}))
+	mp.SetHandler(testutil.Countries, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		countryNames = append(countryNames, path.Base(r.URL.Path))
+		mu.Unlock()
+		testutil.JSONHandler(testutil.DefaultCountriesResponse()).ServeHTTP(w, r)
+	}))
+
+	f := destination.NewFetcherWithClients(
+		destination.NewWeatherClientWithURL(mp.Weather.URL, "test-key"),
+		destination.NewPOIClientWithURLs(mp.Geo.URL, mp.Radius.URL, "test-key"),
+		destination.NewCountriesClientWithURL(mp.Countries.URL),
+		destination.NewTeleportClientWithURL(mp.Teleport.URL),
+		destination.WithProbeCity("Paris", "France"),
+	)
+
+	assert.Empty(t, f.Probe(context.Background()))
+	_, err := f.ProbeProvider(context.Background(), destination.ProviderWeather)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Paris", "Paris"}, weatherCities, "startup probe and provider probe both use the probe city")
+	assert.Equal(t, []string{"France"}, countryNames)
+}
+
+func TestProbeProvider_Failing(t *testing.T) {
+	mp := testutil.NewMockProviders(t)
+	mp.SetHandler(testutil.Countries, testutil.StatusHandler(http.StatusServiceUnavailable))
+
+	res, err := mp.Fetcher.ProbeProvider(context.Background(), destination.ProviderCountry)
+	require.NoError(t, err)
+	require.Error(t, res.Err)
+	var statusErr *destination.StatusError
+	require.ErrorAs(t, res.Err, &statusErr)
+	assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+	assert.ErrorIs(t, res.Err, destination.ErrProviderUnavailable)
+	assert.Nil(t, res.Result)
+}
+
+func TestProbeProvider_Unknown(t *testing.T) {
+	mp := testutil.NewMockProviders(t)
+
+	_, err := mp.Fetcher.ProbeProvider(context.Background(), "weather2")
+	assert.ErrorIs(t, err, destination.ErrUnknownProvider)
 }
@@ -0,0 +1,65 @@
+package destination
+
+import "strings"
+
+// regionNames maps a language code to translations of the English region names
+// returned by RestCountries. Regions without a translation are left as-is.
+var regionNames = map[string]map[string]string{
+	"de": {
+		"Africa":    "Afrika",
+		"Americas":  "Amerika",
+		"Antarctic": "Antarktis",
+		"Asia":      "Asien",
+		"Europe":    "Europa",
+		"Oceania":   "Ozeanien",
+	},
+	"es": {
+		"Africa":    "África",
+		"Americas":  "América",
+		"Antarctic": "Antártida",
+		"Asia":      "Asia",
+		"Europe":    "Europa",
+		"Oceania":   "Oceanía",
+	},
+	"fr": {
+		"Africa":    "Afrique",
+		"Americas":  "Amériques",
+		"Antarctic": "Antarctique",
+		"Asia":      "Asie",
+		"Europe":    "Europe",
+		"Oceania":   "Océanie",
+	},
+}
+
+// SupportsLanguage reports whether region names can be localized into lang.
+func SupportsLanguage(lang string) bool {
+	_, ok := regionNames[strings.ToLower(lang)]
+	return ok
+}
+
+// LocalizeRegion translates an English region name into lang.
+// Unknown languages or regions return the region unchanged.
+func LocalizeRegion(region, lang string) string {
+	names, ok := regionNames[strings.ToLower(lang)]
+	if !ok {
+		return region
+	}
+	if localized, ok := names[region]; ok {
+		return localized
+	}
+	return region
+}
+
+// Localized returns a copy of d with country-level names translated into lang.
+// The receiver is never modified, so cached or stored values stay in English.
+func (d *DestinationData) Localized(lang string) *DestinationData {
+	if d == nil || d.Country == nil || !SupportsLanguage(lang) {
+		return d
+	}
+
+	out := *d
+	country := *d.Country
+	country.Region = LocalizeRegion(country.Region, lang)
+	out.Country = &country
+	return &out
+}
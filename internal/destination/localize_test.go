@@ -0,0 +1,41 @@
+package destination_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neexbeast/ygo-test/internal/destination"
+)
+
+func TestLocalizeRegion(t *testing.T) {
+	assert.Equal(t, "Europa", destination.LocalizeRegion("Europe", "de"))
+	assert.Equal(t, "Amériques", destination.LocalizeRegion("Americas", "FR"))
+	assert.Equal(t, "Europe", destination.LocalizeRegion("Europe", "xx"), "unknown language is a no-op")
+	assert.Equal(t, "Polar", destination.LocalizeRegion("Polar", "de"), "unknown region is a no-op")
+}
+
+func TestDestinationData_Localized(t *testing.T) {
+	data := &destination.DestinationData{
+		Country: &destination.CountryData{Region: "Asia", Capital: "Tokyo"},
+	}
+
+	got := data.Localized("es")
+	require.NotNil(t, got)
+	require.NotNil(t, got.Country)
+	assert.Equal(t, "Asia", got.Country.Region)
+
+	got = data.Localized("fr")
+	assert.Equal(t, "Asie", got.Country.Region)
+	assert.Equal(t, "Tokyo", got.Country.Capital)
+	assert.Equal(t, "Asia", data.Country.Region, "original must not be modified")
+}
+
+func TestDestinationData_Localized_NilSafe(t *testing.T) {
+	var nilData *destination.DestinationData
+	assert.Nil(t, nilData.Localized("fr"))
+
+	noCountry := &destination.DestinationData{}
+	assert.Same(t, noCountry, noCountry.Localized("fr"))
+}
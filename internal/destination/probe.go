@@ -0,0 +1,143 @@
+package destination
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// The city Probe and ProbeProvider fetch unless WithProbeCity overrides it.
+// Every provider has rich, stable data for it.
+const (
+	DefaultProbeCity    = "London"
+	DefaultProbeCountry = "United Kingdom"
+)
+
+// Probe fetches a well-known city and checks that each provider's parsed result
+// has the fields a working provider always fills in. A provider that errors, or
+// answers with a body that decodes to empty fields (typically a misconfigured URL
+// pointing at a different API), is logged as an error and returned in the map,
+// keyed by provider name. A nil map means every provider looks healthy.
+func (f *Fetcher) Probe(ctx context.Context) map[string]error {
+	res, err := f.FetchAll(ctx, f.probeCity, f.probeCountry)
+	if err != nil {
+		slog.Error("provider probe failed", "err", err)
+		problems := make(map[string]error, len(Providers()))
+		for _, p := range Providers() {
+			problems[p] = err
+		}
+		return problems
+	}
+
+	var problems map[string]error
+	for _, p := range Providers() {
+		perr := res.Errors[p]
+		if perr == nil {
+			perr = probeCheck(p, res.Data)
+		}
+		if perr == nil {
+			continue
+		}
+		slog.Error("provider probe: "+p+" is misbehaving; check its URL and API key", "city", f.probeCity, "err", perr)
+		if problems == nil {
+			problems = make(map[string]error)
+		}
+		problems[p] = perr
+	}
+	return problems
+}
+
+// ErrUnknownProvider is returned by ProbeProvider for a name not in Providers.
+var ErrUnknownProvider = errors.New("unknown provider")
+
+// ProbeResult is the outcome of probing one provider.
+type ProbeResult struct {
+	Provider string
+	// Latency is how long the provider took to answer, or to fail.
+	Latency time.Duration
+	// Err is why the probe failed, or nil if the provider looks healthy.
+	Err error
+	// Result is an excerpt of what the provider returned: its section of the
+	// destination data, with points of interest cut to the first few. It is nil
+	// when the provider returned nothing.
+	Result any
+}
+
+// probeSnippetPOIs is how many points of interest a ProbeResult keeps.
+const probeSnippetPOIs = 3
+
+// ProbeProvider fetches the probe city from provider alone and checks its result
+// as Probe does. Nothing is stored or cached. The error is ErrUnknownProvider for
+// a name not in Providers; a failing provider is reported in the result's Err.
+func (f *Fetcher) ProbeProvider(ctx context.Context, provider string) (*ProbeResult, error) {
+	if !slices.Contains(Providers(), provider) {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProvider, provider)
+	}
+
+	res, err := f.FetchProviders(ctx, f.probeCity, f.probeCountry, []string{provider})
+	if err != nil {
+		return nil, fmt.Errorf("probing %s: %w", provider, err)
+	}
+
+	pr := &ProbeResult{Provider: provider, Latency: res.Timings[provider], Err: res.Errors[provider]}
+	if pr.Err == nil {
+		pr.Err = probeCheck(provider, res.Data)
+	}
+	pr.Result = probeSnippet(provider, res.Data)
+	return pr, nil
+}
+
+// probeSnippet returns provider's section of data, or nil if it is empty.
+func probeSnippet(provider string, data *DestinationData) any {
+	switch provider {
+	case ProviderWeather:
+		if data.Weather != nil {
+			return data.Weather
+		}
+	case ProviderPOI:
+		if len(data.PointsOfInt) > 0 {
+			return data.PointsOfInt[:min(len(data.PointsOfInt), probeSnippetPOIs)]
+		}
+	case ProviderCountry:
+		if data.Country != nil {
+			return data.Country
+		}
+	case ProviderTeleport:
+		if len(data.QualityScores) > 0 {
+			return data.QualityScores
+		}
+	}
+	return nil
+}
+
+// errProbeShape reports a provider response that parsed but lacks expected fields.
+var errProbeShape = errors.New("response is missing expected fields")
+
+// probeCheck verifies the fields provider p always fills in for the probe city.
+func probeCheck(provider string, data *DestinationData) error {
+	switch provider {
+	case ProviderWeather:
+		if data.Weather == nil || data.Weather.Description == "" || data.Weather.Humidity == 0 {
+			return fmt.Errorf("weather: %w (description, humidity)", errProbeShape)
+		}
+	case ProviderPOI:
+		if len(data.PointsOfInt) == 0 || data.PointsOfInt[0].Name == "" {
+			return fmt.Errorf("poi: %w (named points of interest)", errProbeShape)
+		}
+	case ProviderCountry:
+		if data.Country == nil || data.Country.Region == "" || data.Country.Capital == "" {
+			return fmt.Errorf("country: %w (region, capital)", errProbeShape)
+		}
+	case ProviderTeleport:
+		for _, qs := range data.QualityScores {
+			if qs.Name != "" && qs.ScoreOutOf > 0 {
+				return nil
+			}
+		}
+		return fmt.Errorf("teleport: %w (scored categories)", errProbeShape)
+	}
+	return nil
+}
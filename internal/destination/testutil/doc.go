@@ -0,0 +1,3 @@
+// Package testutil provides test support for code that depends on the destination
+// package, such as mock servers standing in for the external provider APIs.
+package testutil
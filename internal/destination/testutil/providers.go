@@ -0,0 +1,162 @@
+package testutil
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/neexbeast/ygo-test/internal/destination"
+)
+
+// Endpoint identifies one of the mock provider servers.
+type Endpoint int
+
+// The provider endpoints stood up by NewMockProviders.
+const (
+	Weather Endpoint = iota
+	Geo
+	Radius
+	Countries
+	Teleport
+)
+
+// MockProviders is a set of httptest servers standing in for every external API,
+// plus a Fetcher pre-wired to them. Servers are closed automatically when the test ends.
+type MockProviders struct {
+	Weather   *httptest.Server
+	Geo       *httptest.Server
+	Radius    *httptest.Server
+	Countries *httptest.Server
+	Teleport  *httptest.Server
+
+	// Fetcher calls the mock servers above.
+	Fetcher *destination.Fetcher
+
+	mu       sync.RWMutex
+	handlers map[Endpoint]http.Handler
+}
+
+// NewMockProviders starts a mock server per provider endpoint, each answering
+// with a default successful response for Paris, France.
+func NewMockProviders(t *testing.T) *MockProviders {
+	t.Helper()
+
+	m := &MockProviders{
+		handlers: map[Endpoint]http.Handler{
+			Weather:   JSONHandler(DefaultWeatherResponse()),
+			Geo:       JSONHandler(DefaultGeoResponse()),
+			Radius:    JSONHandler(DefaultRadiusResponse()),
+			Countries: JSONHandler(DefaultCountriesResponse()),
+			Teleport:  JSONHandler(DefaultTeleportResponse()),
+		},
+	}
+
+	m.Weather = m.serve(t, Weather)
+	m.Geo = m.serve(t, Geo)
+	m.Radius = m.serve(t, Radius)
+	m.Countries = m.serve(t, Countries)
+	m.Teleport = m.serve(t, Teleport)
+
+	m.Fetcher = destination.NewFetcherWithClients(
+		destination.NewWeatherClientWithURL(m.Weather.URL, "test-key"),
+		destination.NewPOIClientWithURLs(m.Geo.URL, m.Radius.URL, "test-key"),
+		destination.NewCountriesClientWithURL(m.Countries.URL),
+		destination.NewTeleportClientWithURL(m.Teleport.URL),
+	)
+
+	return m
+}
+
+// SetHandler replaces the handler behind the given endpoint. It is safe to call
+// while requests are in flight.
+func (m *MockProviders) SetHandler(e Endpoint, h http.Handler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers[e] = h
+}
+
+// serve starts a server that delegates every request to the current handler for e.
+func (m *MockProviders) serve(t *testing.T, e Endpoint) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.RLock()
+		h := m.handlers[e]
+		m.mu.RUnlock()
+		h.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// JSONHandler returns a handler that responds 200 with body encoded as JSON.
+func JSONHandler(body any) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(body)
+	})
+}
+
+// StatusHandler returns a handler that responds with the given status code.
+func StatusHandler(status int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, http.StatusText(status), status)
+	})
+}
+
+// DefaultWeatherResponse is an OpenWeatherMap current-weather body.
+func DefaultWeatherResponse() map[string]any {
+	return map[string]any{
+		"main": map[string]any{
+			"temp":       22.5,
+			"feels_like": 21.0,
+			"humidity":   60,
+		},
+		"weather": []map[string]any{{"description": "clear sky"}},
+		"wind":    map[string]any{"speed": 3.5},
+	}
+}
+
+// DefaultGeoResponse is an OpenTripMap geoname body.
+func DefaultGeoResponse() map[string]any {
+	return map[string]any{"lat": 48.8566, "lon": 2.3522}
+}
+
+// DefaultRadiusResponse is an OpenTripMap radius search body with one POI.
+func DefaultRadiusResponse() map[string]any {
+	return map[string]any{
+		"features": []map[string]any{
+			{
+				"properties": map[string]any{
+					"name":  "Eiffel Tower",
+					"kinds": "architecture",
+					"rate":  7,
+				},
+			},
+		},
+	}
+}
+
+// DefaultCountriesResponse is a RestCountries body for France.
+func DefaultCountriesResponse() []map[string]any {
+	return []map[string]any{
+		{
+			"capital":    []string{"Paris"},
+			"region":     "Europe",
+			"languages":  map[string]string{"fra": "French"},
+			"currencies": map[string]any{"EUR": map[string]string{"name": "Euro"}},
+		},
+	}
+}
+
+// DefaultTeleportResponse is a Teleport urban-area scores body.
+func DefaultTeleportResponse() map[string]any {
+	return map[string]any{
+		"categories": []map[string]any{
+			{"name": "Housing", "score_out_of_10": 5.5},
+			{"name": "Safety", "score_out_of_10": 6.0},
+		},
+	}
+}
@@ -0,0 +1,41 @@
+package testutil_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neexbeast/ygo-test/internal/destination"
+	"github.com/neexbeast/ygo-test/internal/destination/testutil"
+)
+
+func TestNewMockProviders_Defaults(t *testing.T) {
+	mp := testutil.NewMockProviders(t)
+
+	res, err := mp.Fetcher.FetchAll(context.Background(), "Paris", "France")
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	assert.Empty(t, res.Errors, "every default provider should succeed")
+	assert.Equal(t, "clear sky", res.Data.Weather.Description)
+	assert.Equal(t, "Eiffel Tower", res.Data.PointsOfInt[0].Name)
+	assert.Equal(t, "Europe", res.Data.Country.Region)
+	assert.Len(t, res.Data.QualityScores, 2)
+}
+
+func TestMockProviders_SetHandler(t *testing.T) {
+	mp := testutil.NewMockProviders(t)
+	mp.SetHandler(testutil.Countries, testutil.StatusHandler(http.StatusNotFound))
+	mp.SetHandler(testutil.Teleport, testutil.JSONHandler(map[string]any{
+		"categories": []map[string]any{{"name": "Safety", "score_out_of_10": 9.1}},
+	}))
+
+	res, err := mp.Fetcher.FetchAll(context.Background(), "Paris", "France")
+	require.NoError(t, err)
+	assert.Nil(t, res.Data.Country)
+	assert.Contains(t, res.Errors, destination.ProviderCountry)
+	require.Len(t, res.Data.QualityScores, 1)
+	assert.Equal(t, 9.1, res.Data.QualityScores[0].ScoreOutOf)
+}
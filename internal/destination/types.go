@@ -9,6 +9,46 @@ type WeatherData struct {
 	Humidity    int     `json:"humidity"`
 	Description string  `json:"description"`
 	WindSpeed   float64 `json:"wind_speed"`
+	// CountryCode is the ISO 3166-1 alpha-2 code of the matched city, when the source reports one.
+	CountryCode string `json:"country_code,omitempty"`
+	// Timezone is the city's current offset from UTC, when the source reports one.
+	Timezone *Timezone `json:"timezone,omitempty"`
+	// Coordinates locate the matched city, when the source reports them.
+	Coordinates *Coordinates `json:"coordinates,omitempty"`
+	// Sunrise and Sunset are today's times in RFC 3339, at the city's offset
+	// when known and in UTC otherwise, when the source reports them.
+	Sunrise string `json:"sunrise,omitempty"`
+	Sunset  string `json:"sunset,omitempty"`
+}
+
+// Coordinates is a point in decimal degrees.
+type Coordinates struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// WeatherAlert is a severe-weather warning issued for a city's area.
+type WeatherAlert struct {
+	Event       string    `json:"event"`
+	Description string    `json:"description"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+}
+
+// Timezone is a fixed UTC offset, as of when the data was fetched (it moves with DST).
+type Timezone struct {
+	OffsetSeconds int `json:"offset_seconds"`
+	// UTCOffset is the offset in ISO 8601 form, e.g. "+01:00" or "-03:30".
+	UTCOffset string `json:"utc_offset"`
+}
+
+// NewTimezone returns the Timezone for an offset of seconds east of UTC.
+func NewTimezone(seconds int) *Timezone {
+	zone := time.FixedZone("", seconds)
+	return &Timezone{
+		OffsetSeconds: seconds,
+		UTCOffset:     time.Unix(0, 0).In(zone).Format("-07:00"),
+	}
 }
 
 // POI represents a single point of interest.
@@ -38,15 +78,121 @@ type DestinationData struct {
 	PointsOfInt   []POI          `json:"points_of_interest,omitempty"`
 	Country       *CountryData   `json:"country,omitempty"`
 	QualityScores []QualityScore `json:"quality_scores,omitempty"`
+	// ExchangeRates maps currency codes to units per one unit of the configured base
+	// currency, which is included at 1: the country's currencies against e.g. USD.
+	ExchangeRates map[string]float64 `json:"exchange_rates,omitempty"`
+	// Alerts are the severe-weather alerts in effect, looked up with the weather.
+	Alerts []WeatherAlert `json:"alerts,omitempty"`
+	// SourcesFetchedAt records when each provider's section was last fetched
+	// successfully, keyed by provider name, so a refresh can reuse fresh sections.
+	// It is bookkeeping, not served to clients; the repository stores it in a
+	// column of its own.
+	SourcesFetchedAt map[string]time.Time `json:"-"`
+}
+
+// Reuse copies provider's section of from into d, with the time it was fetched.
+// Exchange rates go with the country section they were looked up for, and
+// alerts with the weather.
+func (d *DestinationData) Reuse(from *DestinationData, provider string) {
+	switch provider {
+	case ProviderWeather:
+		d.Weather = from.Weather
+		d.Alerts = from.Alerts
+	case ProviderPOI:
+		d.PointsOfInt = from.PointsOfInt
+	case ProviderCountry:
+		d.Country = from.Country
+		d.ExchangeRates = from.ExchangeRates
+	case ProviderTeleport:
+		d.QualityScores = from.QualityScores
+	default:
+		return
+	}
+	if at, ok := from.SourcesFetchedAt[provider]; ok {
+		if d.SourcesFetchedAt == nil {
+			d.SourcesFetchedAt = make(map[string]time.Time)
+		}
+		d.SourcesFetchedAt[provider] = at
+	}
 }
 
 // Destination is a fully stored destination record from the DB.
+// It is served as-is by the admin full-record endpoint, hence the JSON tags.
 type Destination struct {
-	ID        int
-	City      string
-	Country   string
-	Data      DestinationData
-	FetchedAt *time.Time
-	CreatedAt time.Time
+	ID        int             `json:"id"`
+	City      string          `json:"city"`
+	Country   string          `json:"country"`
+	Data      DestinationData `json:"data"`
+	FetchedAt *time.Time      `json:"fetched_at"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// Snapshot is one past version of a destination's data, as kept in its history.
+// It is served by the history endpoint, hence the JSON tags.
+type Snapshot struct {
+	Country   string          `json:"country"`
+	Data      DestinationData `json:"data"`
+	FetchedAt time.Time       `json:"fetched_at"`
+}
+
+// ExpectedSections lists the top-level DestinationData keys a complete record carries.
+var ExpectedSections = []string{"weather", "points_of_interest", "country", "quality_scores"}
+
+// IncompleteDestination identifies a stored destination whose data lacks expected sections.
+type IncompleteDestination struct {
+	City    string   `json:"city"`
+	Country string   `json:"country"`
+	Missing []string `json:"missing"`
+}
+
+// NameCount is one distinct value among stored destinations (a region or
+// country) and how many destinations have it.
+type NameCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// CollectionVersion summarises the stored destinations cheaply enough to check
+// on every list request: any insert, update or delete changes it.
+type CollectionVersion struct {
 	UpdatedAt time.Time
+	Count     int
+}
+
+// CachedData is destination data as held in the cache, with the time it was
+// fetched from the providers. A zero FetchedAt means the age is unknown.
+// NotFound marks a negative entry: the city is known to have no stored data,
+// and Data is nil.
+type CachedData struct {
+	Data      *DestinationData
+	FetchedAt time.Time
+	NotFound  bool
+}
+
+// Page selects a window of a list: at most Limit items, after skipping Offset.
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+// QualityFilter selects stored destinations scoring at least Min (out of 10) in
+// the quality category named Category, e.g. "Safety", matched case-insensitively.
+type QualityFilter struct {
+	Category string
+	Min      float64
+}
+
+// BulkDeleteFilter selects stored destinations for bulk deletion. Zero-valued
+// fields are ignored, but at least one must be set.
+type BulkDeleteFilter struct {
+	// Region matches the country's region (e.g. "Europe"), case-insensitively.
+	Region string
+	// FetchedBefore matches records last fetched before this time.
+	FetchedBefore time.Time
+}
+
+// IsEmpty reports whether no criteria are set, i.e. the filter would match every record.
+func (f BulkDeleteFilter) IsEmpty() bool {
+	return f.Region == "" && f.FetchedBefore.IsZero()
 }
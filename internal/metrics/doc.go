@@ -0,0 +1,2 @@
+// Package metrics defines the Prometheus collectors exported by the service.
+package metrics
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics holds the service's collectors and the registry they are registered on.
+// Each Metrics owns its own registry so tests can inspect values in isolation.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	// InFlightRequests is the number of HTTP requests currently being served, by route pattern.
+	InFlightRequests *prometheus.GaugeVec
+
+	// DestinationUpserts counts stored refreshes by result: "inserted" for a new
+	// destination, "updated" for an existing one.
+	DestinationUpserts *prometheus.CounterVec
+
+	// ProviderRequests counts outbound provider requests by provider and outcome
+	// class: "2xx" through "5xx" by response status, or "timeout", "canceled" or
+	// "error" when no response arrived.
+	ProviderRequests *prometheus.CounterVec
+
+	// ProviderQuotaRemaining is the API plan quota each provider reported left in
+	// the X-RateLimit-Remaining header of its latest response, by provider.
+	ProviderQuotaRemaining *prometheus.GaugeVec
+
+	// ProviderQuotaLimit is the API plan size each provider reported in the
+	// X-RateLimit-Limit header of its latest response, by provider.
+	ProviderQuotaLimit *prometheus.GaugeVec
+}
+
+// Upsert results used as the DestinationUpserts label.
+const (
+	UpsertInserted = "inserted"
+	UpsertUpdated  = "updated"
+)
+
+// Provider request outcomes without an HTTP status, used as the ProviderRequests
+// status_class label alongside "2xx" to "5xx".
+const (
+	ClassTimeout  = "timeout"
+	ClassCanceled = "canceled"
+	ClassError    = "error"
+)
+
+// New constructs Metrics with all collectors registered on a fresh registry.
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		InFlightRequests: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "http_in_flight_requests",
+			Help: "Number of HTTP requests currently being served.",
+		}, []string{"route"}),
+		DestinationUpserts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "destination_upserts_total",
+			Help: "Destinations stored by refresh, by whether the row was inserted or updated.",
+		}, []string{"result"}),
+		ProviderRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "provider_requests_total",
+			Help: "Outbound requests to external providers, by provider and status class.",
+		}, []string{"provider", "status_class"}),
+		ProviderQuotaRemaining: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "provider_quota_remaining",
+			Help: "API plan quota each provider last reported remaining.",
+		}, []string{"provider"}),
+		ProviderQuotaLimit: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "provider_quota_limit",
+			Help: "API plan size each provider last reported.",
+		}, []string{"provider"}),
+	}
+
+	m.Registry.MustRegister(m.InFlightRequests, m.DestinationUpserts, m.ProviderRequests, m.ProviderQuotaRemaining, m.ProviderQuotaLimit)
+	return m
+}
+
+// Handler returns an http.Handler serving the registry in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,36 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/neexbeast/ygo-test/internal/metrics"
+)
+
+func TestNew_RegistersCollectors(t *testing.T) {
+	m := metrics.New()
+	m.InFlightRequests.WithLabelValues("/test").Inc()
+
+	families, err := m.Registry.Gather()
+	assert.NoError(t, err)
+
+	names := make([]string, 0, len(families))
+	for _, f := range families {
+		names = append(names, f.GetName())
+	}
+	assert.Contains(t, names, "http_in_flight_requests")
+}
+
+func TestHandler_ServesRegistry(t *testing.T) {
+	m := metrics.New()
+	m.InFlightRequests.WithLabelValues("/test").Inc()
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `http_in_flight_requests{route="/test"} 1`)
+}
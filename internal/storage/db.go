@@ -2,11 +2,14 @@ package storage
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -18,23 +21,153 @@ type MigrationPool interface {
 	Begin(ctx context.Context) (pgx.Tx, error)
 }
 
+// ConnectOption configures optional Connect behaviour.
+type ConnectOption func(*connectConfig)
+
+type connectConfig struct {
+	attempts         int
+	interval         time.Duration
+	tls              *tls.Config
+	statementTimeout time.Duration
+}
+
+// WithConnectRetries makes Connect ping up to attempts times before giving up,
+// waiting interval after the first failure, twice that after the second, and so
+// on. A server started alongside its database then waits for it instead of
+// exiting. Attempts below 1 keep the default of a single attempt.
+func WithConnectRetries(attempts int, interval time.Duration) ConnectOption {
+	return func(c *connectConfig) {
+		if attempts > 0 {
+			c.attempts = attempts
+		}
+		c.interval = interval
+	}
+}
+
+// WithTLS makes Connect use tlsCfg for every connection instead of the TLS
+// settings derived from the URL's sslmode, so plaintext is never attempted.
+// The server name defaults to each host being connected to.
+func WithTLS(tlsCfg *tls.Config) ConnectOption {
+	return func(c *connectConfig) {
+		c.tls = tlsCfg
+	}
+}
+
+// WithStatementTimeout sets statement_timeout on every connection, so Postgres
+// aborts any single statement running longer than d instead of letting it hold
+// the connection. It overrides a statement_timeout given in the URL; zero keeps
+// the URL's or server's setting.
+func WithStatementTimeout(d time.Duration) ConnectOption {
+	return func(c *connectConfig) {
+		c.statementTimeout = d
+	}
+}
+
+// PoolConfig parses databaseURL into the pool configuration Connect uses, with
+// opts applied. Options that only affect connecting (retries) are ignored here.
+func PoolConfig(databaseURL string, opts ...ConnectOption) (*pgxpool.Config, error) {
+	cfg := connectConfig{attempts: 1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return poolConfig(databaseURL, cfg)
+}
+
+func poolConfig(databaseURL string, cfg connectConfig) (*pgxpool.Config, error) {
+	poolCfg, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing database URL: %w", err)
+	}
+	cc := poolCfg.ConnConfig
+	if cfg.tls != nil {
+		cc.TLSConfig = tlsFor(cfg.tls, cc.Host)
+		for _, fb := range cc.Fallbacks {
+			fb.TLSConfig = tlsFor(cfg.tls, fb.Host)
+		}
+	}
+	if cfg.statementTimeout > 0 {
+		// Sent as a startup parameter, in milliseconds, so it applies before any query runs.
+		cc.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.statementTimeout.Milliseconds(), 10)
+	}
+	return poolCfg, nil
+}
+
 // Connect opens a pgxpool connection and verifies it with a ping.
-func Connect(ctx context.Context, databaseURL string) (*pgxpool.Pool, error) {
-	pool, err := pgxpool.New(ctx, databaseURL)
+func Connect(ctx context.Context, databaseURL string, opts ...ConnectOption) (*pgxpool.Pool, error) {
+	cfg := connectConfig{attempts: 1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	poolCfg, err := poolConfig(databaseURL, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
 	if err != nil {
 		return nil, fmt.Errorf("creating pgxpool: %w", err)
 	}
 
-	if err := pool.Ping(ctx); err != nil {
-		pool.Close()
-		return nil, fmt.Errorf("pinging database: %w", err)
+	attempt := 1
+	for ; ; attempt++ {
+		if err = pool.Ping(ctx); err == nil {
+			return pool, nil
+		}
+		if attempt >= cfg.attempts || !sleepCtx(ctx, time.Duration(attempt)*cfg.interval) {
+			break
+		}
 	}
 
-	return pool, nil
+	pool.Close()
+	return nil, fmt.Errorf("pinging database after %d of %d attempts: %w", attempt, cfg.attempts, err)
+}
+
+// tlsFor returns a copy of base verifying against host, unless base names a server itself.
+func tlsFor(base *tls.Config, host string) *tls.Config {
+	c := base.Clone()
+	if c.ServerName == "" {
+		c.ServerName = host
+	}
+	return c
+}
+
+// sleepCtx waits for d, returning false early if ctx is done first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
 }
 
+// migrationLockID is the Postgres advisory lock key that serializes migrations
+// across instances. Any constant works as long as every instance uses the same one.
+const migrationLockID int64 = 0x79676f2d6d6967 // "ygo-mig"
+
+// disableStatementTimeout lifts WithStatementTimeout for the rest of a migration
+// transaction: waiting for another instance's lock, or DDL on a large table, may
+// legitimately take longer than any query should.
+const disableStatementTimeout = "SET LOCAL statement_timeout = 0"
+
+// createMigrationsTable tracks which migration files have been applied.
+const createMigrationsTable = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		filename   TEXT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)
+`
+
 // RunMigrations reads all .sql files from migrationsDir in lexicographic order
 // and executes them against the pool. Each file runs in its own transaction.
+//
+// When several instances start at once, only one migrates at a time: the run
+// holds a transaction-scoped advisory lock (pg_advisory_xact_lock) in a dedicated
+// transaction that ends, releasing the lock, when RunMigrations returns. Instances
+// that waited then skip files already recorded in schema_migrations.
 func RunMigrations(ctx context.Context, pool MigrationPool, migrationsDir string) error {
 	entries, err := os.ReadDir(migrationsDir)
 	if err != nil {
@@ -48,6 +181,28 @@ func RunMigrations(ctx context.Context, pool MigrationPool, migrationsDir string
 		}
 	}
 	sort.Strings(files)
+	if len(files) == 0 {
+		return nil
+	}
+
+	lockTx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning migration lock transaction: %w", err)
+	}
+	// Ending the lock transaction releases the advisory lock. If ctx is already
+	// done the rollback fails, but the connection is then discarded, which also releases it.
+	defer func() { _ = lockTx.Rollback(context.WithoutCancel(ctx)) }()
+
+	if _, err := lockTx.Exec(ctx, disableStatementTimeout); err != nil {
+		return fmt.Errorf("disabling statement timeout: %w", err)
+	}
+	if _, err := lockTx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("acquiring migration lock: %w", err)
+	}
+
+	if err := runInTx(ctx, pool, createMigrationsTable); err != nil {
+		return fmt.Errorf("creating schema_migrations: %w", err)
+	}
 
 	for _, f := range files {
 		sql, err := os.ReadFile(f)
@@ -55,7 +210,7 @@ func RunMigrations(ctx context.Context, pool MigrationPool, migrationsDir string
 			return fmt.Errorf("reading migration %s: %w", f, err)
 		}
 
-		if err := runInTx(ctx, pool, string(sql)); err != nil {
+		if err := applyMigration(ctx, pool, filepath.Base(f), string(sql)); err != nil {
 			return fmt.Errorf("executing migration %s: %w", f, err)
 		}
 	}
@@ -63,6 +218,39 @@ func RunMigrations(ctx context.Context, pool MigrationPool, migrationsDir string
 	return nil
 }
 
+// applyMigration records name in schema_migrations and runs sql in the same
+// transaction, or does nothing if name was already recorded.
+func applyMigration(ctx context.Context, pool MigrationPool, name, sql string) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, "INSERT INTO schema_migrations (filename) VALUES ($1) ON CONFLICT DO NOTHING", name)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return fmt.Errorf("recording migration: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return tx.Rollback(ctx)
+	}
+
+	if _, err := tx.Exec(ctx, disableStatementTimeout); err != nil {
+		_ = tx.Rollback(ctx)
+		return fmt.Errorf("disabling statement timeout: %w", err)
+	}
+	if _, err := tx.Exec(ctx, sql); err != nil {
+		_ = tx.Rollback(ctx)
+		return fmt.Errorf("executing SQL: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return nil
+}
+
 // runInTx runs the given SQL in a transaction, rolling back on failure.
 func runInTx(ctx context.Context, pool MigrationPool, sql string) error {
 	tx, err := pool.Begin(ctx)
@@ -24,39 +24,91 @@ type Querier interface {
 
 // Repository provides database access for destination records.
 type Repository struct {
-	q Querier
+	q          Querier
+	history    bool
+	countryKey bool
+	upsertSQL  string
+}
+
+// RepositoryOption configures optional Repository behaviour.
+type RepositoryOption func(*Repository)
+
+// WithHistory makes UpsertDestination append every write to destination_history,
+// for GetHistory. It is off by default, as history grows with every refresh.
+func WithHistory(enabled bool) RepositoryOption {
+	return func(r *Repository) {
+		r.history = enabled
+	}
+}
+
+// WithCountryKey keys destinations on city and country instead of city alone, so
+// same-named cities in different countries are stored separately. It needs the
+// schema change in migrations/city_country, which replaces the unique city
+// constraint; a database migrated for it cannot go back to city keys.
+func WithCountryKey(enabled bool) RepositoryOption {
+	return func(r *Repository) {
+		r.countryKey = enabled
+	}
 }
 
 // NewRepository constructs a Repository backed by the given pool.
-func NewRepository(pool *pgxpool.Pool) *Repository {
-	return &Repository{q: pool}
+func NewRepository(pool *pgxpool.Pool, opts ...RepositoryOption) *Repository {
+	return NewRepositoryWithQuerier(pool, opts...)
 }
 
 // NewRepositoryWithQuerier constructs a Repository with a custom Querier (for tests).
-func NewRepositoryWithQuerier(q Querier) *Repository {
-	return &Repository{q: q}
+func NewRepositoryWithQuerier(q Querier, opts ...RepositoryOption) *Repository {
+	r := &Repository{q: q}
+	for _, opt := range opts {
+		opt(r)
+	}
+	key := "city"
+	if r.countryKey {
+		key = "city, country"
+	}
+	r.upsertSQL = upsertSQL(key, r.history)
+	return r
 }
 
-// GetDestination retrieves a destination by city name.
-// Uses JSONB ? operator to ensure the record has weather data.
-// Returns nil, nil when the city is not found.
+// GetDestination retrieves a destination by city name, whatever sections its data holds.
+// Returns nil, nil when the city is not found. Use FindIncomplete to locate records
+// missing expected sections. With WithCountryKey, where the city may be stored
+// for several countries, it returns the most recently updated one; use
+// GetDestinationInCountry to pick.
 func (r *Repository) GetDestination(ctx context.Context, city string) (*destination.Destination, error) {
 	const q = `
-		SELECT id, city, country, data, fetched_at, created_at, updated_at
+		SELECT id, city, country, data, sources_fetched_at, fetched_at, created_at, updated_at
 		FROM destinations
 		WHERE city = $1
-		AND data ? 'weather'
+		ORDER BY updated_at DESC
+		LIMIT 1
 	`
+	return r.getDestination(ctx, city, q, city)
+}
 
+// GetDestinationInCountry retrieves the destination stored for city in country.
+// Returns nil, nil when there is none.
+func (r *Repository) GetDestinationInCountry(ctx context.Context, city, country string) (*destination.Destination, error) {
+	const q = `
+		SELECT id, city, country, data, sources_fetched_at, fetched_at, created_at, updated_at
+		FROM destinations
+		WHERE city = $1 AND country = $2
+	`
+	return r.getDestination(ctx, city, q, city, country)
+}
+
+// getDestination runs q, which selects at most one destination row, and scans its result.
+func (r *Repository) getDestination(ctx context.Context, city, q string, args ...any) (*destination.Destination, error) {
 	var d destination.Destination
-	var dataJSON []byte
+	var dataJSON, sourcesJSON []byte
 	var fetchedAt *time.Time
 
-	err := r.q.QueryRow(ctx, q, city).Scan(
+	err := r.q.QueryRow(ctx, q, args...).Scan(
 		&d.ID,
 		&d.City,
 		&d.Country,
 		&dataJSON,
+		&sourcesJSON,
 		&fetchedAt,
 		&d.CreatedAt,
 		&d.UpdatedAt,
@@ -71,34 +123,133 @@ func (r *Repository) GetDestination(ctx context.Context, city string) (*destinat
 	if err := json.Unmarshal(dataJSON, &d.Data); err != nil {
 		return nil, fmt.Errorf("unmarshaling destination data for city %s: %w", city, err)
 	}
+	if err := json.Unmarshal(sourcesJSON, &d.Data.SourcesFetchedAt); err != nil {
+		return nil, fmt.Errorf("unmarshaling sources_fetched_at for city %s: %w", city, err)
+	}
 
 	d.FetchedAt = fetchedAt
 	return &d, nil
 }
 
-// UpsertDestination inserts or updates a destination record.
-// On conflict (city), updates data, country, fetched_at, and updated_at.
-func (r *Repository) UpsertDestination(ctx context.Context, city, country string, data destination.DestinationData) error {
+// DataFetchedAt returns when city's stored data was fetched, falling back to its
+// last update for rows stored before fetched_at was recorded. It reads only the
+// timestamp, for comparing against a cached copy; ok is false if there is no row.
+// With WithCountryKey it is the latest of the city's rows in any country.
+func (r *Repository) DataFetchedAt(ctx context.Context, city string) (fetchedAt time.Time, ok bool, err error) {
+	const q = `
+		SELECT COALESCE(fetched_at, updated_at) AS at
+		FROM destinations
+		WHERE city = $1
+		ORDER BY at DESC
+		LIMIT 1
+	`
+
+	if err := r.q.QueryRow(ctx, q, city).Scan(&fetchedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, fmt.Errorf("querying fetched_at for city %s: %w", city, err)
+	}
+	return fetchedAt, true, nil
+}
+
+// upsertSQL returns the statement UpsertDestination runs: an insert that
+// updates the row with the same key columns instead, optionally copying the row
+// as written into destination_history in the same statement.
+func upsertSQL(key string, history bool) string {
+	// xmax is zero only for a row version created by INSERT; the UPDATE path sets it.
+	upsert := `
+		INSERT INTO destinations (city, country, data, sources_fetched_at, fetched_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (` + key + `) DO UPDATE
+		SET country            = EXCLUDED.country,
+		    data               = EXCLUDED.data,
+		    sources_fetched_at = EXCLUDED.sources_fetched_at,
+		    fetched_at         = EXCLUDED.fetched_at,
+		    updated_at         = EXCLUDED.updated_at
+		RETURNING (xmax = 0) AS inserted`
+	if !history {
+		return upsert
+	}
+	return `
+		WITH upserted AS (` + upsert + `, city, country, data, fetched_at
+		), history AS (
+			INSERT INTO destination_history (city, country, data, fetched_at)
+			SELECT city, country, data, fetched_at FROM upserted
+		)
+		SELECT inserted FROM upserted`
+}
+
+// UpsertDestination inserts or updates a destination record and reports whether
+// the row was newly inserted. On conflict (city, or city and country with
+// WithCountryKey), updates data, country, sources_fetched_at, fetched_at,
+// and updated_at. data.SourcesFetchedAt is stored in sources_fetched_at rather
+// than in data. fetched_at is set to fetchedAt, the time the data was fetched,
+// so it matches the time cached alongside it. created_at is never written here; the destinations_touch_timestamps
+// trigger also pins it to the original insert time on any UPDATE.
+//
+// With WithHistory, each write also appends the new version to
+// destination_history. Both inserts are one statement, and so one transaction:
+// the history cannot miss a write or record one that was rolled back.
+func (r *Repository) UpsertDestination(ctx context.Context, city, country string, data destination.DestinationData, fetchedAt time.Time) (bool, error) {
 	dataJSON, err := json.Marshal(data)
 	if err != nil {
-		return fmt.Errorf("marshaling destination data for city %s: %w", city, err)
+		return false, fmt.Errorf("marshaling destination data for city %s: %w", city, err)
+	}
+
+	sources := data.SourcesFetchedAt
+	if sources == nil {
+		sources = map[string]time.Time{}
+	}
+	sourcesJSON, err := json.Marshal(sources)
+	if err != nil {
+		return false, fmt.Errorf("marshaling sources_fetched_at for city %s: %w", city, err)
 	}
 
+	var inserted bool
+	if err := r.q.QueryRow(ctx, r.upsertSQL, city, country, dataJSON, sourcesJSON, fetchedAt).Scan(&inserted); err != nil {
+		return false, fmt.Errorf("upserting destination for city %s: %w", city, err)
+	}
+
+	return inserted, nil
+}
+
+// GetHistory returns up to limit past versions of city's data, newest first.
+// A city with no history has no snapshots and no error. History is only written
+// by a Repository with WithHistory.
+func (r *Repository) GetHistory(ctx context.Context, city string, limit int) ([]destination.Snapshot, error) {
 	const q = `
-		INSERT INTO destinations (city, country, data, fetched_at, updated_at)
-		VALUES ($1, $2, $3, NOW(), NOW())
-		ON CONFLICT (city) DO UPDATE
-		SET country    = EXCLUDED.country,
-		    data       = EXCLUDED.data,
-		    fetched_at = EXCLUDED.fetched_at,
-		    updated_at = EXCLUDED.updated_at
+		SELECT COALESCE(country, ''), data, fetched_at
+		FROM destination_history
+		WHERE city = $1
+		ORDER BY fetched_at DESC, id DESC
+		LIMIT $2
 	`
 
-	if _, err := r.q.Exec(ctx, q, city, country, dataJSON); err != nil {
-		return fmt.Errorf("upserting destination for city %s: %w", city, err)
+	rows, err := r.q.Query(ctx, q, city, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying history for city %s: %w", city, err)
+	}
+	defer rows.Close()
+
+	var snapshots []destination.Snapshot
+	for rows.Next() {
+		var s destination.Snapshot
+		var dataJSON []byte
+		if err := rows.Scan(&s.Country, &dataJSON, &s.FetchedAt); err != nil {
+			return nil, fmt.Errorf("scanning history row: %w", err)
+		}
+		if err := json.Unmarshal(dataJSON, &s.Data); err != nil {
+			return nil, fmt.Errorf("unmarshaling history data for city %s: %w", city, err)
+		}
+		snapshots = append(snapshots, s)
 	}
 
-	return nil
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating history rows: %w", err)
+	}
+
+	return snapshots, nil
 }
 
 // GetDestinationByWeatherCondition returns destinations whose data contains
@@ -121,6 +272,193 @@ func (r *Repository) GetDestinationByWeatherCondition(ctx context.Context, condi
 	if err != nil {
 		return nil, fmt.Errorf("querying destinations by weather condition: %w", err)
 	}
+
+	return scanDestinations(rows)
+}
+
+// FullTextSearch returns the page of destinations whose city or country matches
+// every word in query, most relevant first. Matching uses the generated search
+// tsvector column (see migrations/003_search.sql); an empty query matches nothing.
+func (r *Repository) FullTextSearch(ctx context.Context, query string, page destination.Page) ([]*destination.Destination, error) {
+	const q = `
+		SELECT id, city, COALESCE(country, ''), data, fetched_at, created_at, updated_at
+		FROM destinations, plainto_tsquery('simple', $1) AS query
+		WHERE search @@ query
+		ORDER BY ts_rank(search, query) DESC, city
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.q.Query(ctx, q, query, page.Limit, page.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("full-text searching destinations for %q: %w", query, err)
+	}
+
+	return scanDestinations(rows)
+}
+
+// FindByMinQuality returns the page of destinations with a quality score of at
+// least filter.Min in filter.Category, ordered by city. Scores are matched by
+// expanding the JSONB quality_scores array with jsonb_array_elements; records
+// without the array never match.
+func (r *Repository) FindByMinQuality(ctx context.Context, filter destination.QualityFilter, page destination.Page) ([]*destination.Destination, error) {
+	const q = `
+		SELECT id, city, COALESCE(country, ''), data, fetched_at, created_at, updated_at
+		FROM destinations
+		WHERE jsonb_typeof(data->'quality_scores') = 'array'
+		  AND EXISTS (
+		      SELECT 1
+		      FROM jsonb_array_elements(data->'quality_scores') AS score
+		      WHERE LOWER(score->>'name') = LOWER($1)
+		        AND (score->>'score_out_of_10')::float8 >= $2
+		  )
+		ORDER BY city
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.q.Query(ctx, q, filter.Category, filter.Min, page.Limit, page.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("querying destinations with %s of at least %v: %w", filter.Category, filter.Min, err)
+	}
+
+	return scanDestinations(rows)
+}
+
+// ErrEmptyFilter is returned by DeleteMatching when the filter has no criteria,
+// so a caller bug can never turn into an unqualified mass delete.
+var ErrEmptyFilter = errors.New("bulk delete requires at least one filter")
+
+// DeleteMatching deletes every destination matching all criteria in filter and
+// returns the deleted cities. Region is matched via the JSONB ->/->> operators;
+// records never fetched count by their last update time for FetchedBefore.
+func (r *Repository) DeleteMatching(ctx context.Context, filter destination.BulkDeleteFilter) ([]string, error) {
+	if filter.IsEmpty() {
+		return nil, ErrEmptyFilter
+	}
+
+	var before *time.Time
+	if !filter.FetchedBefore.IsZero() {
+		before = &filter.FetchedBefore
+	}
+
+	const q = `
+		DELETE FROM destinations
+		WHERE ($1 = '' OR LOWER(data->'country'->>'region') = LOWER($1))
+		  AND ($2::timestamptz IS NULL OR COALESCE(fetched_at, updated_at) < $2)
+		RETURNING city
+	`
+
+	rows, err := r.q.Query(ctx, q, filter.Region, before)
+	if err != nil {
+		return nil, fmt.Errorf("bulk deleting destinations: %w", err)
+	}
+	defer rows.Close()
+
+	var cities []string
+	for rows.Next() {
+		var city string
+		if err := rows.Scan(&city); err != nil {
+			return nil, fmt.Errorf("scanning deleted city: %w", err)
+		}
+		cities = append(cities, city)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating deleted cities: %w", err)
+	}
+
+	return cities, nil
+}
+
+// DistinctRegions returns each distinct country region among stored
+// destinations with how many destinations are in it, most common first.
+// Records without a region are left out.
+func (r *Repository) DistinctRegions(ctx context.Context) ([]destination.NameCount, error) {
+	const q = `
+		SELECT data->'country'->>'region' AS region, COUNT(*)
+		FROM destinations
+		WHERE COALESCE(data->'country'->>'region', '') <> ''
+		GROUP BY region
+		ORDER BY COUNT(*) DESC, region
+	`
+
+	rows, err := r.q.Query(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("querying distinct regions: %w", err)
+	}
+	return scanNameCounts(rows)
+}
+
+// DistinctCountries returns each distinct country column value among stored
+// destinations with how many destinations have it, most common first.
+// Records without a country are left out.
+func (r *Repository) DistinctCountries(ctx context.Context) ([]destination.NameCount, error) {
+	const q = `
+		SELECT country, COUNT(*)
+		FROM destinations
+		WHERE COALESCE(country, '') <> ''
+		GROUP BY country
+		ORDER BY COUNT(*) DESC, country
+	`
+
+	rows, err := r.q.Query(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("querying distinct countries: %w", err)
+	}
+	return scanNameCounts(rows)
+}
+
+// CollectionVersion returns the latest updated_at among stored destinations and
+// how many there are. Deleting a row lowers the count, so together they change
+// whenever the table does. An empty table reports the Unix epoch.
+func (r *Repository) CollectionVersion(ctx context.Context) (destination.CollectionVersion, error) {
+	const q = `
+		SELECT COALESCE(MAX(updated_at), 'epoch'::timestamptz), COUNT(*)
+		FROM destinations
+	`
+
+	var v destination.CollectionVersion
+	if err := r.q.QueryRow(ctx, q).Scan(&v.UpdatedAt, &v.Count); err != nil {
+		return destination.CollectionVersion{}, fmt.Errorf("querying collection version: %w", err)
+	}
+	return v, nil
+}
+
+// scanNameCounts reads (name, count) rows and closes rows.
+func scanNameCounts(rows pgx.Rows) ([]destination.NameCount, error) {
+	defer rows.Close()
+
+	var results []destination.NameCount
+	for rows.Next() {
+		var nc destination.NameCount
+		if err := rows.Scan(&nc.Name, &nc.Count); err != nil {
+			return nil, fmt.Errorf("scanning name count row: %w", err)
+		}
+		results = append(results, nc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating name count rows: %w", err)
+	}
+
+	return results, nil
+}
+
+// DeleteDestination permanently removes the destination rows for city and
+// reports whether any existed, from the command tag's RowsAffected. There is
+// no soft delete; history rows, if recorded, are kept.
+func (r *Repository) DeleteDestination(ctx context.Context, city string) (bool, error) {
+	const q = `DELETE FROM destinations WHERE city = $1`
+
+	tag, err := r.q.Exec(ctx, q, city)
+	if err != nil {
+		return false, fmt.Errorf("deleting destination for city %s: %w", city, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// scanDestinations reads full destination rows (id, city, country, data,
+// fetched_at, created_at, updated_at) and closes rows.
+func scanDestinations(rows pgx.Rows) ([]*destination.Destination, error) {
 	defer rows.Close()
 
 	var results []*destination.Destination
@@ -155,3 +493,40 @@ func (r *Repository) GetDestinationByWeatherCondition(ctx context.Context, condi
 
 	return results, nil
 }
+
+// FindIncomplete lists the page of destinations whose data lacks any of
+// destination.ExpectedSections, along with which sections are missing, so they
+// can be targeted for re-refresh. Uses the JSONB ?& (all keys exist) and ?
+// (key exists) operators.
+func (r *Repository) FindIncomplete(ctx context.Context, page destination.Page) ([]destination.IncompleteDestination, error) {
+	const q = `
+		SELECT city,
+		       COALESCE(country, ''),
+		       ARRAY(SELECT k FROM unnest($1::text[]) AS k WHERE NOT data ? k)
+		FROM destinations
+		WHERE NOT data ?& $1::text[]
+		ORDER BY city
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.q.Query(ctx, q, destination.ExpectedSections, page.Limit, page.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("querying incomplete destinations: %w", err)
+	}
+	defer rows.Close()
+
+	var results []destination.IncompleteDestination
+	for rows.Next() {
+		var d destination.IncompleteDestination
+		if err := rows.Scan(&d.City, &d.Country, &d.Missing); err != nil {
+			return nil, fmt.Errorf("scanning incomplete destination row: %w", err)
+		}
+		results = append(results, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating incomplete destination rows: %w", err)
+	}
+
+	return results, nil
+}
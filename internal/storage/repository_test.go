@@ -4,8 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -78,6 +82,8 @@ func (f *fakeRows) Scan(dest ...any) error {
 			*v = row[i].(string)
 		case *[]byte:
 			*v = row[i].([]byte)
+		case *[]string:
+			*v = row[i].([]string)
 		case **time.Time:
 			if row[i] == nil {
 				*v = nil
@@ -161,9 +167,10 @@ func TestGetDestination_Found(t *testing.T) {
 				*dest[1].(*string) = "Paris"
 				*dest[2].(*string) = "France"
 				*dest[3].(*[]byte) = dataJSON
-				*dest[4].(**time.Time) = &now
-				*dest[5].(*time.Time) = now
+				*dest[4].(*[]byte) = []byte(`{"weather":"` + now.Format(time.RFC3339) + `"}`)
+				*dest[5].(**time.Time) = &now
 				*dest[6].(*time.Time) = now
+				*dest[7].(*time.Time) = now
 				return nil
 			}}
 		},
@@ -175,6 +182,61 @@ func TestGetDestination_Found(t *testing.T) {
 	require.NotNil(t, dest)
 	assert.Equal(t, "Paris", dest.City)
 	assert.Equal(t, 22.5, dest.Data.Weather.Temperature)
+	assert.True(t, now.Equal(dest.Data.SourcesFetchedAt[destination.ProviderWeather]), "sources_fetched_at is read from its own column")
+}
+
+func TestGetDestination_SameNamedCities(t *testing.T) {
+	var capturedSQL string
+	var capturedArgs []any
+	q := &mockQuerier{
+		queryRowFn: func(_ context.Context, sql string, args ...any) pgx.Row {
+			capturedSQL, capturedArgs = sql, args
+			return &fakeRow{scanFn: func(dest ...any) error {
+				*dest[1].(*string) = "Paris"
+				*dest[2].(*string) = args[len(args)-1].(string)
+				*dest[3].(*[]byte) = []byte(`{}`)
+				*dest[4].(*[]byte) = []byte(`{}`)
+				return nil
+			}}
+		},
+	}
+	repo := storage.NewRepositoryWithQuerier(q, storage.WithCountryKey(true))
+
+	dest, err := repo.GetDestinationInCountry(context.Background(), "Paris", "United States")
+	require.NoError(t, err)
+	assert.Equal(t, "United States", dest.Country)
+	assert.Contains(t, capturedSQL, "WHERE city = $1 AND country = $2")
+	assert.Equal(t, []any{"Paris", "United States"}, capturedArgs)
+
+	_, err = repo.GetDestination(context.Background(), "Paris")
+	require.NoError(t, err)
+	assert.Contains(t, capturedSQL, "ORDER BY updated_at DESC", "without a country the latest row wins")
+	assert.Equal(t, []any{"Paris"}, capturedArgs)
+}
+
+func TestUpsertDestination_CountryKey(t *testing.T) {
+	for _, history := range []bool{false, true} {
+		t.Run("history="+strconv.FormatBool(history), func(t *testing.T) {
+			var capturedSQL string
+			q := &mockQuerier{
+				queryRowFn: func(_ context.Context, sql string, _ ...any) pgx.Row {
+					capturedSQL = sql
+					return &fakeRow{scanFn: func(_ ...any) error { return nil }}
+				},
+			}
+
+			repo := storage.NewRepositoryWithQuerier(q)
+			_, err := repo.UpsertDestination(context.Background(), "Paris", "France", destination.DestinationData{}, time.Now())
+			require.NoError(t, err)
+			assert.Contains(t, capturedSQL, "ON CONFLICT (city) DO UPDATE")
+
+			repo = storage.NewRepositoryWithQuerier(q, storage.WithCountryKey(true), storage.WithHistory(history))
+			_, err = repo.UpsertDestination(context.Background(), "Paris", "United States", destination.DestinationData{}, time.Now())
+			require.NoError(t, err)
+			assert.Contains(t, capturedSQL, "ON CONFLICT (city, country) DO UPDATE")
+			assert.Equal(t, history, strings.Contains(capturedSQL, "destination_history"))
+		})
+	}
 }
 
 func TestGetDestination_NotFound(t *testing.T) {
@@ -212,9 +274,10 @@ func TestGetDestination_BadJSON(t *testing.T) {
 				*dest[1].(*string) = "Paris"
 				*dest[2].(*string) = "France"
 				*dest[3].(*[]byte) = []byte("not-valid-json")
-				*dest[4].(**time.Time) = &now
-				*dest[5].(*time.Time) = now
+				*dest[4].(*[]byte) = []byte(`{}`)
+				*dest[5].(**time.Time) = &now
 				*dest[6].(*time.Time) = now
+				*dest[7].(*time.Time) = now
 				return nil
 			}}
 		},
@@ -228,40 +291,273 @@ func TestGetDestination_BadJSON(t *testing.T) {
 
 // ---- UpsertDestination tests ----
 
+func TestDataFetchedAt(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	var query string
+	q := &mockQuerier{
+		queryRowFn: func(_ context.Context, sql string, _ ...any) pgx.Row {
+			query = sql
+			return &fakeRow{scanFn: func(dest ...any) error {
+				require.Len(t, dest, 1)
+				*dest[0].(*time.Time) = now
+				return nil
+			}}
+		},
+	}
+
+	repo := storage.NewRepositoryWithQuerier(q)
+	got, ok, err := repo.DataFetchedAt(context.Background(), "Paris")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, now, got)
+	assert.NotContains(t, query, "data", "only the timestamp should be read")
+}
+
+func TestDataFetchedAt_NotFound(t *testing.T) {
+	q := &mockQuerier{
+		queryRowFn: func(_ context.Context, _ string, _ ...any) pgx.Row {
+			return &fakeRow{scanFn: func(dest ...any) error { return pgx.ErrNoRows }}
+		},
+	}
+
+	repo := storage.NewRepositoryWithQuerier(q)
+	_, ok, err := repo.DataFetchedAt(context.Background(), "Atlantis")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestDataFetchedAt_DBError(t *testing.T) {
+	q := &mockQuerier{
+		queryRowFn: func(_ context.Context, _ string, _ ...any) pgx.Row {
+			return &fakeRow{scanFn: func(dest ...any) error { return fmt.Errorf("connection reset") }}
+		},
+	}
+
+	repo := storage.NewRepositoryWithQuerier(q)
+	_, _, err := repo.DataFetchedAt(context.Background(), "Paris")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "querying fetched_at")
+}
+
+func TestCollectionVersion(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	var query string
+	q := &mockQuerier{
+		queryRowFn: func(_ context.Context, sql string, _ ...any) pgx.Row {
+			query = sql
+			return &fakeRow{scanFn: func(dest ...any) error {
+				require.Len(t, dest, 2)
+				*dest[0].(*time.Time) = now
+				*dest[1].(*int) = 4
+				return nil
+			}}
+		},
+	}
+
+	repo := storage.NewRepositoryWithQuerier(q)
+	got, err := repo.CollectionVersion(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, destination.CollectionVersion{UpdatedAt: now, Count: 4}, got)
+	assert.Contains(t, query, "MAX(updated_at)")
+	assert.Contains(t, query, "COUNT(*)")
+	assert.NotContains(t, query, "data", "only the aggregates should be read")
+}
+
+func TestCollectionVersion_DBError(t *testing.T) {
+	q := &mockQuerier{
+		queryRowFn: func(_ context.Context, _ string, _ ...any) pgx.Row {
+			return &fakeRow{scanFn: func(dest ...any) error { return fmt.Errorf("connection reset") }}
+		},
+	}
+
+	repo := storage.NewRepositoryWithQuerier(q)
+	_, err := repo.CollectionVersion(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "collection version")
+}
+
 func TestUpsertDestination_Success(t *testing.T) {
 	var capturedArgs []any
 	q := &mockQuerier{
-		execFn: func(_ context.Context, _ string, args ...any) (pgconn.CommandTag, error) {
+		queryRowFn: func(_ context.Context, _ string, args ...any) pgx.Row {
 			capturedArgs = args
-			return pgconn.CommandTag{}, nil
+			return &fakeRow{scanFn: func(dest ...any) error {
+				*dest[0].(*bool) = true
+				return nil
+			}}
 		},
 	}
 
 	data := destination.DestinationData{
-		Weather: &destination.WeatherData{Temperature: 20.0},
+		Weather:          &destination.WeatherData{Temperature: 20.0},
+		SourcesFetchedAt: map[string]time.Time{destination.ProviderWeather: time.Date(2026, 5, 1, 11, 0, 0, 0, time.UTC)},
 	}
 
+	fetchedAt := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
 	repo := storage.NewRepositoryWithQuerier(q)
-	err := repo.UpsertDestination(context.Background(), "Paris", "France", data)
+	_, err := repo.UpsertDestination(context.Background(), "Paris", "France", data, fetchedAt)
 	require.NoError(t, err)
-	require.Len(t, capturedArgs, 3)
+	require.Len(t, capturedArgs, 5)
 	assert.Equal(t, "Paris", capturedArgs[0])
 	assert.Equal(t, "France", capturedArgs[1])
+	assert.JSONEq(t, `{"weather":"2026-05-01T11:00:00Z"}`, string(capturedArgs[3].([]byte)), "sources_fetched_at is stored in its own column")
+	assert.NotContains(t, string(capturedArgs[2].([]byte)), "sources_fetched_at")
+	assert.Equal(t, fetchedAt, capturedArgs[4], "fetched_at must be the caller's fetch time, not NOW()")
+}
+
+func TestUpsertDestination_ReportsInserted(t *testing.T) {
+	for _, inserted := range []bool{true, false} {
+		t.Run(strconv.FormatBool(inserted), func(t *testing.T) {
+			var capturedSQL string
+			q := &mockQuerier{
+				queryRowFn: func(_ context.Context, sql string, _ ...any) pgx.Row {
+					capturedSQL = sql
+					return &fakeRow{scanFn: func(dest ...any) error {
+						*dest[0].(*bool) = inserted
+						return nil
+					}}
+				},
+			}
+
+			repo := storage.NewRepositoryWithQuerier(q)
+			got, err := repo.UpsertDestination(context.Background(), "Paris", "France", destination.DestinationData{}, time.Now())
+			require.NoError(t, err)
+			assert.Equal(t, inserted, got)
+			assert.Contains(t, capturedSQL, "RETURNING (xmax = 0) AS inserted")
+		})
+	}
+}
+
+func TestUpsertDestination_PreservesCreatedAt(t *testing.T) {
+	var capturedSQL string
+	q := &mockQuerier{
+		queryRowFn: func(_ context.Context, sql string, _ ...any) pgx.Row {
+			capturedSQL = sql
+			return &fakeRow{scanFn: func(_ ...any) error { return nil }}
+		},
+	}
+
+	repo := storage.NewRepositoryWithQuerier(q)
+	_, err := repo.UpsertDestination(context.Background(), "Paris", "France", destination.DestinationData{}, time.Now())
+	require.NoError(t, err)
+	assert.NotContains(t, capturedSQL, "created_at", "upsert must leave created_at to the insert default")
 }
 
 func TestUpsertDestination_DBError(t *testing.T) {
 	q := &mockQuerier{
-		execFn: func(_ context.Context, _ string, _ ...any) (pgconn.CommandTag, error) {
-			return pgconn.CommandTag{}, fmt.Errorf("db error")
+		queryRowFn: func(_ context.Context, _ string, _ ...any) pgx.Row {
+			return &fakeRow{scanFn: func(_ ...any) error { return fmt.Errorf("db error") }}
 		},
 	}
 
 	repo := storage.NewRepositoryWithQuerier(q)
-	err := repo.UpsertDestination(context.Background(), "Paris", "France", destination.DestinationData{})
+	_, err := repo.UpsertDestination(context.Background(), "Paris", "France", destination.DestinationData{}, time.Now())
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "upserting destination")
 }
 
+func TestUpsertDestination_History(t *testing.T) {
+	var statements []string
+	q := &mockQuerier{
+		queryRowFn: func(_ context.Context, sql string, _ ...any) pgx.Row {
+			statements = append(statements, sql)
+			return &fakeRow{scanFn: func(dest ...any) error {
+				*dest[0].(*bool) = len(statements) == 1
+				return nil
+			}}
+		},
+	}
+
+	repo := storage.NewRepositoryWithQuerier(q, storage.WithHistory(true))
+	for range 3 {
+		_, err := repo.UpsertDestination(context.Background(), "Paris", "France", destination.DestinationData{}, time.Now())
+		require.NoError(t, err)
+	}
+
+	// One statement per write, so the history row commits or rolls back with the upsert.
+	require.Len(t, statements, 3)
+	for _, sql := range statements {
+		assert.Contains(t, sql, "INSERT INTO destination_history")
+		assert.Contains(t, sql, "FROM upserted", "history must record the row as written")
+		assert.Contains(t, sql, "RETURNING (xmax = 0) AS inserted")
+	}
+}
+
+func TestUpsertDestination_NoHistoryByDefault(t *testing.T) {
+	var capturedSQL string
+	q := &mockQuerier{
+		queryRowFn: func(_ context.Context, sql string, _ ...any) pgx.Row {
+			capturedSQL = sql
+			return &fakeRow{scanFn: func(_ ...any) error { return nil }}
+		},
+	}
+
+	repo := storage.NewRepositoryWithQuerier(q)
+	_, err := repo.UpsertDestination(context.Background(), "Paris", "France", destination.DestinationData{}, time.Now())
+	require.NoError(t, err)
+	assert.NotContains(t, capturedSQL, "destination_history")
+}
+
+// ---- GetHistory tests ----
+
+func TestGetHistory_NewestFirst(t *testing.T) {
+	newer := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+	older := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	var capturedSQL string
+	var capturedArgs []any
+	q := &mockQuerier{
+		queryFn: func(_ context.Context, sql string, args ...any) (pgx.Rows, error) {
+			capturedSQL = sql
+			capturedArgs = args
+			return &fakeRows{rows: [][]any{
+				{"France", marshalData(t, destination.DestinationData{Weather: &destination.WeatherData{Temperature: 21}}), newer},
+				{"France", marshalData(t, destination.DestinationData{Weather: &destination.WeatherData{Temperature: 18}}), older},
+			}}, nil
+		},
+	}
+
+	repo := storage.NewRepositoryWithQuerier(q)
+	history, err := repo.GetHistory(context.Background(), "Paris", 10)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, newer, history[0].FetchedAt)
+	assert.InDelta(t, 21.0, history[0].Data.Weather.Temperature, 0.001)
+	assert.Equal(t, older, history[1].FetchedAt)
+	assert.Equal(t, "France", history[1].Country)
+
+	assert.Contains(t, capturedSQL, "ORDER BY fetched_at DESC, id DESC")
+	assert.Equal(t, []any{"Paris", 10}, capturedArgs)
+}
+
+func TestGetHistory_Empty(t *testing.T) {
+	q := &mockQuerier{
+		queryFn: func(_ context.Context, _ string, _ ...any) (pgx.Rows, error) { return &fakeRows{}, nil },
+	}
+
+	repo := storage.NewRepositoryWithQuerier(q)
+	history, err := repo.GetHistory(context.Background(), "Paris", 10)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func TestGetHistory_Errors(t *testing.T) {
+	repo := storage.NewRepositoryWithQuerier(&mockQuerier{
+		queryFn: func(_ context.Context, _ string, _ ...any) (pgx.Rows, error) { return nil, fmt.Errorf("query failed") },
+	})
+	_, err := repo.GetHistory(context.Background(), "Paris", 10)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "querying history")
+
+	repo = storage.NewRepositoryWithQuerier(&mockQuerier{
+		queryFn: func(_ context.Context, _ string, _ ...any) (pgx.Rows, error) {
+			return &fakeRows{rows: [][]any{{"France", []byte("{bad"), time.Now()}}}, nil
+		},
+	})
+	_, err = repo.GetHistory(context.Background(), "Paris", 10)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unmarshaling history")
+}
+
 // ---- GetDestinationByWeatherCondition tests ----
 
 func TestGetDestinationByWeatherCondition_Found(t *testing.T) {
@@ -359,6 +655,384 @@ func TestGetDestinationByWeatherCondition_BadJSON(t *testing.T) {
 	assert.Contains(t, err.Error(), "unmarshaling")
 }
 
+// ---- FullTextSearch tests ----
+
+func TestFullTextSearch_Found(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	rows := &fakeRows{
+		rows: [][]any{
+			{1, "New York", "United States", marshalData(t, destination.DestinationData{}), nil, now, now},
+			{2, "York", "United Kingdom", marshalData(t, destination.DestinationData{}), nil, now, now},
+		},
+	}
+
+	var capturedSQL string
+	var capturedArgs []any
+	q := &mockQuerier{
+		queryFn: func(_ context.Context, sql string, args ...any) (pgx.Rows, error) {
+			capturedSQL = sql
+			capturedArgs = args
+			return rows, nil
+		},
+	}
+
+	repo := storage.NewRepositoryWithQuerier(q)
+	results, err := repo.FullTextSearch(context.Background(), "new york", destination.Page{Limit: 20, Offset: 40})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "New York", results[0].City)
+	assert.Equal(t, "United Kingdom", results[1].Country)
+
+	assert.Equal(t, []any{"new york", 20, 40}, capturedArgs, "multi-word query is passed whole to plainto_tsquery, then the page")
+	assert.Contains(t, capturedSQL, "LIMIT $2 OFFSET $3")
+	assert.Contains(t, capturedSQL, "plainto_tsquery")
+	assert.Contains(t, capturedSQL, "ORDER BY ts_rank(search, query) DESC")
+}
+
+func TestFullTextSearch_Empty(t *testing.T) {
+	q := &mockQuerier{
+		queryFn: func(_ context.Context, _ string, _ ...any) (pgx.Rows, error) { return &fakeRows{}, nil },
+	}
+
+	repo := storage.NewRepositoryWithQuerier(q)
+	results, err := repo.FullTextSearch(context.Background(), "atlantis", destination.Page{Limit: 50})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestFullTextSearch_QueryError(t *testing.T) {
+	q := &mockQuerier{
+		queryFn: func(_ context.Context, _ string, _ ...any) (pgx.Rows, error) {
+			return nil, fmt.Errorf("query failed")
+		},
+	}
+
+	repo := storage.NewRepositoryWithQuerier(q)
+	_, err := repo.FullTextSearch(context.Background(), "paris", destination.Page{Limit: 50})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "full-text searching")
+}
+
+// ---- FindByMinQuality tests ----
+
+func TestFindByMinQuality(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	data := destination.DestinationData{QualityScores: []destination.QualityScore{{Name: "Safety", ScoreOutOf: 7.2}}}
+	rows := &fakeRows{
+		rows: [][]any{
+			{1, "Tokyo", "Japan", marshalData(t, data), nil, now, now},
+		},
+	}
+
+	var capturedSQL string
+	var capturedArgs []any
+	q := &mockQuerier{
+		queryFn: func(_ context.Context, sql string, args ...any) (pgx.Rows, error) {
+			capturedSQL = sql
+			capturedArgs = args
+			return rows, nil
+		},
+	}
+
+	repo := storage.NewRepositoryWithQuerier(q)
+	results, err := repo.FindByMinQuality(context.Background(), destination.QualityFilter{Category: "Safety", Min: 6}, destination.Page{Limit: 10, Offset: 20})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Tokyo", results[0].City)
+	assert.Equal(t, data.QualityScores, results[0].Data.QualityScores)
+
+	assert.Equal(t, []any{"Safety", 6.0, 10, 20}, capturedArgs)
+	assert.Contains(t, capturedSQL, "jsonb_array_elements(data->'quality_scores')")
+	assert.Contains(t, capturedSQL, "LOWER(score->>'name') = LOWER($1)")
+	assert.Contains(t, capturedSQL, "(score->>'score_out_of_10')::float8 >= $2")
+	assert.Contains(t, capturedSQL, "LIMIT $3 OFFSET $4")
+}
+
+func TestFindByMinQuality_QueryError(t *testing.T) {
+	q := &mockQuerier{
+		queryFn: func(_ context.Context, _ string, _ ...any) (pgx.Rows, error) {
+			return nil, fmt.Errorf("query failed")
+		},
+	}
+
+	repo := storage.NewRepositoryWithQuerier(q)
+	_, err := repo.FindByMinQuality(context.Background(), destination.QualityFilter{Category: "Safety", Min: 6}, destination.Page{Limit: 50})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Safety of at least 6")
+}
+
+// ---- DeleteMatching tests ----
+
+func TestDeleteMatching_Filters(t *testing.T) {
+	cutoff := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		filter     destination.BulkDeleteFilter
+		wantRegion string
+		wantBefore *time.Time
+	}{
+		{name: "region", filter: destination.BulkDeleteFilter{Region: "Europe"}, wantRegion: "Europe"},
+		{name: "older than", filter: destination.BulkDeleteFilter{FetchedBefore: cutoff}, wantBefore: &cutoff},
+		{
+			name:       "both",
+			filter:     destination.BulkDeleteFilter{Region: "Asia", FetchedBefore: cutoff},
+			wantRegion: "Asia",
+			wantBefore: &cutoff,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var capturedSQL string
+			var capturedArgs []any
+			q := &mockQuerier{
+				queryFn: func(_ context.Context, sql string, args ...any) (pgx.Rows, error) {
+					capturedSQL = sql
+					capturedArgs = args
+					return &fakeRows{rows: [][]any{{"Paris"}, {"Berlin"}}}, nil
+				},
+			}
+
+			repo := storage.NewRepositoryWithQuerier(q)
+			cities, err := repo.DeleteMatching(context.Background(), tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"Paris", "Berlin"}, cities)
+
+			assert.Contains(t, capturedSQL, "data->'country'->>'region'")
+			assert.Contains(t, capturedSQL, "RETURNING city")
+			require.Len(t, capturedArgs, 2)
+			assert.Equal(t, tt.wantRegion, capturedArgs[0])
+			assert.Equal(t, tt.wantBefore, capturedArgs[1])
+		})
+	}
+}
+
+func TestDeleteMatching_RequiresFilter(t *testing.T) {
+	q := &mockQuerier{
+		queryFn: func(_ context.Context, _ string, _ ...any) (pgx.Rows, error) {
+			t.Fatal("no query may run without a filter")
+			return nil, nil
+		},
+	}
+
+	repo := storage.NewRepositoryWithQuerier(q)
+	_, err := repo.DeleteMatching(context.Background(), destination.BulkDeleteFilter{})
+	require.ErrorIs(t, err, storage.ErrEmptyFilter)
+}
+
+func TestDeleteMatching_Errors(t *testing.T) {
+	filter := destination.BulkDeleteFilter{Region: "Europe"}
+
+	tests := []struct {
+		name    string
+		rows    pgx.Rows
+		err     error
+		wantErr string
+	}{
+		{name: "query", err: fmt.Errorf("boom"), wantErr: "bulk deleting"},
+		{name: "scan", rows: &fakeRows{rows: [][]any{{"Paris"}}, scanErr: fmt.Errorf("bad")}, wantErr: "scanning deleted city"},
+		{name: "rows", rows: &fakeRows{rowErr: fmt.Errorf("bad")}, wantErr: "iterating deleted cities"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &mockQuerier{
+				queryFn: func(_ context.Context, _ string, _ ...any) (pgx.Rows, error) { return tt.rows, tt.err },
+			}
+			repo := storage.NewRepositoryWithQuerier(q)
+			_, err := repo.DeleteMatching(context.Background(), filter)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+// ---- DistinctRegions / DistinctCountries tests ----
+
+func TestDistinct(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   func(*storage.Repository) ([]destination.NameCount, error)
+		wantSQL string
+	}{
+		{
+			name: "regions",
+			query: func(r *storage.Repository) ([]destination.NameCount, error) {
+				return r.DistinctRegions(context.Background())
+			},
+			wantSQL: "data->'country'->>'region'",
+		},
+		{
+			name: "countries",
+			query: func(r *storage.Repository) ([]destination.NameCount, error) {
+				return r.DistinctCountries(context.Background())
+			},
+			wantSQL: "GROUP BY country",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var capturedSQL string
+			q := &mockQuerier{
+				queryFn: func(_ context.Context, sql string, _ ...any) (pgx.Rows, error) {
+					capturedSQL = sql
+					return &fakeRows{rows: [][]any{{"Europe", 3}, {"Asia", 1}}}, nil
+				},
+			}
+
+			got, err := tt.query(storage.NewRepositoryWithQuerier(q))
+			require.NoError(t, err)
+			assert.Equal(t, []destination.NameCount{{Name: "Europe", Count: 3}, {Name: "Asia", Count: 1}}, got)
+			assert.Contains(t, capturedSQL, tt.wantSQL)
+			assert.Contains(t, capturedSQL, "COUNT(*)")
+		})
+	}
+}
+
+func TestDistinct_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    pgx.Rows
+		err     error
+		wantErr string
+	}{
+		{name: "query", err: fmt.Errorf("boom"), wantErr: "querying distinct regions"},
+		{name: "scan", rows: &fakeRows{rows: [][]any{{"Europe", 1}}, scanErr: fmt.Errorf("bad")}, wantErr: "scanning name count"},
+		{name: "rows", rows: &fakeRows{rowErr: fmt.Errorf("bad")}, wantErr: "iterating name count"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &mockQuerier{
+				queryFn: func(_ context.Context, _ string, _ ...any) (pgx.Rows, error) { return tt.rows, tt.err },
+			}
+			_, err := storage.NewRepositoryWithQuerier(q).DistinctRegions(context.Background())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+// ---- DeleteDestination tests ----
+
+func TestDeleteDestination(t *testing.T) {
+	tests := []struct {
+		name        string
+		tag         pgconn.CommandTag
+		wantDeleted bool
+	}{
+		{name: "deleted", tag: pgconn.NewCommandTag("DELETE 1"), wantDeleted: true},
+		{name: "missing", tag: pgconn.NewCommandTag("DELETE 0"), wantDeleted: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var capturedSQL string
+			var capturedArgs []any
+			q := &mockQuerier{
+				execFn: func(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+					capturedSQL = sql
+					capturedArgs = args
+					return tt.tag, nil
+				},
+			}
+
+			repo := storage.NewRepositoryWithQuerier(q)
+			deleted, err := repo.DeleteDestination(context.Background(), "Paris")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDeleted, deleted)
+			assert.Contains(t, capturedSQL, "DELETE FROM destinations")
+			assert.Equal(t, []any{"Paris"}, capturedArgs)
+		})
+	}
+}
+
+func TestDeleteDestination_Error(t *testing.T) {
+	q := &mockQuerier{
+		execFn: func(_ context.Context, _ string, _ ...any) (pgconn.CommandTag, error) {
+			return pgconn.CommandTag{}, fmt.Errorf("boom")
+		},
+	}
+
+	repo := storage.NewRepositoryWithQuerier(q)
+	_, err := repo.DeleteDestination(context.Background(), "Paris")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "deleting destination")
+}
+
+// ---- FindIncomplete tests ----
+
+func TestFindIncomplete_Found(t *testing.T) {
+	var capturedSQL string
+	var capturedArgs []any
+	rows := &fakeRows{
+		rows: [][]any{
+			{"Lyon", "France", []string{"points_of_interest", "quality_scores"}},
+			{"Oslo", "", []string{"weather"}},
+		},
+	}
+
+	q := &mockQuerier{
+		queryFn: func(_ context.Context, sql string, args ...any) (pgx.Rows, error) {
+			capturedSQL = sql
+			capturedArgs = args
+			return rows, nil
+		},
+	}
+
+	repo := storage.NewRepositoryWithQuerier(q)
+	results, err := repo.FindIncomplete(context.Background(), destination.Page{Limit: 10, Offset: 5})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "Lyon", results[0].City)
+	assert.Equal(t, []string{"points_of_interest", "quality_scores"}, results[0].Missing)
+	assert.Equal(t, []string{"weather"}, results[1].Missing)
+
+	assert.Contains(t, capturedSQL, "?&")
+	assert.Contains(t, capturedSQL, "LIMIT $2 OFFSET $3")
+	assert.Equal(t, []any{destination.ExpectedSections, 10, 5}, capturedArgs)
+}
+
+func TestFindIncomplete_Empty(t *testing.T) {
+	q := &mockQuerier{
+		queryFn: func(_ context.Context, _ string, _ ...any) (pgx.Rows, error) { return &fakeRows{}, nil },
+	}
+
+	repo := storage.NewRepositoryWithQuerier(q)
+	results, err := repo.FindIncomplete(context.Background(), destination.Page{Limit: 50})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestFindIncomplete_Errors(t *testing.T) {
+	repo := storage.NewRepositoryWithQuerier(&mockQuerier{
+		queryFn: func(_ context.Context, _ string, _ ...any) (pgx.Rows, error) { return nil, fmt.Errorf("query failed") },
+	})
+	_, err := repo.FindIncomplete(context.Background(), destination.Page{Limit: 50})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "querying incomplete")
+
+	repo = storage.NewRepositoryWithQuerier(&mockQuerier{
+		queryFn: func(_ context.Context, _ string, _ ...any) (pgx.Rows, error) {
+			return &fakeRows{rows: [][]any{{"Lyon", "France", []string{}}}, scanErr: fmt.Errorf("scan failed")}, nil
+		},
+	})
+	_, err = repo.FindIncomplete(context.Background(), destination.Page{Limit: 50})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "scanning")
+
+	repo = storage.NewRepositoryWithQuerier(&mockQuerier{
+		queryFn: func(_ context.Context, _ string, _ ...any) (pgx.Rows, error) {
+			return &fakeRows{rowErr: fmt.Errorf("iteration failed")}, nil
+		},
+	})
+	_, err = repo.FindIncomplete(context.Background(), destination.Page{Limit: 50})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "iterating")
+}
+
 // ---- NewRepository ----
 
 func TestNewRepository_NotNil(t *testing.T) {
@@ -378,23 +1052,119 @@ func TestRunMigrations_EmptyDir(t *testing.T) {
 	require.NoError(t, err)
 }
 
-func TestRunMigrations_Success(t *testing.T) {
-	dir := t.TempDir()
-	writeSQLFile(t, dir, "001_test.sql", "SELECT 1;")
+// migrationLog records every transaction RunMigrations opens, for asserting order.
+type migrationLog struct {
+	events  []string
+	applied map[string]bool
+	execErr error
+	commit  error
+}
 
-	tx := &mockTx{
-		execFn: func(_ context.Context, _ string, _ ...any) (pgconn.CommandTag, error) {
-			return pgconn.CommandTag{}, nil
+// pool returns a MigrationPool whose transactions log "begin", each Exec'd SQL,
+// "commit" and "rollback". Files already in applied report as recorded.
+func (l *migrationLog) pool() *mockMigrationPool {
+	return &mockMigrationPool{
+		beginFn: func(_ context.Context) (pgx.Tx, error) {
+			l.events = append(l.events, "begin")
+			return &mockTx{
+				execFn: func(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+					l.events = append(l.events, sql)
+					if strings.HasPrefix(sql, "INSERT INTO schema_migrations") {
+						if l.applied[args[0].(string)] {
+							return pgconn.NewCommandTag("INSERT 0 0"), nil
+						}
+						return pgconn.NewCommandTag("INSERT 0 1"), nil
+					}
+					if strings.HasPrefix(sql, "SELECT pg_advisory") || strings.HasPrefix(sql, "SET LOCAL") || strings.Contains(sql, "schema_migrations") {
+						return pgconn.CommandTag{}, nil
+					}
+					return pgconn.CommandTag{}, l.execErr
+				},
+				commitFn: func(_ context.Context) error {
+					l.events = append(l.events, "commit")
+					return l.commit
+				},
+				rollbackFn: func(_ context.Context) error {
+					l.events = append(l.events, "rollback")
+					return nil
+				},
+			}, nil
 		},
-		commitFn:   func(_ context.Context) error { return nil },
-		rollbackFn: func(_ context.Context) error { return nil },
 	}
-	pool := &mockMigrationPool{
-		beginFn: func(_ context.Context) (pgx.Tx, error) { return tx, nil },
+}
+
+// migrationSQL returns the logged statements that came from migration files.
+func (l *migrationLog) migrationSQL() []string {
+	var out []string
+	for _, e := range l.events {
+		switch {
+		case e == "begin", e == "commit", e == "rollback",
+			strings.HasPrefix(e, "SELECT pg_advisory"),
+			strings.HasPrefix(e, "SET LOCAL"),
+			strings.Contains(e, "schema_migrations"):
+			continue
+		}
+		out = append(out, e)
 	}
+	return out
+}
 
-	err := storage.RunMigrations(context.Background(), pool, dir)
+func TestRunMigrations_Success(t *testing.T) {
+	dir := t.TempDir()
+	writeSQLFile(t, dir, "001_test.sql", "SELECT 1;")
+
+	var log migrationLog
+	err := storage.RunMigrations(context.Background(), log.pool(), dir)
 	require.NoError(t, err)
+	assert.Equal(t, []string{"SELECT 1;"}, log.migrationSQL())
+}
+
+func TestRunMigrations_AdvisoryLockHeldAroundMigrations(t *testing.T) {
+	dir := t.TempDir()
+	writeSQLFile(t, dir, "001_test.sql", "SELECT 1;")
+
+	var log migrationLog
+	require.NoError(t, storage.RunMigrations(context.Background(), log.pool(), dir))
+
+	require.GreaterOrEqual(t, len(log.events), 4)
+	assert.Equal(t, "begin", log.events[0], "lock transaction opens first")
+	assert.Equal(t, "SET LOCAL statement_timeout = 0", log.events[1], "waiting for the lock is not cut short")
+	assert.Equal(t, "SELECT pg_advisory_xact_lock($1)", log.events[2], "lock is taken before anything else")
+	assert.Equal(t, "rollback", log.events[len(log.events)-1], "lock transaction ends, releasing the lock, last")
+
+	migrated := -1
+	for i, e := range log.events {
+		if e == "SELECT 1;" {
+			migrated = i
+		}
+	}
+	assert.Greater(t, migrated, 2, "migration runs while the lock is held")
+	assert.Equal(t, "SET LOCAL statement_timeout = 0", log.events[migrated-1], "migration SQL runs without a statement timeout")
+}
+
+func TestRunMigrations_SkipsAppliedFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeSQLFile(t, dir, "001_a.sql", "SELECT 1;")
+	writeSQLFile(t, dir, "002_b.sql", "SELECT 2;")
+
+	log := migrationLog{applied: map[string]bool{"001_a.sql": true}}
+	require.NoError(t, storage.RunMigrations(context.Background(), log.pool(), dir))
+	assert.Equal(t, []string{"SELECT 2;"}, log.migrationSQL())
+}
+
+func TestRunMigrations_TimestampTriggerInOneTransaction(t *testing.T) {
+	var log migrationLog
+	require.NoError(t, storage.RunMigrations(context.Background(), log.pool(), "../../migrations"))
+
+	var found bool
+	for _, sql := range log.migrationSQL() {
+		if strings.Contains(sql, "CREATE OR REPLACE FUNCTION destinations_touch_timestamps") {
+			found = true
+			assert.Contains(t, sql, "CREATE TRIGGER destinations_touch_timestamps")
+			assert.Contains(t, sql, "NEW.created_at := OLD.created_at")
+		}
+	}
+	assert.True(t, found, "timestamp trigger migration not executed")
 }
 
 func TestRunMigrations_BeginError(t *testing.T) {
@@ -407,16 +1177,19 @@ func TestRunMigrations_BeginError(t *testing.T) {
 
 	err := storage.RunMigrations(context.Background(), pool, dir)
 	require.Error(t, err)
-	assert.Contains(t, err.Error(), "executing migration")
+	assert.Contains(t, err.Error(), "migration lock transaction")
 }
 
-func TestRunMigrations_ExecError(t *testing.T) {
+func TestRunMigrations_LockError(t *testing.T) {
 	dir := t.TempDir()
-	writeSQLFile(t, dir, "001_test.sql", "INVALID SQL;")
+	writeSQLFile(t, dir, "001_test.sql", "SELECT 1;")
 
 	tx := &mockTx{
-		execFn: func(_ context.Context, _ string, _ ...any) (pgconn.CommandTag, error) {
-			return pgconn.CommandTag{}, fmt.Errorf("syntax error")
+		execFn: func(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+			if strings.HasPrefix(sql, "SET LOCAL") {
+				return pgconn.CommandTag{}, nil
+			}
+			return pgconn.CommandTag{}, fmt.Errorf("lock timeout")
 		},
 		commitFn:   func(_ context.Context) error { return nil },
 		rollbackFn: func(_ context.Context) error { return nil },
@@ -427,59 +1200,124 @@ func TestRunMigrations_ExecError(t *testing.T) {
 
 	err := storage.RunMigrations(context.Background(), pool, dir)
 	require.Error(t, err)
+	assert.Contains(t, err.Error(), "acquiring migration lock")
+}
+
+func TestRunMigrations_ExecError(t *testing.T) {
+	dir := t.TempDir()
+	writeSQLFile(t, dir, "001_test.sql", "INVALID SQL;")
+
+	log := migrationLog{execErr: fmt.Errorf("syntax error")}
+	err := storage.RunMigrations(context.Background(), log.pool(), dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "executing migration")
 }
 
 func TestRunMigrations_CommitError(t *testing.T) {
 	dir := t.TempDir()
 	writeSQLFile(t, dir, "001_test.sql", "SELECT 1;")
 
-	tx := &mockTx{
-		execFn: func(_ context.Context, _ string, _ ...any) (pgconn.CommandTag, error) {
-			return pgconn.CommandTag{}, nil
-		},
-		commitFn:   func(_ context.Context) error { return fmt.Errorf("commit failed") },
-		rollbackFn: func(_ context.Context) error { return nil },
-	}
-	pool := &mockMigrationPool{
-		beginFn: func(_ context.Context) (pgx.Tx, error) { return tx, nil },
-	}
-
-	err := storage.RunMigrations(context.Background(), pool, dir)
+	log := migrationLog{commit: fmt.Errorf("commit failed")}
+	err := storage.RunMigrations(context.Background(), log.pool(), dir)
 	require.Error(t, err)
 }
 
 func TestRunMigrations_SortsFilesLexicographically(t *testing.T) {
 	dir := t.TempDir()
-	var order []string
 	writeSQLFile(t, dir, "003_c.sql", "SELECT 3;")
 	writeSQLFile(t, dir, "001_a.sql", "SELECT 1;")
 	writeSQLFile(t, dir, "002_b.sql", "SELECT 2;")
 
-	tx := &mockTx{
-		execFn: func(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
-			order = append(order, sql)
-			return pgconn.CommandTag{}, nil
-		},
-		commitFn:   func(_ context.Context) error { return nil },
-		rollbackFn: func(_ context.Context) error { return nil },
-	}
-	pool := &mockMigrationPool{
-		beginFn: func(_ context.Context) (pgx.Tx, error) { return tx, nil },
-	}
-
-	err := storage.RunMigrations(context.Background(), pool, dir)
+	var log migrationLog
+	err := storage.RunMigrations(context.Background(), log.pool(), dir)
 	require.NoError(t, err)
-	require.Len(t, order, 3)
-	assert.Equal(t, "SELECT 1;", order[0])
-	assert.Equal(t, "SELECT 2;", order[1])
-	assert.Equal(t, "SELECT 3;", order[2])
+	assert.Equal(t, []string{"SELECT 1;", "SELECT 2;", "SELECT 3;"}, log.migrationSQL())
 }
 
 // ---- Connect tests ----
 
+func TestPoolConfig_StatementTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		opts    []storage.ConnectOption
+		want    string
+		wantSet bool
+	}{
+		{name: "unset", url: "postgres://user@localhost/db"},
+		{name: "zero keeps url", url: "postgres://user@localhost/db?statement_timeout=1000", opts: []storage.ConnectOption{storage.WithStatementTimeout(0)}, want: "1000", wantSet: true},
+		{name: "set", url: "postgres://user@localhost/db", opts: []storage.ConnectOption{storage.WithStatementTimeout(5 * time.Second)}, want: "5000", wantSet: true},
+		{name: "overrides url", url: "postgres://user@localhost/db?statement_timeout=1000", opts: []storage.ConnectOption{storage.WithStatementTimeout(1500 * time.Millisecond)}, want: "1500", wantSet: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := storage.PoolConfig(tt.url, tt.opts...)
+			require.NoError(t, err)
+			got, ok := cfg.ConnConfig.RuntimeParams["statement_timeout"]
+			assert.Equal(t, tt.wantSet, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPoolConfig_BadURL(t *testing.T) {
+	_, err := storage.PoolConfig("postgres://user@localhost:notaport/db")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "parsing database URL")
+}
+
 func TestConnect_BadURL(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 	_, err := storage.Connect(ctx, "postgres://invalid-host-xyz:5432/db?sslmode=disable")
 	require.Error(t, err)
 }
+
+// closingListener accepts TCP connections and closes them immediately, counting
+// each one, so a client's connection attempts against it fail and can be counted.
+func closingListener(t *testing.T) (string, *atomic.Int32) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	var accepts atomic.Int32
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			accepts.Add(1)
+			_ = conn.Close()
+		}
+	}()
+	return ln.Addr().String(), &accepts
+}
+
+func TestConnect_RetriesConfiguredTimes(t *testing.T) {
+	addr, accepts := closingListener(t)
+
+	start := time.Now()
+	_, err := storage.Connect(context.Background(), "postgres://user@"+addr+"/db?sslmode=disable",
+		storage.WithConnectRetries(3, 10*time.Millisecond))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "after 3 of 3 attempts")
+	// The pool may open an extra background connection after a failed ping, so
+	// only a lower bound on dials is exact.
+	assert.GreaterOrEqual(t, accepts.Load(), int32(3))
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond, "waits 10ms then 20ms between attempts")
+}
+
+func TestConnect_StopsRetryingWhenContextDone(t *testing.T) {
+	addr, accepts := closingListener(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := storage.Connect(ctx, "postgres://user@"+addr+"/db?sslmode=disable",
+		storage.WithConnectRetries(100, time.Hour))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "after 1 of 100 attempts")
+	assert.Positive(t, accepts.Load())
+}
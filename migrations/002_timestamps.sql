@@ -0,0 +1,19 @@
+-- created_at is set once on insert and never changes; updated_at tracks the last write.
+-- The trigger enforces both for every UPDATE, whichever code path issues it, so
+-- UpsertDestination setting updated_at explicitly is harmless but no longer required.
+-- The function and trigger live in one file so they are created in the same transaction.
+ALTER TABLE destinations ALTER COLUMN created_at SET DEFAULT NOW();
+ALTER TABLE destinations ALTER COLUMN updated_at SET DEFAULT NOW();
+
+CREATE OR REPLACE FUNCTION destinations_touch_timestamps() RETURNS trigger AS $$
+BEGIN
+    NEW.created_at := OLD.created_at;
+    NEW.updated_at := NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS destinations_touch_timestamps ON destinations;
+CREATE TRIGGER destinations_touch_timestamps
+    BEFORE UPDATE ON destinations
+    FOR EACH ROW EXECUTE FUNCTION destinations_touch_timestamps();
@@ -0,0 +1,8 @@
+-- Full-text search over city and country for Repository.FullTextSearch.
+-- The 'simple' configuration is used because place names should not be stemmed
+-- or have stop words removed ("Le Havre", "The Hague").
+ALTER TABLE destinations
+    ADD COLUMN IF NOT EXISTS search tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', city || ' ' || COALESCE(country, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS destinations_search_gin ON destinations USING GIN (search);
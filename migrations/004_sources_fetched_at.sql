@@ -0,0 +1,4 @@
+-- When each provider's section was last fetched, keyed by provider name, for
+-- refreshes that reuse fresh sections. It is bookkeeping, so it is kept out of
+-- data, which is served to clients as it is stored.
+ALTER TABLE destinations ADD COLUMN IF NOT EXISTS sources_fetched_at JSONB NOT NULL DEFAULT '{}'::jsonb;
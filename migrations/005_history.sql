@@ -0,0 +1,13 @@
+-- Past versions of each destination's data, one row per write, for
+-- Repository.GetHistory. Rows are only ever inserted; there is no foreign key to
+-- destinations so the history of a purged city survives it.
+CREATE TABLE IF NOT EXISTS destination_history (
+    id         BIGSERIAL PRIMARY KEY,
+    city       VARCHAR(255) NOT NULL,
+    country    VARCHAR(255),
+    data       JSONB NOT NULL,
+    fetched_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS destination_history_city_fetched_at
+    ON destination_history (city, fetched_at DESC);
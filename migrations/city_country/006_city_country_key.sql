@@ -0,0 +1,9 @@
+-- Keys destinations on (city, country) instead of city alone, so "Paris" in
+-- France and in the United States are separate rows. Applied only with
+-- DESTINATION_KEY=city_country. It is one-way: once same-named cities are
+-- stored, the city-only constraint cannot be restored.
+
+-- The new index also serves lookups by city alone, as city is its first column.
+CREATE UNIQUE INDEX IF NOT EXISTS destinations_city_country_unique ON destinations (city, country);
+
+ALTER TABLE destinations DROP CONSTRAINT IF EXISTS destinations_city_unique;